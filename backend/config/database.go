@@ -47,6 +47,7 @@ func ConnectDatabase() {
 
 func MigrateModels() {
 	var AllEntities = []interface{}{
+		&entity.Permission{},
 		&entity.Role{},
 		&entity.Faculty{},
 		&entity.Major{},
@@ -62,6 +63,10 @@ func MigrateModels() {
 		&entity.ActivityEvaluationScore{},	
 		&entity.Post{},
 		&entity.Registration{},
+		&entity.RegistrationFormField{},
+		&entity.RegistrationAnswer{},
+		&entity.TeamInvitation{},
+		&entity.RegistrationStatusHistory{},
 		&entity.Chatroom{},
 		&entity.MessagesType{},
 		&entity.Messages{},
@@ -75,12 +80,55 @@ func MigrateModels() {
 		&entity.PortfolioStatus{},
 		&entity.Certificate{},
 		&entity.PostStatus{},
+		&entity.PostReview{},
+		&entity.RefreshToken{},
+		&entity.AccountToken{},
+		&entity.LoginThrottle{},
+		&entity.AuditLog{},
+		&entity.UserIdentity{},
+		&entity.OIDCLoginState{},
+		&entity.RecoveryCode{},
+		&entity.APIToken{},
+	}
+
+	// ผู้ใช้ที่มีอยู่ก่อนเพิ่มการยืนยันอีเมล ให้ถือว่ายืนยันแล้ว (ทำครั้งเดียวตอนเพิ่มคอลัมน์)
+	backfillEmailVerified := DB.Migrator().HasTable(&entity.User{}) &&
+		!DB.Migrator().HasColumn(&entity.User{}, "EmailVerifiedAt")
+
+	// บทบาทในทีมอยู่ในตารางเชื่อม user_registrations ทีมที่มีอยู่ก่อนให้สมาชิก user_id น้อยสุดเป็นหัวหน้าทีม
+	backfillTeamLeaders := DB.Migrator().HasTable("user_registrations") &&
+		!DB.Migrator().HasColumn(&entity.UserRegistration{}, "Role")
+
+	// ทีมที่มีอยู่ก่อนมีประวัติสถานะ เริ่มประวัติด้วยสถานะปัจจุบัน (ถือว่าแจ้งแล้ว ไม่ส่งอีเมลย้อนหลัง)
+	backfillStatusHistory := DB.Migrator().HasTable(&entity.Registration{}) &&
+		!DB.Migrator().HasTable(&entity.RegistrationStatusHistory{})
+	if err := DB.SetupJoinTable(&entity.Registration{}, "Users", &entity.UserRegistration{}); err != nil {
+		log.Fatalf("Error setting up user_registrations: %v", err)
+	}
+	if err := DB.SetupJoinTable(&entity.User{}, "Registrations", &entity.UserRegistration{}); err != nil {
+		log.Fatalf("Error setting up user_registrations: %v", err)
 	}
 
 	err := DB.AutoMigrate(AllEntities...)
 	if err != nil {
 		log.Fatalf("Error migrating database: %v", err)
 	}
+
+	if backfillEmailVerified {
+		DB.Model(&entity.User{}).
+			Where("email_verified_at IS NULL").
+			Update("email_verified_at", gorm.Expr("created_at"))
+	}
+	if backfillTeamLeaders {
+		DB.Exec(`UPDATE user_registrations ur SET role = ?
+			WHERE ur.user_id = (SELECT MIN(x.user_id) FROM user_registrations x WHERE x.registration_id = ur.registration_id)`,
+			entity.TeamRoleLeader)
+	}
+	if backfillStatusHistory {
+		DB.Exec(`INSERT INTO registration_status_histories (created_at, registration_id, from_status, to_status, reason, notified_at)
+			SELECT updated_at, id, '', status, rejection_reason, NOW() FROM registrations WHERE deleted_at IS NULL`)
+	}
+	SetupSearchIndexes(DB)
 	SeedAllData()
 	fmt.Println("Database migrated successfully")
 }
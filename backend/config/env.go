@@ -5,6 +5,7 @@ import (
 	"github.com/joho/godotenv"
 	"log"
 	"os"
+	"strings"
 )
 
 type EnvConfig struct {
@@ -16,7 +17,26 @@ type EnvConfig struct {
 	DBHost           string
 	DBPort           string
 	JWTSecretKey     string
+	JWTKeysDir       string
+	JWTActiveKID     string
 	CORSAllowOrigins string
+	TrustedProxies   []string
+	FrontendURL      string
+	PublicAPIURL     string
+	MailDriver       string
+	MailFrom         string
+	MailLogDir       string
+	SMTPHost         string
+	SMTPPort         string
+	SMTPUser         string
+	SMTPPassword     string
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	OIDCSutIdClaim   string
+	OIDCEmailDomain  string
+	OIDCMock         bool
 }
 
 var Env EnvConfig
@@ -38,6 +58,37 @@ func LoadEnv() {
 	dbname := GetEnv("POSTGRES_DB")
 	sslmode := GetEnv("POSTGRES_SSLMODE")
 	jwtSecretKey := GetEnv("JWT_SECRET_KEY")
+	// key สำหรับเซ็น JWT: ไฟล์ <kid>.pem ใน JWT_KEYS_DIR, JWT_ACTIVE_KID คือ key ที่ใช้เซ็น
+	jwtKeysDir := GetEnv("JWT_KEYS_DIR")
+	jwtActiveKID := GetEnv("JWT_ACTIVE_KID")
+
+	// proxy ที่เชื่อถือ X-Forwarded-For ได้ (ใช้หา IP จริงตอนนับการเข้าสู่ระบบผิด) ค่าเริ่มต้นคือ network ภายใน
+	trustedProxies := []string{"127.0.0.1", "::1", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+	if value := GetEnv("TRUSTED_PROXIES"); value != "" {
+		trustedProxies = strings.Split(value, ",")
+	}
+
+	// ลิงก์ในอีเมล (ยืนยันอีเมล / รีเซ็ตรหัสผ่าน)
+	frontendURL := GetEnv("FRONTEND_URL")
+	publicAPIURL := GetEnv("PUBLIC_API_URL")
+
+	// MAIL_DRIVER=smtp ส่งอีเมลจริง, ค่าอื่น (ค่าเริ่มต้น log) เขียนอีเมลลงไฟล์ใน MAIL_LOG_DIR สำหรับ dev/test
+	mailDriver := GetEnv("MAIL_DRIVER")
+	mailFrom := GetEnv("MAIL_FROM")
+	mailLogDir := GetEnv("MAIL_LOG_DIR")
+	smtpHost := GetEnv("SMTP_HOST")
+	smtpPort := GetEnv("SMTP_PORT")
+	smtpUser := GetEnv("SMTP_USER")
+	smtpPassword := GetEnv("SMTP_PASSWORD")
+
+	// เข้าสู่ระบบด้วยบัญชีมหาวิทยาลัย (OpenID Connect) OIDC_MOCK=true ใช้ IdP จำลองใน backend สำหรับ dev
+	oidcIssuer := GetEnv("OIDC_ISSUER")
+	oidcClientID := GetEnv("OIDC_CLIENT_ID")
+	oidcClientSecret := GetEnv("OIDC_CLIENT_SECRET")
+	oidcRedirectURL := GetEnv("OIDC_REDIRECT_URL")
+	oidcSutIdClaim := GetEnv("OIDC_SUTID_CLAIM")
+	oidcEmailDomain := GetEnv("OIDC_EMAIL_DOMAIN")
+	oidcMock := strings.EqualFold(GetEnv("OIDC_MOCK"), "true")
 
 	dsn := fmt.Sprintf(
 		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
@@ -53,7 +104,26 @@ func LoadEnv() {
 		DBHost:           host,
 		DBPort:           port,
 		JWTSecretKey:     jwtSecretKey,
+		JWTKeysDir:       jwtKeysDir,
+		JWTActiveKID:     jwtActiveKID,
 		CORSAllowOrigins: corsAllowOrigins,
+		TrustedProxies:   trustedProxies,
+		FrontendURL:      frontendURL,
+		PublicAPIURL:     publicAPIURL,
+		MailDriver:       mailDriver,
+		MailFrom:         mailFrom,
+		MailLogDir:       mailLogDir,
+		SMTPHost:         smtpHost,
+		SMTPPort:         smtpPort,
+		SMTPUser:         smtpUser,
+		SMTPPassword:     smtpPassword,
+		OIDCIssuer:       oidcIssuer,
+		OIDCClientID:     oidcClientID,
+		OIDCClientSecret: oidcClientSecret,
+		OIDCRedirectURL:  oidcRedirectURL,
+		OIDCSutIdClaim:   oidcSutIdClaim,
+		OIDCEmailDomain:  oidcEmailDomain,
+		OIDCMock:         oidcMock,
 	}
 }
 
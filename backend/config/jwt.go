@@ -6,37 +6,59 @@ import (
 	jwt "github.com/golang-jwt/jwt/v5"
 )
 
-var jwtSecretKey = []byte(Env.JWTSecretKey)
+const (
+	// AccessTokenTTL อายุของ access token (JWT) ต้องสั้นเพราะตรวจสอบได้โดยไม่ต้องถาม DB
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL อายุของ refresh token ที่เก็บไว้ในตาราง refresh_tokens
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
 
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	SutId  string `json:"sut_id"`
-	Role   string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	SutId     string `json:"sut_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
+	// ImpersonatorID คือ admin ที่ออก token นี้เพื่อสวมสิทธิ์ผู้ใช้ (0 = token ปกติ)
+	ImpersonatorID uint `json:"imp,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userId uint, sutId string, role string) (string, error) {
+func GenerateJWT(userId uint, sutId string, role string, sessionID string) (string, error) {
+	claims := &Claims{
+		UserID:    userId,
+		SutId:     sutId,
+		Role:      role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	return getKeyRing().Sign(claims)
+}
+
+// GenerateImpersonationJWT ออก access token ของผู้ใช้ให้ admin ใช้ช่วยเหลือ (ไม่มี refresh token)
+func GenerateImpersonationJWT(userId uint, sutId string, role string, sessionID string, impersonatorID uint, ttl time.Duration) (string, error) {
 	claims := &Claims{
-		UserID: userId,
-		SutId:  sutId,
-		Role:   role,
+		UserID:         userId,
+		SutId:          sutId,
+		Role:           role,
+		SessionID:      sessionID,
+		ImpersonatorID: impersonatorID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecretKey)
+	return getKeyRing().Sign(claims)
 }
 
 func ValidateJWT(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return jwtSecretKey, nil
-	})
+	// key ถูกเลือกตาม kid ใน header ดู KeyRing.Keyfunc
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, getKeyRing().Keyfunc,
+		jwt.WithValidMethods([]string{"EdDSA", "RS256", "HS256"}))
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,279 @@
+package config
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+)
+
+// legacyKeyID ใช้กับ JWT_SECRET_KEY (HS256) ที่ใช้ก่อนมี key ring
+const legacyKeyID = "hs256"
+
+// SigningKey หนึ่ง key ใน key ring ถ้าไม่มี private key จะใช้ตรวจสอบได้อย่างเดียว (key ที่ปลดระวางแล้ว)
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+// KeyRing เก็บหลาย key แยกด้วย kid ใช้ key ที่ active เซ็น และใช้ทุก key ในการตรวจสอบ
+// จึงหมุนเวียน key ได้โดยที่ token เดิมยังใช้ได้จนหมดอายุ
+type KeyRing struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+var (
+	keyRingMu      sync.RWMutex
+	currentKeyRing *KeyRing
+)
+
+// LoadKeyRing โหลด key ring จาก Env (เรียกหลัง LoadEnv) ถ้าโหลดไม่ได้จะหยุดโปรแกรม
+func LoadKeyRing() {
+	kr, err := NewKeyRing(Env.JWTKeysDir, Env.JWTActiveKID, Env.JWTSecretKey)
+	if err != nil {
+		log.Fatalf("Error loading JWT keys: %v", err)
+	}
+	SetKeyRing(kr)
+}
+
+// ReloadKeyRing โหลด key ใหม่ขณะรันอยู่ (เช่นหลังเพิ่มไฟล์ key) ถ้าผิดพลาดจะใช้ key ring เดิมต่อ
+func ReloadKeyRing() error {
+	kr, err := NewKeyRing(Env.JWTKeysDir, Env.JWTActiveKID, Env.JWTSecretKey)
+	if err != nil {
+		return err
+	}
+	SetKeyRing(kr)
+	return nil
+}
+
+// SetKeyRing เปลี่ยน key ring ที่ใช้อยู่ (ใช้ตอนหมุนเวียน key และในเทสต์)
+func SetKeyRing(kr *KeyRing) {
+	keyRingMu.Lock()
+	defer keyRingMu.Unlock()
+	currentKeyRing = kr
+}
+
+func getKeyRing() *KeyRing {
+	keyRingMu.RLock()
+	kr := currentKeyRing
+	keyRingMu.RUnlock()
+	if kr != nil {
+		return kr
+	}
+
+	keyRingMu.Lock()
+	defer keyRingMu.Unlock()
+	if currentKeyRing == nil {
+		kr, err := NewKeyRing(Env.JWTKeysDir, Env.JWTActiveKID, Env.JWTSecretKey)
+		if err != nil {
+			log.Fatalf("Error loading JWT keys: %v", err)
+		}
+		currentKeyRing = kr
+	}
+	return currentKeyRing
+}
+
+// NewKeyRing อ่าน key จากไฟล์ <kid>.pem ใน dir (Ed25519 หรือ RSA, PKCS#8/PKCS#1 หรือ public key)
+// active คือ kid ที่ใช้เซ็น ถ้าไม่ระบุจะใช้ kid ที่เรียงแล้วอยู่ท้ายสุด
+// hmacSecret (JWT_SECRET_KEY) ใช้ตรวจ token HS256 แบบเดิม และใช้เซ็นถ้าไม่มี key ใน dir
+// ถ้าไม่มีอะไรเลยจะสร้าง Ed25519 key ชั่วคราว (token ใช้ไม่ได้หลังรีสตาร์ท เหมาะกับ dev/test)
+func NewKeyRing(dir, active, hmacSecret string) (*KeyRing, error) {
+	kr := &KeyRing{keys: map[string]*SigningKey{}}
+
+	if dir != "" {
+		files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			kid := strings.TrimSuffix(filepath.Base(file), ".pem")
+			key, err := loadKeyFile(kid, file)
+			if err != nil {
+				return nil, err
+			}
+			kr.keys[kid] = key
+			if active == "" && key.private != nil {
+				kr.active = key
+			}
+		}
+	}
+
+	if hmacSecret != "" {
+		kr.keys[legacyKeyID] = &SigningKey{
+			ID:      legacyKeyID,
+			Method:  jwt.SigningMethodHS256,
+			private: []byte(hmacSecret),
+			public:  []byte(hmacSecret),
+		}
+	}
+
+	if active != "" {
+		key, ok := kr.keys[active]
+		if !ok || key.private == nil {
+			return nil, fmt.Errorf("JWT_ACTIVE_KID %q not found or has no private key", active)
+		}
+		kr.active = key
+	}
+
+	if kr.active == nil {
+		if key, ok := kr.keys[legacyKeyID]; ok {
+			kr.active = key
+		} else {
+			log.Println("JWT keys not configured, using a temporary Ed25519 key")
+			key, err := NewEd25519SigningKey("ephemeral")
+			if err != nil {
+				return nil, err
+			}
+			kr.keys[key.ID] = key
+			kr.active = key
+		}
+	}
+	return kr, nil
+}
+
+// NewEd25519SigningKey สร้าง Ed25519 key ใหม่ในหน่วยความจำ
+func NewEd25519SigningKey(kid string) (*SigningKey, error) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, private: private, public: public}, nil
+}
+
+// NewSigningKeyFromPEM สร้าง SigningKey จาก PEM (private key หรือ public key)
+func NewSigningKeyFromPEM(kid string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %s: invalid PEM", kid)
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %s: unsupported PEM type %s", kid, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", kid, err)
+	}
+
+	switch k := parsed.(type) {
+	case ed25519.PrivateKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, private: k, public: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA, public: k}, nil
+	case *rsa.PrivateKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, private: k, public: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, public: k}, nil
+	}
+	return nil, fmt.Errorf("key %s: only Ed25519 and RSA keys are supported", kid)
+}
+
+func loadKeyFile(kid, file string) (*SigningKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewSigningKeyFromPEM(kid, data)
+}
+
+// NewKeyRingFromKeys สร้าง key ring จาก key ที่มีอยู่แล้ว (key แรกคือ key ที่ใช้เซ็น)
+func NewKeyRingFromKeys(active *SigningKey, others ...*SigningKey) *KeyRing {
+	kr := &KeyRing{active: active, keys: map[string]*SigningKey{active.ID: active}}
+	for _, key := range others {
+		kr.keys[key.ID] = key
+	}
+	return kr
+}
+
+// ActiveKeyID kid ที่ใช้เซ็น token ใหม่
+func (kr *KeyRing) ActiveKeyID() string {
+	return kr.active.ID
+}
+
+// Sign เซ็น claims ด้วย key ที่ active และใส่ kid ใน header
+func (kr *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(kr.active.Method, claims)
+	token.Header["kid"] = kr.active.ID
+	return token.SignedString(kr.active.private)
+}
+
+// Keyfunc เลือก key ตาม kid และตรวจว่า alg ตรงกับชนิดของ key
+// token ที่ไม่มี kid ถือเป็น token HS256 แบบเดิม
+func (kr *KeyRing) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = legacyKeyID
+	}
+	key, ok := kr.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return key.public, nil
+}
+
+// JWKS public key ทั้งหมดในรูปแบบ JSON Web Key Set (ไม่รวม HMAC key)
+func (kr *KeyRing) JWKS() map[string]interface{} {
+	kids := make([]string, 0, len(kr.keys))
+	for kid := range kr.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := []map[string]string{}
+	for _, kid := range kids {
+		key := kr.keys[kid]
+		switch pub := key.public.(type) {
+		case ed25519.PublicKey:
+			keys = append(keys, map[string]string{
+				"kty": "OKP",
+				"crv": "Ed25519",
+				"x":   base64.RawURLEncoding.EncodeToString(pub),
+				"kid": kid,
+				"alg": key.Method.Alg(),
+				"use": "sig",
+			})
+		case *rsa.PublicKey:
+			keys = append(keys, map[string]string{
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+				"kid": kid,
+				"alg": key.Method.Alg(),
+				"use": "sig",
+			})
+		}
+	}
+	return map[string]interface{}{"keys": keys}
+}
+
+// JWKS ของ key ring ที่ใช้อยู่
+func JWKS() map[string]interface{} {
+	return getKeyRing().JWKS()
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// searchVectors คอลัมน์ tsvector ของแต่ละตารางที่ค้นหาได้ เป็น generated column จึงอัปเดตเองทุกครั้งที่แถวเปลี่ยน
+// ใช้ dictionary 'simple' เพราะ PostgreSQL ไม่มีตัวตัดคำภาษาไทย และไม่ต้องการให้คำภาษาอังกฤษถูกตัดรากศัพท์ผิด
+var searchVectors = map[string]string{
+	"posts": `setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(organizer, '') || ' ' || coalesce(type, '')), 'B') ||
+		setweight(to_tsvector('simple', coalesce(detail, '')), 'C')`,
+	"users": `setweight(to_tsvector('simple', coalesce(sut_id, '') || ' ' || coalesce(first_name, '') || ' ' || coalesce(last_name, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(bio, '')), 'C')`,
+	"portfolios": `setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce(port_type, '')), 'B') ||
+		setweight(to_tsvector('simple', coalesce(description, '')), 'C')`,
+}
+
+// searchTrigramColumns คอลัมน์สั้นที่ใช้ค้นแบบ substring (ILIKE) สำหรับข้อความภาษาไทยที่ไม่มีช่องว่างระหว่างคำ
+var searchTrigramColumns = map[string][]string{
+	"posts":      {"title", "organizer"},
+	"users":      {"sut_id", "first_name", "last_name"},
+	"portfolios": {"title"},
+}
+
+// SetupSearchIndexes สร้างคอลัมน์และ index สำหรับ GET /search (ทำซ้ำได้ ไม่สร้างซ้ำถ้ามีอยู่แล้ว)
+// ถ้าติดตั้ง pg_trgm ไม่ได้ การค้นแบบ ILIKE ยังทำงานได้ เพียงแต่ไม่มี index ช่วย
+func SetupSearchIndexes(db *gorm.DB) {
+	for table, vector := range searchVectors {
+		stmts := []string{
+			"ALTER TABLE " + table + " ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (" + vector + ") STORED",
+			"CREATE INDEX IF NOT EXISTS idx_" + table + "_search_vector ON " + table + " USING GIN (search_vector)",
+		}
+		for _, stmt := range stmts {
+			if err := db.Exec(stmt).Error; err != nil {
+				log.Printf("Error setting up full-text search on %s: %v", table, err)
+			}
+		}
+	}
+
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		log.Printf("pg_trgm is not available, substring search runs without trigram indexes: %v", err)
+		return
+	}
+	for table, columns := range searchTrigramColumns {
+		for _, column := range columns {
+			stmt := "CREATE INDEX IF NOT EXISTS idx_" + table + "_" + column + "_trgm ON " + table + " USING GIN (" + column + " gin_trgm_ops)"
+			if err := db.Exec(stmt).Error; err != nil {
+				log.Printf("Error creating trigram index on %s.%s: %v", table, column, err)
+			}
+		}
+	}
+}
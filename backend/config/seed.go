@@ -2,12 +2,14 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/sut68/team21/entity"
 )
 
 func SeedAllData() {
 	SeedRoles()
+	SeedPermissions()
 	SeedFaculties()
 	SeedMajors()
 	SeedUsers()
@@ -38,6 +40,33 @@ func SeedRoles() {
 	}
 }
 
+// SeedPermissions สร้างสิทธิ์ที่ยังไม่มี และกำหนดสิทธิ์เริ่มต้นให้ role ที่ยังไม่เคยตั้งสิทธิ์
+// (รวมถึงสร้าง role organizer ในระบบที่ seed role ไปแล้วก่อนมีตารางสิทธิ์)
+func SeedPermissions() {
+	permissions := map[string]entity.Permission{}
+	for name, description := range entity.PermissionDescriptions {
+		permission := entity.Permission{Name: name}
+		DB.Where(entity.Permission{Name: name}).Attrs(entity.Permission{Description: description}).FirstOrCreate(&permission)
+		permissions[name] = permission
+	}
+
+	for roleName, names := range entity.DefaultRolePermissions {
+		role := entity.Role{Name: roleName}
+		DB.FirstOrCreate(&role, entity.Role{Name: roleName})
+
+		if DB.Model(&role).Association("Permissions").Count() > 0 {
+			continue
+		}
+		defaults := make([]entity.Permission, 0, len(names))
+		for _, name := range names {
+			defaults = append(defaults, permissions[name])
+		}
+		if err := DB.Model(&role).Association("Permissions").Append(defaults); err != nil {
+			log.Printf("Error seeding permissions for role %s: %v", roleName, err)
+		}
+	}
+}
+
 func SeedUsers() {
 
 	var count int64
@@ -165,7 +194,9 @@ func SeedUsers() {
 		},
 	}
 
+	verifiedAt := time.Now()
 	for _, user := range users {
+		user.EmailVerifiedAt = &verifiedAt
 		DB.FirstOrCreate(&user, entity.User{SutId: user.SutId})
 	}
 }
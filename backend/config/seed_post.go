@@ -6,6 +6,7 @@ import (
 	"os"
 	"encoding/base64"
 	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
 )
 
 func SeedLocations() {
@@ -191,7 +192,7 @@ func SeedPosts() {
 			Start:      parsePostTime("2024-12-28 10:00:00"),
 			Stop:       parsePostTime("2024-12-29 16:00:00"),
 			UserID:     postUintPtr(1),
-			StatusID:   postUintPtr(6),
+			StatusID:   postUintPtr(entity.PostStatusEnded),
 			LocationID: postUintPtr(1),
 			PostPoint:  0,
 		},
@@ -206,7 +207,7 @@ func SeedPosts() {
 			Start:      parsePostTime("2024-12-10 10:00:00"),
 			Stop:       parsePostTime("2024-12-12 16:00:00"),
 			UserID:     postUintPtr(1),
-			StatusID:   postUintPtr(6),
+			StatusID:   postUintPtr(entity.PostStatusEnded),
 			LocationID: postUintPtr(1),
 			PostPoint:  0,
 		},
@@ -224,16 +225,9 @@ func SeedPostStatuses() {
 	if count > 0 {
 		return
 	}
-	statuses := []entity.PostStatus{
-		{StatusName: "Pending"},
-		{StatusName: "Approved"},
-		{StatusName: "Rejected"},
-		{StatusName: "Upcoming"},
-		{StatusName: "Active"},
-		{StatusName: "Ended"},
-	}
-	for _, status := range statuses {
-
+	// กำหนด ID ให้ตรงกับค่าคงที่ entity.PostStatus* ที่ state machine ใช้
+	for id := entity.PostStatusPending; id <= entity.PostStatusEnded; id++ {
+		status := entity.PostStatus{Model: gorm.Model{ID: id}, StatusName: entity.PostStatusNames[id]}
 		DB.FirstOrCreate(&status, entity.PostStatus{StatusName: status.StatusName})
 	}
 
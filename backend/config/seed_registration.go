@@ -42,6 +42,7 @@ func SeedRegistrations() {
 	var users2 []entity.User
 	DB.Where("id IN ?", []uint{2,3,6}).Find(&users2)// ดึง User ID 2,3,6
 	DB.Model(&registrations[1]).Association("Users").Append(&users2) // กิจกรรมที่ 2 ผู้ที่เข้าร่วมกิจกรรม
+	DB.Model(&entity.UserRegistration{}).Where("user_id = ?", 2).Update("role", entity.TeamRoleLeader) // User ID 2 เป็นหัวหน้าทั้งสองทีม
 
 	log.Println("Seed Evaluation Registrations completed")
 }
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateSecureToken สร้าง token แบบสุ่ม (base64url) สำหรับส่งให้ client เช่น refresh token
+func GenerateSecureToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashToken เก็บเฉพาะ hash ของ token ลง DB เพื่อไม่ให้ token ใช้งานได้หากฐานข้อมูลรั่วไหล
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+type APITokenController struct {
+	apiTokenService *services.APITokenService
+}
+
+func NewAPITokenController(apiTokenService *services.APITokenService) *APITokenController {
+	return &APITokenController{apiTokenService: apiTokenService}
+}
+
+// GET /api-tokens
+func (c *APITokenController) List(ctx *gin.Context) {
+	tokens, err := c.apiTokenService.List(middleware.CurrentUserID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูล API token ได้"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": tokens})
+}
+
+// POST /api-tokens
+func (c *APITokenController) Create(ctx *gin.Context) {
+	if _, impersonated := ctx.Get("impersonator_id"); impersonated {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": services.ErrImpersonatedCredential.Error()})
+		return
+	}
+	var req dto.CreateAPITokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุชื่อและ scope ของ token"})
+		return
+	}
+
+	token, err := c.apiTokenService.Create(middleware.CurrentUserID(ctx), ctx.GetString("role"), req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "สร้าง API token สำเร็จ กรุณาคัดลอกเก็บไว้ token จะแสดงเพียงครั้งนี้ครั้งเดียว",
+		"data":    token,
+	})
+}
+
+// DELETE /api-tokens/:id
+func (c *APITokenController) Revoke(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	if err := c.apiTokenService.Revoke(middleware.CurrentUserID(ctx), uint(id)); err != nil {
+		if errors.Is(err, services.ErrAPITokenNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถเพิกถอน API token ได้"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "เพิกถอน API token สำเร็จ"})
+}
@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/services"
+)
+
+type AuditController struct {
+	auditService *services.AuditService
+}
+
+func NewAuditController(auditService *services.AuditService) *AuditController {
+	return &AuditController{auditService: auditService}
+}
+
+func bindAuditQuery(ctx *gin.Context) (dto.AuditLogQuery, bool) {
+	var query dto.AuditLogQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "เงื่อนไขค้นหาไม่ถูกต้อง (วันที่ต้องอยู่ในรูปแบบ YYYY-MM-DD)"})
+		return query, false
+	}
+	return query, true
+}
+
+// GET /audit-logs
+func (c *AuditController) List(ctx *gin.Context) {
+	query, ok := bindAuditQuery(ctx)
+	if !ok {
+		return
+	}
+	page, err := c.auditService.List(query)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูล audit log ได้"})
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GET /audit-logs/export
+func (c *AuditController) Export(ctx *gin.Context) {
+	query, ok := bindAuditQuery(ctx)
+	if !ok {
+		return
+	}
+	// BOM ให้ Excel อ่านภาษาไทยได้ถูกต้อง
+	buf := bytes.NewBufferString("\ufeff")
+	if err := c.auditService.ExportCSV(query, buf); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถส่งออก audit log ได้"})
+		return
+	}
+	filename := "audit-log-" + time.Now().Format("20060102-150405") + ".csv"
+	ctx.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
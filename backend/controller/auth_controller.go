@@ -1,19 +1,26 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/config"
 	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
 
 type AuthController struct {
-	authService *services.AuthService
+	authService    *services.AuthService
+	sessionService *services.SessionService
+	accountService *services.AccountService
+	loginGuard     *services.LoginGuard
 }
 
-func NewAuthController(authService *services.AuthService) *AuthController {
-	return &AuthController{authService: authService}
+func NewAuthController(authService *services.AuthService, sessionService *services.SessionService, accountService *services.AccountService, loginGuard *services.LoginGuard) *AuthController {
+	return &AuthController{authService: authService, sessionService: sessionService, accountService: accountService, loginGuard: loginGuard}
 }
 
 func (c *AuthController) Register(ctx *gin.Context) {
@@ -34,7 +41,7 @@ func (c *AuthController) Register(ctx *gin.Context) {
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "ลงทะเบียนสำเร็จ",
+		"message": "ลงทะเบียนสำเร็จ กรุณายืนยันอีเมลจากลิงก์ที่ส่งไปก่อนเข้าสู่ระบบ",
 	})
 }
 func (c *AuthController) Login(ctx *gin.Context) {
@@ -47,8 +54,41 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	res, err := c.authService.Login(req)
+	res, err := c.authService.Login(req, ctx.Request.UserAgent(), ctx.ClientIP())
 
+	var throttled *services.LoginThrottledError
+	if errors.As(err, &throttled) {
+		retryAfter := int(throttled.Wait.Seconds()) + 1
+		ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+		ctx.JSON(http.StatusTooManyRequests, gin.H{
+			"error":       err.Error(),
+			"locked":      throttled.Locked,
+			"retry_after": retryAfter,
+		})
+		return
+	}
+
+	if errors.Is(err, services.ErrEmailNotVerified) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+			"code":  "email_not_verified",
+		})
+		return
+	}
+	if errors.Is(err, services.ErrAccountDeactivated) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+			"code":  "account_deactivated",
+		})
+		return
+	}
+	if errors.Is(err, services.ErrPasswordResetRequired) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": err.Error(),
+			"code":  "password_reset_required",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{
 			"error": err.Error(),
@@ -56,15 +96,182 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
+	if res.ChallengeToken != "" {
+		ctx.JSON(http.StatusOK, gin.H{
+			"message":            "กรุณายืนยันตัวตนขั้นที่สอง",
+			"challenge_token":    res.ChallengeToken,
+			"mfa_required":       res.MFARequired,
+			"mfa_setup_required": res.MFASetupRequired,
+			"expires_in":         res.ExpiresIn,
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loginSuccessBody(res))
+}
+
+// loginSuccessBody รูปแบบ response เมื่อเข้าสู่ระบบสำเร็จ (ใช้ร่วมกับขั้นยืนยัน 2FA)
+func loginSuccessBody(res dto.LoginResponse) gin.H {
+	return gin.H{
+		"message":       "เข้าสู่ระบบสำเร็จ",
+		"token_type":    "Bearer",
+		"token":         res.Token,
+		"refresh_token": res.RefreshToken,
+		"expires_in":    res.ExpiresIn,
+		"role":          res.Role,
+		"id":            res.ID,
+		"sut_id":        res.SutId,
+		"email":         res.Email,
+		"first_name":    res.FirstName,
+		"last_name":     res.LastName,
+	}
+}
+
+// POST /auth/refresh
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุ refresh_token"})
+		return
+	}
+
+	tokens, err := c.sessionService.Refresh(req.RefreshToken, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
-		"message":    "เข้าสู่ระบบสำเร็จ",
-		"token_type": "Bearer",
-		"token":      res.Token,
-		"role":       res.Role,
-		"id":         res.ID,
-		"sut_id":     res.SutId,
-		"email":      res.Email,
-		"first_name": res.FirstName,
-		"last_name":  res.LastName,
+		"token_type":    "Bearer",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
 	})
 }
+
+// POST /auth/logout
+func (c *AuthController) Logout(ctx *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุ refresh_token"})
+		return
+	}
+
+	if err := c.sessionService.Logout(req.RefreshToken); err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "ออกจากระบบสำเร็จ"})
+}
+
+// POST /auth/logout-all
+func (c *AuthController) LogoutAll(ctx *gin.Context) {
+	userID := middleware.CurrentUserID(ctx)
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "กรุณาเข้าสู่ระบบ"})
+		return
+	}
+
+	if err := c.sessionService.RevokeAllSessions(userID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถออกจากระบบทุกอุปกรณ์ได้"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "ออกจากระบบทุกอุปกรณ์สำเร็จ"})
+}
+
+// GET /auth/verify-email?token= (ลิงก์จากอีเมล) และ POST /auth/verify-email
+func (c *AuthController) VerifyEmail(ctx *gin.Context) {
+	if ctx.Request.Method == http.MethodGet {
+		if err := c.accountService.VerifyEmail(ctx.Query("token")); err != nil {
+			ctx.Redirect(http.StatusFound, services.FrontendURL("/login?verified=0"))
+			return
+		}
+		ctx.Redirect(http.StatusFound, services.FrontendURL("/login?verified=1"))
+		return
+	}
+
+	var req dto.VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุ token"})
+		return
+	}
+	if err := c.accountService.VerifyEmail(req.Token); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ยืนยันอีเมลสำเร็จ"})
+}
+
+// POST /auth/resend-verification
+func (c *AuthController) ResendVerification(ctx *gin.Context) {
+	var req dto.EmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุอีเมลให้ถูกต้อง"})
+		return
+	}
+	if err := c.accountService.ResendVerification(req.Email); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "หากอีเมลนี้ยังไม่ได้ยืนยัน ระบบได้ส่งลิงก์ยืนยันใหม่ให้แล้ว"})
+}
+
+// POST /auth/forgot-password
+func (c *AuthController) ForgotPassword(ctx *gin.Context) {
+	var req dto.EmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุอีเมลให้ถูกต้อง"})
+		return
+	}
+	if err := c.accountService.ForgotPassword(req.Email); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "หากอีเมลนี้มีอยู่ในระบบ ระบบได้ส่งลิงก์รีเซ็ตรหัสผ่านให้แล้ว"})
+}
+
+// POST /auth/reset-password
+func (c *AuthController) ResetPassword(ctx *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง รหัสผ่านต้องมีอย่างน้อย 8 ตัวอักษร"})
+		return
+	}
+	if err := c.accountService.ResetPassword(req.Token, req.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถรีเซ็ตรหัสผ่านได้"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ตั้งรหัสผ่านใหม่สำเร็จ กรุณาเข้าสู่ระบบอีกครั้ง"})
+}
+
+// GET /auth/lockouts (admin)
+func (c *AuthController) ListLockouts(ctx *gin.Context) {
+	lockouts, err := c.loginGuard.ListLocked()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูลบัญชีที่ถูกล็อกได้"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": lockouts})
+}
+
+// POST /auth/unlock/:sutId (admin)
+func (c *AuthController) UnlockAccount(ctx *gin.Context) {
+	actorID := middleware.CurrentUserID(ctx)
+	if err := c.loginGuard.Unlock(ctx.Param("sutId"), actorID, ctx.ClientIP()); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ปลดล็อกบัญชีสำเร็จ"})
+}
+
+// GET /.well-known/jwks.json public key สำหรับบริการอื่นใช้ตรวจ token ของเรา
+func (c *AuthController) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, config.JWKS())
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
 
@@ -29,13 +30,16 @@ func (c *CertificateController) CreateCertificate(ctx *gin.Context) {
 	}
 
 	// ดึง user_id จาก JWT
-	userID, exists := ctx.Get("user_id")
-	if !exists {
+	userID := middleware.CurrentUserID(ctx)
+	if userID == 0 {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
-	uid := userID.(uint)
-	certificate.UserID = &uid
+	certificate.UserID = &userID
+
+	if !canManageActivity(ctx, func() bool { return c.certificateService.IsPostOwner(certificate.PostID, userID) }) {
+		return
+	}
 
 	result, err := c.certificateService.CreateCertificate(&certificate)
 	if err != nil {
@@ -105,13 +109,13 @@ func (c *CertificateController) GetCertificateByPostID(ctx *gin.Context) {
 
 
 func (c *CertificateController) GetMyCertificates(ctx *gin.Context) {
-	userID, exists := ctx.Get("user_id")
-	if !exists {
+	userID := middleware.CurrentUserID(ctx)
+	if userID == 0 {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	certificates, err := c.certificateService.GetMyCertificates(userID.(uint))
+	certificates, err := c.certificateService.GetMyCertificates(userID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -131,6 +135,11 @@ func (c *CertificateController) UpdateCertificate(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
 		return
 	}
+	if !canManageActivity(ctx, func() bool {
+		return c.certificateService.IsCertificateOwner(uint(id), middleware.CurrentUserID(ctx))
+	}) {
+		return
+	}
 
 	var certificate entity.Certificate
 	if err := ctx.ShouldBindJSON(&certificate); err != nil {
@@ -156,6 +165,11 @@ func (c *CertificateController) DeleteCertificate(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
 		return
 	}
+	if !canManageActivity(ctx, func() bool {
+		return c.certificateService.IsCertificateOwner(uint(id), middleware.CurrentUserID(ctx))
+	}) {
+		return
+	}
 
 	err = c.certificateService.DeleteCertificate(uint(id))
 	if err != nil {
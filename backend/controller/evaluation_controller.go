@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
 
@@ -96,8 +97,8 @@ func (c *EvaluationController) UpdateTopic(ctx *gin.Context) {
 }
 
 func (c *EvaluationController) SubmitEvaluation(ctx *gin.Context) {
-	userID, exists := ctx.Get("user_id")
-	if !exists {
+	userID := middleware.CurrentUserID(ctx)
+	if userID == 0 {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ไม่ได้เข้าสู่ระบบ"})
 		return
 	}
@@ -108,7 +109,7 @@ func (c *EvaluationController) SubmitEvaluation(ctx *gin.Context) {
 		return
 	}
 
-	respone, err := c.service.SubmitEvaluation(req, userID.(uint))
+	respone, err := c.service.SubmitEvaluation(req, userID)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -121,13 +122,13 @@ func (c *EvaluationController) SubmitEvaluation(ctx *gin.Context) {
 }
 
 func (c *EvaluationController) GetMyRegisteredPosts(ctx *gin.Context) {
-	userID, exists := ctx.Get("user_id")
-	if !exists {
+	userID := middleware.CurrentUserID(ctx)
+	if userID == 0 {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "ไม่ได้เข้าสู่ระบบ"})
 		return
 	}
 
-	posts, err := c.service.GetMyRegisteredPosts(userID.(uint))
+	posts, err := c.service.GetMyRegisteredPosts(userID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+type MFAController struct {
+	mfaService *services.MFAService
+}
+
+func NewMFAController(mfaService *services.MFAService) *MFAController {
+	return &MFAController{mfaService: mfaService}
+}
+
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrInvalidMFAChallenge):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrInvalidMFACode),
+		errors.Is(err, services.ErrMFAAlreadyEnabled),
+		errors.Is(err, services.ErrMFANotEnabled),
+		errors.Is(err, services.ErrMFASetupNotStarted):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrMFARequiredForRole):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// POST /auth/2fa/verify ขั้นที่สองของการเข้าสู่ระบบ (รหัส TOTP หรือรหัสสำรอง)
+func (c *MFAController) Verify(ctx *gin.Context) {
+	var req dto.MFAVerifyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุ challenge_token และรหัสยืนยัน"})
+		return
+	}
+
+	res, err := c.mfaService.VerifyChallenge(req.ChallengeToken, req.Code, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, loginSuccessBody(res))
+}
+
+// POST /auth/2fa/challenge/setup ผู้ที่ถูกบังคับใช้ 2FA แต่ยังไม่ได้ตั้งค่า เริ่มตั้งค่าระหว่างเข้าสู่ระบบ
+func (c *MFAController) ChallengeSetup(ctx *gin.Context) {
+	var req dto.MFAChallengeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุ challenge_token"})
+		return
+	}
+
+	res, err := c.mfaService.SetupForChallenge(req.ChallengeToken)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// POST /auth/2fa/challenge/enable ยืนยันรหัสแรกจากแอป เปิดใช้ 2FA แล้วเข้าสู่ระบบ
+func (c *MFAController) ChallengeEnable(ctx *gin.Context) {
+	var req dto.MFAVerifyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุ challenge_token และรหัสยืนยัน"})
+		return
+	}
+
+	res, codes, err := c.mfaService.EnableForChallenge(req.ChallengeToken, req.Code, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	body := loginSuccessBody(res)
+	body["recovery_codes"] = codes
+	ctx.JSON(http.StatusOK, body)
+}
+
+// GET /auth/2fa/status
+func (c *MFAController) Status(ctx *gin.Context) {
+	res, err := c.mfaService.Status(middleware.CurrentUserID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// POST /auth/2fa/setup
+func (c *MFAController) Setup(ctx *gin.Context) {
+	if _, impersonated := ctx.Get("impersonator_id"); impersonated {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": services.ErrImpersonatedCredential.Error()})
+		return
+	}
+	res, err := c.mfaService.Setup(middleware.CurrentUserID(ctx))
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": res})
+}
+
+// POST /auth/2fa/enable รหัสสำรองแสดงครั้งเดียวใน response นี้
+func (c *MFAController) Enable(ctx *gin.Context) {
+	if _, impersonated := ctx.Get("impersonator_id"); impersonated {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": services.ErrImpersonatedCredential.Error()})
+		return
+	}
+	var req dto.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุรหัสยืนยัน"})
+		return
+	}
+
+	codes, err := c.mfaService.Enable(middleware.CurrentUserID(ctx), req.Code)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":        "เปิดใช้การยืนยันตัวตนสองขั้นตอนสำเร็จ กรุณาเก็บรหัสสำรองไว้ในที่ปลอดภัย",
+		"recovery_codes": codes,
+	})
+}
+
+// POST /auth/2fa/disable
+func (c *MFAController) Disable(ctx *gin.Context) {
+	if _, impersonated := ctx.Get("impersonator_id"); impersonated {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": services.ErrImpersonatedCredential.Error()})
+		return
+	}
+	var req dto.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุรหัสยืนยัน"})
+		return
+	}
+
+	if err := c.mfaService.Disable(middleware.CurrentUserID(ctx), req.Code); err != nil {
+		ctx.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ปิดการยืนยันตัวตนสองขั้นตอนแล้ว"})
+}
+
+// POST /auth/2fa/recovery-codes
+func (c *MFAController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	if _, impersonated := ctx.Get("impersonator_id"); impersonated {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": services.ErrImpersonatedCredential.Error()})
+		return
+	}
+	var req dto.MFACodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุรหัสยืนยัน"})
+		return
+	}
+
+	codes, err := c.mfaService.RegenerateRecoveryCodes(middleware.CurrentUserID(ctx), req.Code)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
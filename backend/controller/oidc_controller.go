@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/services"
+)
+
+const oidcStateCookie = "oidc_state"
+
+type OIDCController struct {
+	oidcService *services.OIDCService
+}
+
+func NewOIDCController(oidcService *services.OIDCService) *OIDCController {
+	return &OIDCController{oidcService: oidcService}
+}
+
+// GET /auth/oidc/config หน้าเว็บใช้ตัดสินใจว่าจะแสดงปุ่มเข้าสู่ระบบด้วยบัญชีมหาวิทยาลัยหรือไม่
+func (c *OIDCController) Config(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"enabled": c.oidcService.Enabled()})
+}
+
+// GET /auth/oidc/login เปิดจากเบราว์เซอร์โดยตรง แล้ว redirect ไปหน้า login ของ provider
+func (c *OIDCController) Login(ctx *gin.Context) {
+	authURL, state, err := c.oidcService.AuthCodeURL(ctx.Request.Context())
+	if err != nil {
+		redirectSSOError(ctx, err.Error())
+		return
+	}
+
+	// ผูก state กับเบราว์เซอร์ที่เริ่มคำขอ ป้องกัน login CSRF
+	secure := ctx.Request.TLS != nil || ctx.GetHeader("X-Forwarded-Proto") == "https"
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcStateCookie, state, 600, "/api/auth/oidc", "", secure, true)
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// GET /auth/oidc/callback provider redirect กลับมาพร้อม code แล้วส่ง token ต่อให้หน้าเว็บผ่าน URL fragment
+func (c *OIDCController) Callback(ctx *gin.Context) {
+	if errMsg := ctx.Query("error"); errMsg != "" {
+		redirectSSOError(ctx, "ผู้ให้บริการปฏิเสธการเข้าสู่ระบบ: "+errMsg)
+		return
+	}
+
+	state := ctx.Query("state")
+	cookieState, _ := ctx.Cookie(oidcStateCookie)
+	ctx.SetCookie(oidcStateCookie, "", -1, "/api/auth/oidc", "", false, true)
+	if state == "" || cookieState != state {
+		redirectSSOError(ctx, services.ErrOIDCInvalidState.Error())
+		return
+	}
+
+	res, err := c.oidcService.HandleCallback(ctx.Request.Context(), ctx.Query("code"), state, ctx.Request.UserAgent(), ctx.ClientIP())
+	if err != nil {
+		redirectSSOError(ctx, err.Error())
+		return
+	}
+
+	fragment := url.Values{}
+	if res.ChallengeToken != "" {
+		fragment.Set("challenge_token", res.ChallengeToken)
+		fragment.Set("mfa_required", strconv.FormatBool(res.MFARequired))
+		fragment.Set("mfa_setup_required", strconv.FormatBool(res.MFASetupRequired))
+		ctx.Redirect(http.StatusFound, services.FrontendURL("/login#"+fragment.Encode()))
+		return
+	}
+	fragment.Set("token_type", "Bearer")
+	fragment.Set("token", res.Token)
+	fragment.Set("refresh_token", res.RefreshToken)
+	fragment.Set("expires_in", strconv.Itoa(res.ExpiresIn))
+	fragment.Set("role", res.Role)
+	ctx.Redirect(http.StatusFound, services.FrontendURL("/oidc/callback#"+fragment.Encode()))
+}
+
+func redirectSSOError(ctx *gin.Context, message string) {
+	ctx.Redirect(http.StatusFound, services.FrontendURL("/login?sso_error="+url.QueryEscape(message)))
+}
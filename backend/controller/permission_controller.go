@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+type PermissionController struct {
+	permissionService *services.PermissionService
+}
+
+func NewPermissionController(permissionService *services.PermissionService) *PermissionController {
+	return &PermissionController{permissionService: permissionService}
+}
+
+// GET /permissions
+func (c *PermissionController) ListPermissions(ctx *gin.Context) {
+	permissions, err := c.permissionService.ListPermissions()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูลสิทธิ์ได้"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": permissions})
+}
+
+// PUT /roles/:id/permissions
+func (c *PermissionController) UpdateRolePermissions(ctx *gin.Context) {
+	roleID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "รหัสบทบาทไม่ถูกต้อง"})
+		return
+	}
+	var req dto.UpdateRolePermissionsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ข้อมูลไม่ถูกต้อง กรุณาตรวจสอบอีกครั้ง"})
+		return
+	}
+
+	role, err := c.permissionService.UpdateRolePermissions(uint(roleID), req.Permissions, middleware.CurrentUserID(ctx), ctx.ClientIP())
+	switch {
+	case errors.Is(err, services.ErrRoleNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, services.ErrUnknownPermission):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถบันทึกสิทธิ์ได้"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "บันทึกสิทธิ์ของบทบาทสำเร็จ", "data": role})
+}
+
+// canManageActivity ผู้มีสิทธิ์ activities.manage_any จัดการได้ทุกกิจกรรม
+// ส่วนสิทธิ์อื่น (เช่น registrations.approve) ใช้ได้เฉพาะกิจกรรมที่ตนเองสร้าง
+func canManageActivity(ctx *gin.Context, isOwner func() bool) bool {
+	if middleware.HasPermission(ctx, entity.PermActivitiesManageAny) || isOwner() {
+		return true
+	}
+	ctx.JSON(http.StatusForbidden, gin.H{"error": "คุณจัดการได้เฉพาะกิจกรรมที่ตนเองสร้าง"})
+	return false
+}
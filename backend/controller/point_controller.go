@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
 
@@ -168,7 +169,7 @@ func (pc *PointController) DeleteReward(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reward id"})
 		return
 	}
-	if err := pc.PointService.DeleteReward(uint(rewardId)); err != nil {
+	if err := pc.PointService.DeleteReward(uint(rewardId), middleware.CurrentUserID(c), c.ClientIP()); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete reward"})
 		return
 	}
@@ -232,7 +233,7 @@ func (pc *PointController) UpdatePostPoint(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
 		return
 	}
-	if err := pc.PointService.UpdatePostPoint(uint(postId), req.Point); err != nil {
+	if err := pc.PointService.UpdatePostPoint(uint(postId), req.Point, middleware.CurrentUserID(c), c.ClientIP()); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update post point"})
 		return
 	}
@@ -251,6 +252,16 @@ func (pc *PointController) RedeemReward(c *gin.Context) {
 		return
 	}
 
+	// นักศึกษาแลกรางวัลได้เฉพาะด้วยคะแนนของตัวเอง
+	currentUserID := middleware.CurrentUserID(c)
+	if req.UserID == 0 {
+		req.UserID = currentUserID
+	}
+	if req.UserID != currentUserID && !middleware.HasPermission(c, entity.PermPointsManage) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "ไม่สามารถแลกรางวัลแทนผู้ใช้อื่นได้"})
+		return
+	}
+
 	if err := pc.PointService.RedeemReward(req.UserID, req.RewardID); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -284,7 +295,7 @@ func (pc *PointController) DistributePoints(c *gin.Context) {
 		return
 	}
 
-	if err := pc.PointService.DistributePointsToParticipants(uint(postId)); err != nil {
+	if err := pc.PointService.DistributePointsToParticipants(uint(postId), middleware.CurrentUserID(c), c.ClientIP()); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 
 	"github.com/gin-gonic/gin"
@@ -26,6 +27,13 @@ func (c *PortfolioController) Create(ctx *gin.Context) {
 		return
 	}
 
+	// นักศึกษาสร้างผลงานได้เฉพาะของตัวเอง และเริ่มที่สถานะรอตรวจเสมอ
+	if !middleware.HasPermission(ctx, entity.PermPortfoliosReview) {
+		portfolio.UserID = middleware.CurrentUserID(ctx)
+		portfolio.PortfolioStatusID = 0
+		portfolio.AdminComment = nil
+	}
+
 	if portfolio.File_urls == "" {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาเพิ่มรูปภาพ"})
 		return
@@ -91,13 +99,27 @@ func (c *PortfolioController) Update(ctx *gin.Context) {
 		return
 	}
 
+	if !c.canManage(ctx, uint(id)) {
+		return
+	}
+
 	var input map[string]interface{}
 	if err := ctx.ShouldBindJSON(&input); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := c.portfolioService.UpdatePortfolio(uint(id), input); err != nil {
+	// การอนุมัติ/ปฏิเสธผลงานเป็นหน้าที่ของผู้ตรวจผลงาน จึงตัดฟิลด์เหล่านี้ออกเมื่อเจ้าของแก้ไขเอง
+	if middleware.HasPermission(ctx, entity.PermPortfoliosReview) {
+		err = c.portfolioService.ReviewPortfolio(uint(id), input, middleware.CurrentUserID(ctx), ctx.ClientIP())
+	} else {
+		delete(input, "portfolio_status_id")
+		delete(input, "admin_comment")
+		delete(input, "user_id")
+		err = c.portfolioService.UpdatePortfolio(uint(id), input)
+	}
+
+	if err != nil {
 		if err.Error() == "duplicate_title" {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": "คุณมีผลงานชื่อนี้อยู่แล้ว กรุณาใช้ชื่ออื่น"})
 			return
@@ -116,6 +138,10 @@ func (c *PortfolioController) Delete(ctx *gin.Context) {
 		return
 	}
 
+	if !c.canManage(ctx, uint(id)) {
+		return
+	}
+
 	if err := c.portfolioService.DeletePortfolio(uint(id)); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete portfolio"})
 		return
@@ -132,3 +158,17 @@ func (c *PortfolioController) ListStatuses(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, gin.H{"data": statuses})
 }
+
+// canManage อนุญาตให้เจ้าของผลงานหรือผู้ตรวจผลงาน แก้ไข/ลบผลงาน
+func (c *PortfolioController) canManage(ctx *gin.Context, id uint) bool {
+	portfolio, err := c.portfolioService.GetPortfolioByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Portfolio not found"})
+		return false
+	}
+	if middleware.HasPermission(ctx, entity.PermPortfoliosReview) || portfolio.UserID == middleware.CurrentUserID(ctx) {
+		return true
+	}
+	ctx.JSON(http.StatusForbidden, gin.H{"error": "คุณไม่มีสิทธิ์จัดการผลงานนี้"})
+	return false
+}
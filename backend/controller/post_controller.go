@@ -1,12 +1,15 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/dto"
 	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 ) 
 
@@ -22,9 +25,8 @@ func NewPostController(postService *services.PostService) *PostController {
 
 
 func (c *PostController) CreatePost(ctx *gin.Context) {
-	userID, exists := ctx.Get("user_id")
-
-	if !exists {
+	userID := middleware.CurrentUserID(ctx)
+	if userID == 0 {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
@@ -35,8 +37,14 @@ func (c *PostController) CreatePost(ctx *gin.Context) {
 		return
 	}
 
-	uid := userID.(uint)
-	post.UserID = &uid
+	post.UserID = &userID
+
+	// โพสต์ของผู้ใช้ทั่วไปต้องรอผู้ดูแลกิจกรรมอนุมัติก่อนเผยแพร่
+	if !middleware.HasPermission(ctx, entity.PermActivitiesManageAny) {
+		pending := entity.PostStatusPending
+		post.StatusID = &pending
+		post.Comment = ""
+	}
 	
 	result, err := c.postService.CreatePost(&post)
 	
@@ -58,8 +66,36 @@ func (c *PostController) CreatePost(ctx *gin.Context) {
 	})
 }
 
+// GetAllPost รายการโพสต์แบบแบ่งหน้า กรอง และเรียงลำดับได้ (ข้อมูลย่อสำหรับหน้ารายการ)
 func (c *PostController) GetAllPost(ctx *gin.Context) {
-	posts, err := c.postService.GetAllPost()
+	var query dto.PostListQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "เงื่อนไขค้นหาไม่ถูกต้อง (วันที่ต้องอยู่ในรูปแบบ YYYY-MM-DD)"})
+		return
+	}
+
+	page, err := c.postService.ListPosts(query, middleware.CurrentUserID(ctx), middleware.HasPermission(ctx, entity.PermActivitiesManageAny))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPostSort) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetDetailedPosts โพสต์ทั้งหมดพร้อมทีมที่สมัครและผลการแข่งขัน ใช้กับหน้าสรุปผล
+func (c *PostController) GetDetailedPosts(ctx *gin.Context) {
+	var posts []entity.Post
+	var err error
+	if middleware.HasPermission(ctx, entity.PermActivitiesManageAny) {
+		posts, err = c.postService.GetAllPost()
+	} else {
+		posts, err = c.postService.GetVisiblePosts(middleware.CurrentUserID(ctx))
+	}
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -79,8 +115,8 @@ func (c *PostController) GetPostByID(ctx *gin.Context) {
 	}
 
 	post, err := c.postService.GetPostByID(uint(id))
-	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	if err != nil || !canViewPost(ctx, post) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": services.ErrPostNotFound.Error()})
 		return
 	}
 
@@ -91,13 +127,13 @@ func (c *PostController) GetPostByID(ctx *gin.Context) {
 }
 
 func (c *PostController) GetMyPosts(ctx *gin.Context) {
-	userID, exists := ctx.Get("user_id")
-	if !exists {
+	userID := middleware.CurrentUserID(ctx)
+	if userID == 0 {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	posts, err := c.postService.GetMyPost(userID.(uint))
+	posts, err := c.postService.GetMyPost(userID)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -116,16 +152,40 @@ func (c *PostController) UpdatePost(ctx *gin.Context) {
 		return
 	}
 
+	existing, err := c.postService.GetPostByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !canManagePost(ctx, existing) {
+		return
+	}
+
 	var post entity.Post
 	if err := ctx.ShouldBindJSON(&post); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// สถานะ ความเห็นผู้ตรวจ คะแนน และเจ้าของโพสต์ เปลี่ยนได้เฉพาะผู้ที่จัดการได้ทุกกิจกรรม
+	// เจ้าของแก้ไขได้เฉพาะโพสต์ที่ยังไม่อนุมัติ และโพสต์ที่ไม่ผ่านจะถูกส่งตรวจใหม่หลังแก้ไข
+	resubmit := false
+	if !middleware.HasPermission(ctx, entity.PermActivitiesManageAny) {
+		if existing.StatusID == nil || entity.IsPublishedPostStatus(*existing.StatusID) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": services.ErrPostLocked.Error()})
+			return
+		}
+		resubmit = *existing.StatusID == entity.PostStatusRejected
+		post.StatusID = existing.StatusID
+		post.Comment = existing.Comment
+		post.PostPoint = existing.PostPoint
+		post.UserID = existing.UserID
+	}
+
 	err = c.postService.UpdatePost(uint(id), &post)
 	if err != nil {
 		// เช็คว่าหาไม่เจอ หรือเป็น error อื่นๆ (เช่น validation)
-		if err.Error() == "post not found" {
+		if errors.Is(err, services.ErrPostNotFound) {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		} else {
 			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -133,6 +193,13 @@ func (c *PostController) UpdatePost(ctx *gin.Context) {
 		return
 	}
 
+	if resubmit {
+		if _, err := c.postService.SubmitPost(uint(id), middleware.CurrentUserID(ctx)); err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "Post updated successfully",
 	})
@@ -145,6 +212,15 @@ func (c *PostController) DeletePost(ctx *gin.Context) {
 		return
 	}
 
+	existing, err := c.postService.GetPostByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if !canManagePost(ctx, existing) {
+		return
+	}
+
 	err = c.postService.DeletePost(uint(id))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -156,6 +232,187 @@ func (c *PostController) DeletePost(ctx *gin.Context) {
 	})
 }
 
+// canManagePost อนุญาตให้เจ้าของโพสต์หรือผู้ที่จัดการได้ทุกกิจกรรมแก้ไข/ลบโพสต์
+func canManagePost(ctx *gin.Context, post *entity.Post) bool {
+	if middleware.HasPermission(ctx, entity.PermActivitiesManageAny) {
+		return true
+	}
+	if post.UserID != nil && *post.UserID == middleware.CurrentUserID(ctx) {
+		return true
+	}
+	ctx.JSON(http.StatusForbidden, gin.H{"error": "คุณไม่มีสิทธิ์จัดการโพสต์นี้"})
+	return false
+}
+
+// canViewPost โพสต์ที่ยังไม่อนุมัติเห็นได้เฉพาะเจ้าของและผู้ดูแลกิจกรรม
+func canViewPost(ctx *gin.Context, post *entity.Post) bool {
+	if post.StatusID != nil && entity.IsPublishedPostStatus(*post.StatusID) {
+		return true
+	}
+	if post.UserID != nil && *post.UserID == middleware.CurrentUserID(ctx) {
+		return true
+	}
+	return middleware.HasPermission(ctx, entity.PermActivitiesManageAny)
+}
+
+// SubmitPost เจ้าของส่งโพสต์ที่ไม่ผ่านการอนุมัติกลับไปตรวจอีกครั้ง
+func (c *PostController) SubmitPost(ctx *gin.Context) {
+	post, ok := c.loadPost(ctx)
+	if !ok || !canManagePost(ctx, post) {
+		return
+	}
+
+	result, err := c.postService.SubmitPost(post.ID, middleware.CurrentUserID(ctx))
+	if err != nil {
+		respondPostReviewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Post submitted for review",
+		"data":    result,
+	})
+}
+
+func (c *PostController) ApprovePost(ctx *gin.Context) {
+	c.reviewPost(ctx, true)
+}
+
+func (c *PostController) RejectPost(ctx *gin.Context) {
+	c.reviewPost(ctx, false)
+}
+
+func (c *PostController) reviewPost(ctx *gin.Context, approve bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req dto.ReviewPostRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil && !approve {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": services.ErrRejectCommentMissing.Error()})
+		return
+	}
+
+	result, err := c.postService.ReviewPost(uint(id), middleware.CurrentUserID(ctx), approve, req.Comment, ctx.ClientIP())
+	if err != nil {
+		respondPostReviewError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Post reviewed successfully",
+		"data":    result,
+	})
+}
+
+// ReviewQueue โพสต์ที่รอตรวจ สำหรับผู้ดูแลกิจกรรม
+func (c *PostController) ReviewQueue(ctx *gin.Context) {
+	posts, err := c.postService.GetReviewQueue()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Review queue retrieved successfully",
+		"data":    posts,
+	})
+}
+
+// GetPostReviews ประวัติการตรวจโพสต์ เจ้าของใช้ดูความเห็นของผู้ตรวจทุกรอบ
+func (c *PostController) GetPostReviews(ctx *gin.Context) {
+	post, ok := c.loadPost(ctx)
+	if !ok || !canManagePost(ctx, post) {
+		return
+	}
+
+	reviews, err := c.postService.GetPostReviews(post.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Post reviews retrieved successfully",
+		"data":    reviews,
+	})
+}
+
+func (c *PostController) GetRegistrationForm(ctx *gin.Context) {
+	post, ok := c.loadPost(ctx)
+	if !ok {
+		return
+	}
+	if !canViewPost(ctx, post) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": services.ErrPostNotFound.Error()})
+		return
+	}
+
+	fields, err := c.postService.GetRegistrationForm(post.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": fields})
+}
+
+// SaveRegistrationForm แทนที่แบบฟอร์มสมัครทั้งชุด คำตอบที่สมัครไว้แล้วไม่ถูกตรวจซ้ำ
+func (c *PostController) SaveRegistrationForm(ctx *gin.Context) {
+	post, ok := c.loadPost(ctx)
+	if !ok || !canManagePost(ctx, post) {
+		return
+	}
+
+	var req dto.SaveRegistrationFormRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fields, err := c.postService.SaveRegistrationForm(post.ID, req.Fields, middleware.CurrentUserID(ctx), ctx.ClientIP())
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidFormField) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Registration form saved successfully", "data": fields})
+}
+
+func (c *PostController) loadPost(ctx *gin.Context) (*entity.Post, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return nil, false
+	}
+
+	post, err := c.postService.GetPostByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return post, true
+}
+
+func respondPostReviewError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPostNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrRejectCommentMissing):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPostNotPending), errors.Is(err, services.ErrPostNotRejected):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func (c *PostController) GetStudentPosts(ctx *gin.Context) {
 	posts, err := c.postService.GetActivePostsForStudent()
 	if err != nil {
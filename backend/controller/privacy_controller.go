@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+type PrivacyController struct {
+	privacyService *services.PrivacyService
+}
+
+func NewPrivacyController(privacyService *services.PrivacyService) *PrivacyController {
+	return &PrivacyController{privacyService: privacyService}
+}
+
+func privacyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidPassword):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrCannotDeleteAdmin), errors.Is(err, services.ErrImpersonatedDeletion):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrAlreadyAnonymized):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// GET /profiles/me/export
+func (c *PrivacyController) ExportMyData(ctx *gin.Context) {
+	userID := middleware.CurrentUserID(ctx)
+	var buf bytes.Buffer
+	if err := c.privacyService.Export(userID, &buf); err != nil {
+		ctx.JSON(privacyErrorStatus(err), gin.H{"error": "ไม่สามารถส่งออกข้อมูลส่วนบุคคลได้"})
+		return
+	}
+	sutID, _ := ctx.Get("sut_id")
+	filename, _ := sutID.(string)
+	ctx.Header("Content-Disposition", `attachment; filename="engiconnect-data-`+filename+`.zip"`)
+	ctx.Data(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+// DELETE /profiles/me
+func (c *PrivacyController) DeleteMyAccount(ctx *gin.Context) {
+	if _, impersonated := ctx.Get("impersonator_id"); impersonated {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": services.ErrImpersonatedDeletion.Error()})
+		return
+	}
+	var req dto.DeleteAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณายืนยันด้วยรหัสผ่าน"})
+		return
+	}
+	if err := c.privacyService.DeleteOwnAccount(middleware.CurrentUserID(ctx), req.Password, ctx.ClientIP()); err != nil {
+		ctx.JSON(privacyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ลบบัญชีและข้อมูลส่วนบุคคลเรียบร้อยแล้ว"})
+}
+
+// POST /users/:id/anonymize
+func (c *PrivacyController) Anonymize(ctx *gin.Context) {
+	userID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+	if userID == middleware.CurrentUserID(ctx) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": services.ErrCannotDeleteAdmin.Error()})
+		return
+	}
+	if err := c.privacyService.Anonymize(userID, middleware.CurrentUserID(ctx), ctx.ClientIP()); err != nil {
+		ctx.JSON(privacyErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ลบข้อมูลส่วนบุคคลของผู้ใช้เรียบร้อยแล้ว"})
+}
@@ -1,12 +1,22 @@
 package controller
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sut68/team21/dto"
 	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
 
@@ -17,6 +27,8 @@ type RegistrationController struct {
 type UpdateRegistrationRequest struct {
 	TeamName    string `json:"team_name" binding:"required,min=3"`
 	Description string `json:"description"`
+	// ส่งเมื่อต้องการแก้คำตอบแบบฟอร์มสมัคร
+	Answers []dto.RegistrationAnswerInput `json:"answers"`
 }
 
 func NewRegistrationController(registrationService *services.RegistrationService) *RegistrationController {
@@ -32,6 +44,16 @@ type CreateRegistrationRequest struct {
 	Status           string `json:"status"`
 	RegistrationDate string `json:"registration_date"`
 	UserIDs          []uint `json:"user_ids"` 
+	Answers          []dto.RegistrationAnswerInput `json:"answers"`
+}
+
+// creatableRegistrationStatuses สถานะที่ผู้จัดการระบุได้ตอนเพิ่มทีม
+// offered ต้องมาจากคิวสำรอง ส่วน expired และ withdrawn เกิดหลังสมัครแล้วเท่านั้น
+var creatableRegistrationStatuses = []string{
+	entity.RegistrationStatusPending,
+	entity.RegistrationStatusApproved,
+	entity.RegistrationStatusRejected,
+	entity.RegistrationStatusWaitlisted,
 }
 
 func (c *RegistrationController) CreateRegistration(ctx *gin.Context) {
@@ -42,41 +64,18 @@ func (c *RegistrationController) CreateRegistration(ctx *gin.Context) {
 		return
 	}
 
-	if req.Status == "" {
+	// นักศึกษาสร้างได้เฉพาะสถานะ pending ส่วนการอนุมัติทำผ่าน PUT /registration/:id/status
+	if req.Status == "" || !middleware.HasPermission(ctx, entity.PermActivitiesManageAny) {
 		req.Status = "pending"
+	} else if !slices.Contains(creatableRegistrationStatuses, req.Status) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "สถานะการสมัครไม่ถูกต้อง"})
+		return
 	}
 
-	userIDValue, exists := ctx.Get("user_id")
-	if exists {
-		var currentUserID uint
-
-		switch v := userIDValue.(type) {
-		case uint:
-			currentUserID = v
-		case float64:
-			currentUserID = uint(v)
-		case int:
-			currentUserID = uint(v)
-		case string:
-			if id, err := strconv.ParseUint(v, 10, 32); err == nil {
-				currentUserID = uint(id)
-			}
-		}
-
-		if currentUserID != 0 {
-
-			found := false
-			for _, uid := range req.UserIDs {
-				if uid == currentUserID {
-					found = true
-					break
-				}
-			}
-
-			if !found {
-				req.UserIDs = append(req.UserIDs, currentUserID)
-			}
-		}
+	// ผู้สร้างเป็นสมาชิกของทีมเสมอ
+	currentUserID := middleware.CurrentUserID(ctx)
+	if currentUserID != 0 && !slices.Contains(req.UserIDs, currentUserID) {
+		req.UserIDs = append(req.UserIDs, currentUserID)
 	}
 
 	registration := &entity.Registration{
@@ -86,17 +85,72 @@ func (c *RegistrationController) CreateRegistration(ctx *gin.Context) {
 		PostID:      &req.PostID,
 	}
 
-	result, err := c.registrationService.CreateRegistrationWithUserIDs(registration, req.UserIDs)
+	// ผู้จัดการทุกกิจกรรมเพิ่มทีมนอกช่วงรับสมัครและเพิ่มสมาชิกได้ทันที
+	// ส่วนนักศึกษาเข้าทีมทันทีเฉพาะผู้สร้าง คนอื่นใน user_ids ได้รับคำเชิญและต้องตอบรับก่อน
+	manager := middleware.HasPermission(ctx, entity.PermActivitiesManageAny)
+	memberIDs := req.UserIDs
+	opts := services.RegistrationOptions{InviterID: currentUserID, LeaderID: currentUserID, Answers: req.Answers, OverrideWindow: manager}
+	if !manager && currentUserID != 0 {
+		memberIDs = []uint{currentUserID}
+		opts.InviteeIDs = req.UserIDs
+	}
+	result, err := c.registrationService.CreateRegistrationWithUserIDs(registration, memberIDs, opts)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusCreated, gin.H{"data": result})
 }
 
+// registrationErrorStatus HTTP status ของ error จากการสมัคร: ที่นั่งเต็ม/เป็นสมาชิกอยู่แล้ว/มีทีมในกิจกรรมนี้แล้ว/เวลาซ้อน
+// ยืนยันที่นั่ง ตอบคำเชิญ หรือถอนการสมัครไม่ได้ 409
+// นอกช่วงรับสมัคร/ไม่มีสิทธิ์สมัคร 403 ไม่พบข้อมูล 404 อื่นๆ 400
+func registrationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrTeamsFull), errors.Is(err, services.ErrSeatsFull), errors.Is(err, services.ErrAlreadyMember),
+		errors.Is(err, services.ErrNotOffered), errors.Is(err, services.ErrOfferExpired),
+		errors.Is(err, services.ErrAlreadyInvited), errors.Is(err, services.ErrInvitationClosed), errors.Is(err, services.ErrInvitationExpired),
+		errors.Is(err, services.ErrDuplicateRegistration), errors.Is(err, services.ErrScheduleConflict),
+		errors.Is(err, services.ErrCannotWithdraw), errors.Is(err, services.ErrRegistrationWithdrawn), errors.Is(err, services.ErrActivityEnded),
+		errors.Is(err, services.ErrTeamClosed):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrRegistrationNotOpen), errors.Is(err, services.ErrRegistrationClosed), errors.Is(err, services.ErrNotEligible):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrPostNotFound), errors.Is(err, services.ErrInvitationNotFound),
+		err.Error() == "registration not found", err.Error() == "user not found":
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// canManage อนุญาตให้สมาชิกในทีมหรือผู้ที่จัดการได้ทุกกิจกรรม จัดการการลงทะเบียน
+func (c *RegistrationController) canManage(ctx *gin.Context, registrationID string) bool {
+	if middleware.HasPermission(ctx, entity.PermActivitiesManageAny) || c.registrationService.IsMember(registrationID, middleware.CurrentUserID(ctx)) {
+		return true
+	}
+	ctx.JSON(http.StatusForbidden, gin.H{"error": "คุณไม่ได้เป็นสมาชิกของทีมนี้"})
+	return false
+}
+
+// canLead อนุญาตเฉพาะหัวหน้าทีมหรือผู้ที่จัดการได้ทุกกิจกรรม แก้ไขทีม จัดการสมาชิก และถอนการสมัคร
+func (c *RegistrationController) canLead(ctx *gin.Context, registrationID string) bool {
+	if !c.canManage(ctx, registrationID) {
+		return false
+	}
+	if middleware.HasPermission(ctx, entity.PermActivitiesManageAny) || c.registrationService.IsLeader(registrationID, middleware.CurrentUserID(ctx)) {
+		return true
+	}
+	ctx.JSON(http.StatusForbidden, gin.H{"error": "เฉพาะหัวหน้าทีมเท่านั้นที่ทำรายการนี้ได้"})
+	return false
+}
+
 func (c *RegistrationController) UpdateRegistration(ctx *gin.Context) {
 	id := ctx.Param("id")
+	if !c.canLead(ctx, id) {
+		return
+	}
 
 	var req UpdateRegistrationRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -104,18 +158,9 @@ func (c *RegistrationController) UpdateRegistration(ctx *gin.Context) {
 		return
 	}
 
-	payload := &entity.Registration{
-		TeamName:    req.TeamName,
-		Description: req.Description,
-	}
-
-	result, err := c.registrationService.UpdateRegistration(id, payload)
+	result, err := c.registrationService.UpdateTeamDetails(id, req.TeamName, req.Description, req.Answers)
 	if err != nil {
-		if err.Error() == "registration not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		} else {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		}
+		ctx.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -124,6 +169,11 @@ func (c *RegistrationController) UpdateRegistration(ctx *gin.Context) {
 
 func (c *RegistrationController) UpdateRegistrationStatus(ctx *gin.Context) {
 	id := ctx.Param("id")
+	if !canManageActivity(ctx, func() bool {
+		return c.registrationService.IsPostOwner(id, middleware.CurrentUserID(ctx))
+	}) {
+		return
+	}
 
 	var req struct {
 		Status string `json:"status" binding:"required"`
@@ -140,24 +190,32 @@ func (c *RegistrationController) UpdateRegistrationStatus(ctx *gin.Context) {
 		return
 	}
 
-	payload := &entity.Registration{
-		Status: req.Status,
-		RejectionReason: req.Reason,
+	result, err := c.registrationService.UpdateRegistrationStatus(id, req.Status, req.Reason, middleware.CurrentUserID(ctx), ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": result, "message": "status updated successfully"})
+}
+
+// ConfirmRegistration สมาชิกยืนยันที่นั่งที่ได้จากคิวสำรองก่อนหมดเวลา
+func (c *RegistrationController) ConfirmRegistration(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if !c.canManage(ctx, id) {
+		return
 	}
 
-	result, err := c.registrationService.UpdateRegistration(id, payload)
+	result, err := c.registrationService.ConfirmOffer(id, middleware.CurrentUserID(ctx), time.Now())
 	if err != nil {
-		if err.Error() == "registration not found" {
-			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		} else {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		}
+		ctx.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"data": result, "message": "status updated successfully"})
+	ctx.JSON(http.StatusOK, gin.H{"data": result, "message": "seat confirmed"})
 }
 
+// DeleteRegistration ลบทีมถาวร (activities.manage_any ตรวจที่ route)
 func (c *RegistrationController) DeleteRegistration(ctx *gin.Context) {
 	id := ctx.Param("id")
 
@@ -169,6 +227,50 @@ func (c *RegistrationController) DeleteRegistration(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"data": "id " + id + " deleted"})
 }
 
+// WithdrawRegistration หัวหน้าทีมถอนการสมัคร ทีมยังอยู่ในสถานะ withdrawn พร้อมเหตุผล
+func (c *RegistrationController) WithdrawRegistration(ctx *gin.Context) {
+	id := ctx.Param("id")
+	if !c.canLead(ctx, id) {
+		return
+	}
+
+	var req dto.WithdrawRegistrationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": services.ErrWithdrawalReason.Error()})
+		return
+	}
+
+	result, err := c.registrationService.Withdraw(id, req.Reason, middleware.CurrentUserID(ctx), ctx.ClientIP(), time.Now())
+	if err != nil {
+		ctx.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": result, "message": "registration withdrawn"})
+}
+
+// GetWithdrawalReport รายงานการถอนการสมัครของกิจกรรม: เจ้าของกิจกรรมหรือ activities.manage_any
+func (c *RegistrationController) GetWithdrawalReport(ctx *gin.Context) {
+	postID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	if !canManageActivity(ctx, func() bool {
+		return services.IsPostOwner(c.registrationService.GetDB(), uint(postID), middleware.CurrentUserID(ctx))
+	}) {
+		return
+	}
+
+	report, err := c.registrationService.WithdrawalReport(uint(postID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": report})
+}
+
 func (c *RegistrationController) GetRegistrationByID(ctx *gin.Context) {
 	id := ctx.Param("id")
 
@@ -177,6 +279,12 @@ func (c *RegistrationController) GetRegistrationByID(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	userID := middleware.CurrentUserID(ctx)
+	if !middleware.HasPermission(ctx, entity.PermActivitiesManageAny) &&
+		!c.registrationService.IsMember(id, userID) && !c.registrationService.IsPostOwner(id, userID) {
+		result.Answers = nil
+		result.StatusHistory = nil
+	}
 
 	ctx.JSON(http.StatusOK, gin.H{"data": result})
 }
@@ -189,13 +297,135 @@ func (c *RegistrationController) GetRegistrationsByPostID(ctx *gin.Context) {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
+	// คำตอบแบบฟอร์มสมัครเห็นได้เฉพาะผู้จัดกิจกรรมและสมาชิกของทีมนั้น
+	if !c.canSeeAllAnswers(ctx, postID) {
+		userID := middleware.CurrentUserID(ctx)
+		for i := range result {
+			if !slices.ContainsFunc(result[i].Users, func(u *entity.User) bool { return u.ID == userID }) {
+				result[i].Answers = nil
+			}
+		}
+	}
 
 	ctx.JSON(http.StatusOK, gin.H{"data": result})
 }
 
+// ExportRegistrations ดาวน์โหลดรายชื่อทีมของกิจกรรมพร้อมคำตอบแบบฟอร์มสมัครเป็น CSV
+func (c *RegistrationController) ExportRegistrations(ctx *gin.Context) {
+	postID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+	if !canManageActivity(ctx, func() bool {
+		return services.IsPostOwner(c.registrationService.GetDB(), uint(postID), middleware.CurrentUserID(ctx))
+	}) {
+		return
+	}
+
+	// BOM ให้ Excel อ่านภาษาไทยได้ถูกต้อง
+	buf := bytes.NewBufferString("\ufeff")
+	if err := c.registrationService.ExportRegistrationsCSV(uint(postID), buf); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถส่งออกรายชื่อผู้สมัครได้"})
+		return
+	}
+	filename := fmt.Sprintf("registrations-post-%d-%s.csv", postID, time.Now().Format("20060102-150405"))
+	ctx.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// UploadAnswerFile อัปโหลดไฟล์สำหรับคำถามชนิด file แล้วส่ง url ที่ได้เป็นคำตอบ
+func (c *RegistrationController) UploadAnswerFile(ctx *gin.Context) {
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาเลือกไฟล์"})
+		return
+	}
+
+	ext := strings.ToLower(filepath.Ext(file.Filename))
+	allowedExts := map[string]bool{".pdf": true, ".jpg": true, ".jpeg": true, ".png": true, ".zip": true, ".docx": true, ".pptx": true}
+	if !allowedExts[ext] {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ประเภทไฟล์ไม่ถูกต้อง รองรับเฉพาะ: pdf, jpg, jpeg, png, zip, docx, pptx"})
+		return
+	}
+	if file.Size > 10*1024*1024 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ขนาดไฟล์ต้องไม่เกิน 10MB"})
+		return
+	}
+
+	if err := os.MkdirAll(services.RegistrationUploadDir, 0755); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถบันทึกไฟล์ได้"})
+		return
+	}
+	fileURL := services.RegistrationUploadDir + uuid.New().String() + ext
+	if err := ctx.SaveUploadedFile(file, fileURL); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถบันทึกไฟล์ได้"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"url": fileURL})
+}
+
+// canSeeAllAnswers ผู้จัดกิจกรรม (เจ้าของโพสต์) และผู้จัดการทุกกิจกรรมเห็นคำตอบของทุกทีม
+func (c *RegistrationController) canSeeAllAnswers(ctx *gin.Context, postID string) bool {
+	if middleware.HasPermission(ctx, entity.PermActivitiesManageAny) {
+		return true
+	}
+	id, err := strconv.ParseUint(postID, 10, 32)
+	return err == nil && services.IsPostOwner(c.registrationService.GetDB(), uint(id), middleware.CurrentUserID(ctx))
+}
+
+// AddUserToRegistration ผู้จัดการทุกกิจกรรมเพิ่มสมาชิกได้ทันที ส่วนหัวหน้าทีมจะเป็นการส่งคำเชิญแทน
 func (c *RegistrationController) AddUserToRegistration(ctx *gin.Context) {
 	registrationID := ctx.Param("id")
+	if !c.canLead(ctx, registrationID) {
+		return
+	}
+
+	userID, ok := c.bindInvitee(ctx)
+	if !ok {
+		return
+	}
+
+	if !middleware.HasPermission(ctx, entity.PermActivitiesManageAny) {
+		c.invite(ctx, registrationID, userID)
+		return
+	}
+
+	warnings, err := c.registrationService.AddUserToRegistration(registrationID, userID)
+	if err != nil {
+		ctx.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "user added to registration successfully", "warnings": warnings})
+}
+
+// InviteMember หัวหน้าทีมเชิญผู้ใช้เข้าทีมด้วย sut_id (หรือ user_id)
+func (c *RegistrationController) InviteMember(ctx *gin.Context) {
+	registrationID := ctx.Param("id")
+	if !c.canLead(ctx, registrationID) {
+		return
+	}
 
+	userID, ok := c.bindInvitee(ctx)
+	if !ok {
+		return
+	}
+	c.invite(ctx, registrationID, userID)
+}
+
+func (c *RegistrationController) invite(ctx *gin.Context, registrationID string, userID uint) {
+	invitation, err := c.registrationService.InviteMember(registrationID, middleware.CurrentUserID(ctx), userID, time.Now())
+	if err != nil {
+		ctx.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"data": invitation, "message": "invitation sent"})
+}
+
+// bindInvitee อ่านผู้ใช้จาก sut_id หรือ user_id ใน body
+func (c *RegistrationController) bindInvitee(ctx *gin.Context) (uint, bool) {
 	var req struct {
 		UserID uint   `json:"user_id"`
 		SutID  string `json:"sut_id"`
@@ -203,38 +433,93 @@ func (c *RegistrationController) AddUserToRegistration(ctx *gin.Context) {
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return 0, false
 	}
 
-	var userID uint
-
 	if req.SutID != "" {
 		var user entity.User
 		if err := c.registrationService.GetDB().
 			Where("sut_id = ?", req.SutID).
 			First(&user).Error; err != nil {
 			ctx.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบผู้ใช้จาก SUT ID"})
-			return
+			return 0, false
 		}
-		userID = user.ID
-	} else if req.UserID != 0 {
-		userID = req.UserID
-	} else {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "ต้องระบุ user_id หรือ sut_id"})
+		return user.ID, true
+	}
+	if req.UserID != 0 {
+		return req.UserID, true
+	}
+	ctx.JSON(http.StatusBadRequest, gin.H{"error": "ต้องระบุ user_id หรือ sut_id"})
+	return 0, false
+}
+
+// ListTeamInvitations คำเชิญทั้งหมดของทีม (สมาชิกในทีม)
+func (c *RegistrationController) ListTeamInvitations(ctx *gin.Context) {
+	registrationID := ctx.Param("id")
+	if !c.canManage(ctx, registrationID) {
 		return
 	}
 
-	if err := c.registrationService.AddUserToRegistration(registrationID, userID); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	invitations, err := c.registrationService.ListTeamInvitations(registrationID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "user added to registration successfully"})
+	ctx.JSON(http.StatusOK, gin.H{"data": invitations})
 }
 
+// CancelInvitation หัวหน้าทีมยกเลิกคำเชิญที่ยังรอตอบ
+func (c *RegistrationController) CancelInvitation(ctx *gin.Context) {
+	registrationID := ctx.Param("id")
+	if !c.canLead(ctx, registrationID) {
+		return
+	}
 
+	if err := c.registrationService.CancelInvitation(registrationID, ctx.Param("invitationId")); err != nil {
+		ctx.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "invitation cancelled"})
+}
+
+// GetMyInvitations คำเชิญเข้าทีมที่รอผู้ใช้ตอบ
+func (c *RegistrationController) GetMyInvitations(ctx *gin.Context) {
+	invitations, err := c.registrationService.ListMyInvitations(middleware.CurrentUserID(ctx), time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": invitations})
+}
+
+func (c *RegistrationController) AcceptInvitation(ctx *gin.Context) {
+	c.respondInvitation(ctx, true)
+}
+
+func (c *RegistrationController) DeclineInvitation(ctx *gin.Context) {
+	c.respondInvitation(ctx, false)
+}
+
+// respondInvitation ตอบคำเชิญได้เฉพาะผู้ได้รับเชิญ (คำเชิญของคนอื่นตอบ 404)
+func (c *RegistrationController) respondInvitation(ctx *gin.Context, accept bool) {
+	invitation, err := c.registrationService.RespondInvitation(ctx.Param("invitationId"), middleware.CurrentUserID(ctx), accept, time.Now())
+	if err != nil {
+		ctx.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": invitation, "message": "invitation " + invitation.Status})
+}
+
+// RemoveUserFromRegistration หัวหน้าทีมนำสมาชิกออก หรือสมาชิกออกจากทีมเอง
 func (c *RegistrationController) RemoveUserFromRegistration(ctx *gin.Context) {
 	registrationID := ctx.Param("id")
+	if !c.canManage(ctx, registrationID) {
+		return
+	}
 
 	var req struct {
 		UserID uint `json:"user_id" binding:"required"`
@@ -245,38 +530,47 @@ func (c *RegistrationController) RemoveUserFromRegistration(ctx *gin.Context) {
 		return
 	}
 
+	if req.UserID != middleware.CurrentUserID(ctx) && !c.canLead(ctx, registrationID) {
+		return
+	}
+
 	if err := c.registrationService.RemoveUserFromRegistration(registrationID, req.UserID); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "user removed from registration successfully"})
 }
 
-func (c *RegistrationController) GetMyRegistrations(ctx *gin.Context) {
-	userIDValue, exists := ctx.Get("user_id")
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+// TransferLeadership หัวหน้าทีมโอนตำแหน่งให้สมาชิกคนอื่นในทีม
+func (c *RegistrationController) TransferLeadership(ctx *gin.Context) {
+	registrationID := ctx.Param("id")
+	if !c.canLead(ctx, registrationID) {
 		return
 	}
 
-	var userID uint
-	switch v := userIDValue.(type) {
-	case uint:
-		userID = v
-	case float64:
-		userID = uint(v)
-	case string:
-		id, err := strconv.ParseUint(v, 10, 32)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
-			return
-		}
-		userID = uint(id)
-	case int:
-		userID = uint(v)
-	default:
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID format"})
+	var req struct {
+		UserID uint `json:"user_id" binding:"required"`
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	registration, err := c.registrationService.TransferLeadership(registrationID, req.UserID)
+	if err != nil {
+		ctx.JSON(registrationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"data": registration, "message": "leadership transferred"})
+}
+
+func (c *RegistrationController) GetMyRegistrations(ctx *gin.Context) {
+	userID := middleware.CurrentUserID(ctx)
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
 		return
 	}
 
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
 
@@ -85,7 +86,7 @@ func (rc *ResultsController) UpdateResult(c *gin.Context) {
 		result.EditReason = &req.EditReason
 	}
 
-	if err := rc.ResultsService.UpdateResult(result); err != nil {
+	if err := rc.ResultsService.UpdateResult(result, middleware.CurrentUserID(c), c.ClientIP()); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update result"})
 		return
 	}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+type SearchController struct {
+	searchService *services.SearchService
+}
+
+func NewSearchController(searchService *services.SearchService) *SearchController {
+	return &SearchController{searchService: searchService}
+}
+
+// GET /search?q=...&type=posts,users&limit=5
+func (c *SearchController) Search(ctx *gin.Context) {
+	var query dto.SearchQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "เงื่อนไขค้นหาไม่ถูกต้อง"})
+		return
+	}
+
+	viewer := services.SearchViewer{
+		UserID:           middleware.CurrentUserID(ctx),
+		ManageActivities: middleware.HasPermission(ctx, entity.PermActivitiesManageAny),
+		ReviewPortfolios: middleware.HasPermission(ctx, entity.PermPortfoliosReview),
+	}
+	result, err := c.searchService.Search(query, viewer)
+	if err != nil {
+		if errors.Is(err, services.ErrSearchQueryRequired) || errors.Is(err, services.ErrInvalidSearchType) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถค้นหาได้"})
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+type UserAdminController struct {
+	userAdminService *services.UserAdminService
+}
+
+func NewUserAdminController(userAdminService *services.UserAdminService) *UserAdminController {
+	return &UserAdminController{userAdminService: userAdminService}
+}
+
+func userAdminErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound), errors.Is(err, services.ErrRoleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrRoleExists), errors.Is(err, services.ErrAlreadyAnonymized):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrInvalidRoleName):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrCannotModifySelf), errors.Is(err, services.ErrCannotImpersonateAdmin):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+func targetUserID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "รหัสผู้ใช้ไม่ถูกต้อง"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GET /roles
+func (c *UserAdminController) ListRoles(ctx *gin.Context) {
+	roles, err := c.userAdminService.ListRoles()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูลบทบาทได้"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"data": roles})
+}
+
+// POST /roles
+func (c *UserAdminController) CreateRole(ctx *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุชื่อบทบาท"})
+		return
+	}
+	role, err := c.userAdminService.CreateRole(req.Name, middleware.CurrentUserID(ctx), ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(userAdminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{"message": "สร้างบทบาทสำเร็จ", "data": role})
+}
+
+// PUT /users/:id/role
+func (c *UserAdminController) ChangeRole(ctx *gin.Context) {
+	userID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+	var req dto.ChangeRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุบทบาท"})
+		return
+	}
+	user, err := c.userAdminService.ChangeRole(userID, req.RoleID, middleware.CurrentUserID(ctx), ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(userAdminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "เปลี่ยนบทบาทสำเร็จ ผู้ใช้ต้องเข้าสู่ระบบใหม่", "data": user})
+}
+
+// POST /users/:id/deactivate
+func (c *UserAdminController) Deactivate(ctx *gin.Context) {
+	userID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+	var req dto.DeactivateUserRequest
+	_ = ctx.ShouldBindJSON(&req)
+	if err := c.userAdminService.Deactivate(userID, req.Reason, middleware.CurrentUserID(ctx), ctx.ClientIP()); err != nil {
+		ctx.JSON(userAdminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ระงับบัญชีสำเร็จ"})
+}
+
+// POST /users/:id/reactivate
+func (c *UserAdminController) Reactivate(ctx *gin.Context) {
+	userID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+	if err := c.userAdminService.Reactivate(userID, middleware.CurrentUserID(ctx), ctx.ClientIP()); err != nil {
+		ctx.JSON(userAdminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "เปิดใช้งานบัญชีอีกครั้งสำเร็จ"})
+}
+
+// POST /users/:id/force-password-reset
+func (c *UserAdminController) ForcePasswordReset(ctx *gin.Context) {
+	userID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+	if err := c.userAdminService.ForcePasswordReset(userID, middleware.CurrentUserID(ctx), ctx.ClientIP()); err != nil {
+		ctx.JSON(userAdminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "บังคับรีเซ็ตรหัสผ่านแล้ว ระบบได้ส่งลิงก์ตั้งรหัสผ่านใหม่ไปที่อีเมลของผู้ใช้"})
+}
+
+// POST /users/:id/impersonate
+func (c *UserAdminController) Impersonate(ctx *gin.Context) {
+	userID, ok := targetUserID(ctx)
+	if !ok {
+		return
+	}
+	res, err := c.userAdminService.Impersonate(userID, middleware.CurrentUserID(ctx), ctx.ClientIP())
+	if err != nil {
+		ctx.JSON(userAdminErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "ออก token สวมสิทธิ์สำเร็จ การใช้งานถูกบันทึกไว้", "data": res})
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/sut68/team21/entity"
+)
+
+// AuditLogQuery เงื่อนไขค้นหา audit log จาก query string (from/to รูปแบบ YYYY-MM-DD)
+type AuditLogQuery struct {
+	ActorID    uint      `form:"actor_id"`
+	ActorSutID string    `form:"actor_sut_id"`
+	Action     string    `form:"action"`
+	TargetType string    `form:"target_type"`
+	TargetID   string    `form:"target_id"`
+	IPAddress  string    `form:"ip"`
+	From       time.Time `form:"from" time_format:"2006-01-02"`
+	To         time.Time `form:"to" time_format:"2006-01-02"`
+	Page       int       `form:"page"`
+	Limit      int       `form:"limit"`
+}
+
+type AuditLogEntry struct {
+	entity.AuditLog
+	ActorSutID string `json:"actor_sut_id"`
+}
+
+type AuditLogPage struct {
+	Data  []AuditLogEntry `json:"data"`
+	Total int64           `json:"total"`
+	Page  int             `json:"page"`
+	Limit int             `json:"limit"`
+}
@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/sut68/team21/entity"
+)
+
+type ReviewPostRequest struct {
+	Comment string `json:"comment"`
+}
+
+// PostListQuery เงื่อนไขของ GET /post จาก query string (from/to รูปแบบ YYYY-MM-DD)
+// sort เป็นชื่อฟิลด์ ใส่ "-" นำหน้าเพื่อเรียงจากมากไปน้อย เช่น -start_date
+type PostListQuery struct {
+	Q          string    `form:"q"`
+	Type       string    `form:"type"`
+	StatusID   uint      `form:"status_id"`
+	LocationID uint      `form:"location_id"`
+	Organizer  string    `form:"organizer"`
+	From       time.Time `form:"from" time_format:"2006-01-02"`
+	To         time.Time `form:"to" time_format:"2006-01-02"`
+	Open       bool      `form:"open"`
+	Sort       string    `form:"sort"`
+	Page       int       `form:"page"`
+	Limit      int       `form:"limit"`
+}
+
+type PostStatusSummary struct {
+	ID         uint   `json:"ID"`
+	StatusName string `json:"status_name"`
+}
+
+type PostLocationSummary struct {
+	ID       uint   `json:"ID"`
+	Building string `json:"building"`
+}
+
+type PostOwnerRole struct {
+	Name string `json:"name"`
+}
+
+type PostOwnerSummary struct {
+	ID        uint           `json:"ID"`
+	FirstName string         `json:"first_name"`
+	LastName  string         `json:"last_name"`
+	Role      *PostOwnerRole `json:"role"`
+}
+
+// PostListItem ข้อมูลโพสต์สำหรับหน้ารายการ ไม่มีรายการสมัคร ผลการแข่งขัน หรือห้องแชทซ้อนอยู่
+type PostListItem struct {
+	ID                uint                 `json:"ID"`
+	CreatedAt         time.Time            `json:"CreatedAt"`
+	Title             string               `json:"title"`
+	Detail            string               `json:"detail"`
+	Picture           string               `json:"picture"`
+	Type              string               `json:"type"`
+	Organizer         string               `json:"organizer"`
+	StartDate         time.Time            `json:"start_date"`
+	StopDate          time.Time            `json:"stop_date"`
+	Start             time.Time            `json:"start"`
+	Stop              time.Time            `json:"stop"`
+	Comment           string               `json:"comment"`
+	PostPoint         uint                 `json:"post_point"`
+	UserID            *uint                `json:"user_id"`
+	User              *PostOwnerSummary    `json:"user"`
+	StatusID          *uint                `json:"status_id"`
+	Status            *PostStatusSummary   `json:"status"`
+	LocationID        *uint                `json:"location_id"`
+	Location          *PostLocationSummary `json:"location"`
+	MaxTeams          uint                 `json:"max_teams"`
+	MinTeamSize       uint                 `json:"min_team_size"`
+	MaxTeamSize       uint                 `json:"max_team_size"`
+	MaxSeats          uint                 `json:"max_seats"`
+	Capacity          *entity.PostCapacity `json:"capacity"`
+	RegistrationCount int64                `json:"registration_count"`
+	RegistrationOpen  bool                 `json:"registration_open"`
+}
+
+type PostListPage struct {
+	Data  []PostListItem `json:"data"`
+	Total int64          `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+}
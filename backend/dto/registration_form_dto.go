@@ -0,0 +1,23 @@
+package dto
+
+import "encoding/json"
+
+// RegistrationFormFieldInput คำถามหนึ่งข้อของแบบฟอร์มสมัคร ID = 0 คือคำถามใหม่
+type RegistrationFormFieldInput struct {
+	ID       uint     `json:"id"`
+	Label    string   `json:"label"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options"`
+}
+
+// SaveRegistrationFormRequest แบบฟอร์มทั้งชุด ลำดับใน fields คือลำดับที่แสดง
+type SaveRegistrationFormRequest struct {
+	Fields []RegistrationFormFieldInput `json:"fields"`
+}
+
+// RegistrationAnswerInput คำตอบตามชนิดคำถาม: text/choice/file เป็น string, number เป็นตัวเลข, multi_choice เป็น array ของ string
+type RegistrationAnswerInput struct {
+	FieldID uint            `json:"field_id"`
+	Value   json.RawMessage `json:"value"`
+}
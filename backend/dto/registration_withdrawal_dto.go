@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/sut68/team21/entity"
+)
+
+// WithdrawRegistrationRequest เหตุผลที่ทีมถอนการสมัคร
+type WithdrawRegistrationRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// WithdrawalReport รายงานการถอนการสมัครของกิจกรรมสำหรับผู้จัด
+type WithdrawalReport struct {
+	PostID uint `json:"post_id"`
+	Total  int  `json:"total"`
+	// Late จำนวนทีมที่ถอนหลังกำหนดและถูกหักคะแนน
+	Late int `json:"late"`
+	// PenaltyPoints คะแนนที่หักรวมทุกคน
+	PenaltyPoints int                `json:"penalty_points"`
+	Withdrawals   []WithdrawalRecord `json:"withdrawals"`
+}
+
+type WithdrawalRecord struct {
+	RegistrationID uint      `json:"registration_id"`
+	TeamName       string    `json:"team_name"`
+	Members        []string  `json:"members"`
+	WithdrawnAt    time.Time `json:"withdrawn_at"`
+	Reason         string    `json:"reason"`
+	Penalty        uint      `json:"penalty"`
+}
+
+// NewWithdrawalReport สรุปทีมที่ถอนการสมัคร (ทีมต้อง preload Users แล้ว)
+func NewWithdrawalReport(postID uint, registrations []entity.Registration) WithdrawalReport {
+	report := WithdrawalReport{PostID: postID, Withdrawals: []WithdrawalRecord{}}
+	for _, registration := range registrations {
+		record := WithdrawalRecord{
+			RegistrationID: registration.ID,
+			TeamName:       registration.TeamName,
+			Members:        make([]string, 0, len(registration.Users)),
+			Reason:         registration.WithdrawalReason,
+			Penalty:        registration.WithdrawalPenalty,
+		}
+		if registration.WithdrawnAt != nil {
+			record.WithdrawnAt = *registration.WithdrawnAt
+		}
+		for _, user := range registration.Users {
+			record.Members = append(record.Members, user.SutId)
+		}
+		if registration.WithdrawalPenalty > 0 {
+			report.Late++
+			report.PenaltyPoints += int(registration.WithdrawalPenalty) * len(registration.Users)
+		}
+		report.Withdrawals = append(report.Withdrawals, record)
+	}
+	report.Total = len(report.Withdrawals)
+	return report
+}
@@ -0,0 +1,26 @@
+package dto
+
+// SearchQuery เงื่อนไขของ GET /search: type จำกัดหมวด (posts, users, portfolios คั่นด้วย ,) limit คือจำนวนต่อหมวด
+type SearchQuery struct {
+	Q     string `form:"q"`
+	Type  string `form:"type"`
+	Limit int    `form:"limit"`
+}
+
+type SearchHit struct {
+	ID       uint    `json:"id"`
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle"`
+	Rank     float64 `json:"rank"`
+}
+
+type SearchGroup struct {
+	Type  string      `json:"type"`
+	Total int64       `json:"total"`
+	Items []SearchHit `json:"items"`
+}
+
+type SearchResponse struct {
+	Query  string        `json:"query"`
+	Groups []SearchGroup `json:"groups"`
+}
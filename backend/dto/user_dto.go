@@ -114,3 +114,33 @@ func ToProfileResponse(user *entity.User) *ProfileResponse {
 		Certificates: []CertificateDTO{},   
 	}
 }
+
+type CreateRoleRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type ChangeRoleRequest struct {
+	RoleID uint `json:"role_id" binding:"required"`
+}
+
+type DeactivateUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ImpersonationResponse struct {
+	Token     string `json:"token"`
+	TokenType string `json:"token_type"`
+	ExpiresIn int    `json:"expires_in"`
+	SessionID string `json:"session_id"`
+	UserID    uint   `json:"user_id"`
+	SutId     string `json:"sut_id"`
+	Role      string `json:"role"`
+}
+
+type UpdateRolePermissionsRequest struct {
+	Permissions []string `json:"permissions"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
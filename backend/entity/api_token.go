@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APITokenPrefix นำหน้า personal API token ทุกตัว ใช้แยกจาก JWT ใน AuthMiddleware
+const APITokenPrefix = "ecp_"
+
+// scope ของ personal API token
+const (
+	ScopeProfileRead       = "profile:read"
+	ScopePostsRead         = "posts:read"
+	ScopeRegistrationsRead = "registrations:read"
+	ScopeResultsRead       = "results:read"
+	ScopeResultsWrite      = "results:write"
+)
+
+// APITokenScopes scope ทั้งหมด และสิทธิ์ที่ผู้ใช้ต้องมีก่อนสร้าง token ที่มี scope นั้น ("" = ไม่ต้องมี)
+var APITokenScopes = map[string]string{
+	ScopeProfileRead:       "",
+	ScopePostsRead:         "",
+	ScopeRegistrationsRead: "",
+	ScopeResultsRead:       "",
+	ScopeResultsWrite:      PermResultsManage,
+}
+
+// APIToken personal access token สำหรับสคริปต์/ระบบภายนอก เก็บเฉพาะ hash และแสดง token จริงครั้งเดียวตอนสร้าง
+type APIToken struct {
+	gorm.Model
+	UserID     uint       `gorm:"index;not null" json:"user_id"`
+	User       *User      `gorm:"foreignKey:UserID" json:"-"`
+	Name       string     `gorm:"not null" json:"name"`
+	Prefix     string     `json:"prefix"`
+	TokenHash  string     `gorm:"uniqueIndex;not null" json:"-"`
+	Scopes     string     `gorm:"not null" json:"-"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// ScopeList scope ของ token (เก็บใน DB แบบคั่นด้วย space)
+func (t *APIToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AuditActionAccountLocked   = "auth.account_locked"
+	AuditActionIPLocked        = "auth.ip_locked"
+	AuditActionAccountUnlocked = "auth.account_unlocked"
+
+	AuditActionRoleCreated            = "user.role_created"
+	AuditActionRoleChanged            = "user.role_changed"
+	AuditActionRolePermissionsChanged = "role.permissions_changed"
+	AuditActionUserDeactivated        = "user.deactivated"
+	AuditActionUserReactivated        = "user.reactivated"
+	AuditActionPasswordResetForced    = "user.password_reset_forced"
+	AuditActionImpersonated           = "user.impersonated"
+	AuditActionImpersonatedRequest    = "user.impersonated_request"
+	AuditActionUserAnonymized         = "user.anonymized"
+
+	AuditActionRegistrationStatusChanged = "registration.status_changed"
+	AuditActionRegistrationWithdrawn     = "registration.withdrawn"
+	AuditActionPostPointChanged          = "post.point_changed"
+	AuditActionPointsDistributed         = "points.distributed"
+	AuditActionRewardDeleted             = "reward.deleted"
+	AuditActionResultUpdated             = "result.updated"
+	AuditActionPortfolioReviewed         = "portfolio.reviewed"
+	AuditActionPostReviewed              = "post.reviewed"
+	AuditActionPostFormChanged           = "post.form_changed"
+)
+
+var ErrAuditLogImmutable = errors.New("audit log แก้ไขหรือลบไม่ได้")
+
+// AuditLog บันทึกเหตุการณ์สำคัญแบบ append-only (ไม่มี UpdatedAt/DeletedAt)
+type AuditLog struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+	ActorID    *uint     `gorm:"index" json:"actor_id"`
+	Action     string    `gorm:"index;not null" json:"action"`
+	TargetType string    `gorm:"index" json:"target_type"`
+	TargetID   string    `gorm:"index" json:"target_id"`
+	IPAddress  string    `json:"ip_address"`
+	Detail     string    `json:"detail"`
+	Before     string    `gorm:"type:text" json:"before,omitempty"`
+	After      string    `gorm:"type:text" json:"after,omitempty"`
+}
+
+// BeforeUpdate และ BeforeDelete กันไม่ให้โค้ดส่วนอื่นแก้ไขประวัติที่บันทึกไปแล้ว
+func (AuditLog) BeforeUpdate(*gorm.DB) error { return ErrAuditLogImmutable }
+
+func (AuditLog) BeforeDelete(*gorm.DB) error { return ErrAuditLogImmutable }
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// LoginThrottle นับจำนวนครั้งที่เข้าสู่ระบบผิด แยกตาม key ("account:<sut_id>" หรือ "ip:<address>")
+type LoginThrottle struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	Key          string     `gorm:"uniqueIndex;not null" json:"key"`
+	FailedCount  int        `json:"failed_count"`
+	LastFailedAt time.Time  `json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until"`
+}
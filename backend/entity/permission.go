@@ -0,0 +1,46 @@
+package entity
+
+import "gorm.io/gorm"
+
+// ชื่อสิทธิ์ที่ใช้ตรวจใน routes/controllers (ตรวจด้วยชื่อสิทธิ์ ไม่ใช่ชื่อ role)
+const (
+	PermUsersManage          = "users.manage"
+	PermPostsCreate          = "posts.create"
+	PermActivitiesManageAny  = "activities.manage_any"
+	PermRegistrationsApprove = "registrations.approve"
+	PermCertificatesIssue    = "certificates.issue"
+	PermPointsManage         = "points.manage"
+	PermResultsManage        = "results.manage"
+	PermPortfoliosReview     = "portfolios.review"
+	PermEvaluationsManage    = "evaluations.manage"
+)
+
+// PermissionDescriptions สิทธิ์ทั้งหมดในระบบ ใช้ seed ตาราง permissions
+var PermissionDescriptions = map[string]string{
+	PermUsersManage:          "จัดการบัญชีผู้ใช้ บทบาท และสิทธิ์",
+	PermPostsCreate:          "สร้างโพสต์กิจกรรม",
+	PermActivitiesManageAny:  "จัดการทุกกิจกรรม รวมถึงกิจกรรมที่ผู้อื่นสร้าง",
+	PermRegistrationsApprove: "อนุมัติการลงทะเบียนของกิจกรรมที่ตนเองสร้าง",
+	PermCertificatesIssue:    "ออกเกียรติบัตรของกิจกรรมที่ตนเองสร้าง",
+	PermPointsManage:         "จัดการแต้ม รางวัล และการแจกแต้ม",
+	PermResultsManage:        "บันทึกผลการแข่งขัน",
+	PermPortfoliosReview:     "ตรวจและอนุมัติผลงาน",
+	PermEvaluationsManage:    "จัดการหัวข้อและดูผลการประเมินกิจกรรม",
+}
+
+// DefaultRolePermissions สิทธิ์เริ่มต้นของแต่ละ role ใช้ตอน seed
+// (และเป็นค่าเริ่มต้นก่อนโหลดจาก DB) admin แก้ไขภายหลังได้ผ่าน API
+var DefaultRolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermUsersManage, PermPostsCreate, PermActivitiesManageAny, PermRegistrationsApprove,
+		PermCertificatesIssue, PermPointsManage, PermResultsManage, PermPortfoliosReview, PermEvaluationsManage,
+	},
+	RoleOrganizer: {PermPostsCreate, PermRegistrationsApprove, PermCertificatesIssue},
+	RoleStudent:   {PermPostsCreate},
+}
+
+type Permission struct {
+	gorm.Model
+	Name        string `gorm:"unique;not null" json:"name"`
+	Description string `json:"description"`
+}
@@ -31,5 +31,37 @@ type Post struct {
 	Registrations            []*Registration            `gorm:"foreignKey:PostID" json:"registrations"`
 	ActivityEvaluationTopics []*ActivityEvaluationTopic `gorm:"foreignKey:PostID" json:"activity_evaluation_topics"`
 	Certificates             []*Certificate             `gorm:"foreignKey:PostID" json:"certificates"`
+	FormFields               []*RegistrationFormField   `gorm:"foreignKey:PostID" json:"form_fields,omitempty"`
 	PostPoint                uint                       `gorm:"default:0" json:"post_point"`
-} 
+
+	// ขีดจำกัดการรับสมัคร ค่า 0 คือไม่จำกัด
+	MaxTeams    uint `gorm:"default:0" json:"max_teams"`
+	MinTeamSize uint `gorm:"default:0" json:"min_team_size"`
+	MaxTeamSize uint `gorm:"default:0" json:"max_team_size"`
+	MaxSeats    uint `gorm:"default:0" json:"max_seats"`
+
+	// เงื่อนไขผู้มีสิทธิ์สมัคร ค่าว่างคือไม่จำกัด สมาชิกทุกคนในทีมต้องผ่านทุกเงื่อนไข
+	EligibleFacultyIDs []uint   `gorm:"type:text;serializer:json" json:"eligible_faculty_ids"`
+	EligibleMajorIDs   []uint   `gorm:"type:text;serializer:json" json:"eligible_major_ids"`
+	EligibleYears      []uint   `gorm:"type:text;serializer:json" json:"eligible_years"`
+	EligibleRoles      []string `gorm:"type:text;serializer:json" json:"eligible_roles"`
+
+	// BlockScheduleConflicts ปฏิเสธการสมัครของสมาชิกที่มีกิจกรรมอื่นเวลาซ้อน (ค่าเริ่มต้นแค่เตือน)
+	BlockScheduleConflicts bool `gorm:"default:false" json:"block_schedule_conflicts"`
+
+	// นโยบายถอนการสมัคร: ถอนตัวภายใน WithdrawalCutoffHours ชั่วโมงก่อนปิดรับสมัคร (Stop) หรือหลังจากนั้นถือว่าถอนช้า
+	// และหัก LateWithdrawalPenalty คะแนนจากสมาชิกทุกคน ค่า 0 คือไม่มีบทลงโทษ
+	WithdrawalCutoffHours uint `gorm:"default:0" json:"withdrawal_cutoff_hours"`
+	LateWithdrawalPenalty uint `gorm:"default:0" json:"late_withdrawal_penalty"`
+
+	Capacity *PostCapacity `gorm:"-" json:"capacity,omitempty"`
+}
+
+// PostCapacity จำนวนทีม/ผู้เข้าร่วมที่สมัครแล้ว (ไม่นับทีมที่ถูกปฏิเสธ) และที่ว่างคงเหลือ
+// Remaining* เป็น nil เมื่อกิจกรรมไม่จำกัดจำนวน
+type PostCapacity struct {
+	Teams          int64  `json:"teams"`
+	Seats          int64  `json:"seats"`
+	RemainingTeams *int64 `json:"remaining_teams"`
+	RemainingSeats *int64 `json:"remaining_seats"`
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "gorm.io/gorm"
+
+const (
+	PostReviewSubmitted = "submitted"
+	PostReviewApproved  = "approved"
+	PostReviewRejected  = "rejected"
+)
+
+// PostReview ประวัติการส่งตรวจและผลการตรวจโพสต์ (ส่ง → ตรวจ → อนุมัติ/ไม่อนุมัติ → แก้ไขแล้วส่งใหม่)
+type PostReview struct {
+	gorm.Model
+	PostID  uint   `gorm:"index;not null" json:"post_id"`
+	ActorID uint   `gorm:"not null" json:"actor_id"`
+	Actor   *User  `gorm:"foreignKey:ActorID;constraint:-" json:"actor,omitempty"`
+	Action  string `gorm:"not null" json:"action"`
+	Comment string `json:"comment"`
+}
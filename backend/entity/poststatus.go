@@ -1,9 +1,73 @@
-package entity	
+package entity
 
 import "gorm.io/gorm"
 
+// รหัสสถานะโพสต์ (ตรงกับข้อมูลที่ seed ใน config.SeedPostStatuses)
+const (
+	PostStatusPending  uint = 1
+	PostStatusApproved uint = 2
+	PostStatusRejected uint = 3
+	PostStatusUpcoming uint = 4
+	PostStatusActive   uint = 5
+	PostStatusEnded    uint = 6
+)
+
+var PostStatusNames = map[uint]string{
+	PostStatusPending:  "Pending",
+	PostStatusApproved: "Approved",
+	PostStatusRejected: "Rejected",
+	PostStatusUpcoming: "Upcoming",
+	PostStatusActive:   "Active",
+	PostStatusEnded:    "Ended",
+}
+
+// PostStatusTransitions สถานะถัดไปที่เปลี่ยนไปได้จากแต่ละสถานะ
+// Approved → Upcoming → Active → Ended ขยับตามเวลาโดย scheduler ส่วนที่เหลือเป็นการตรวจ/แก้ไขโพสต์
+var PostStatusTransitions = map[uint][]uint{
+	PostStatusPending:  {PostStatusApproved, PostStatusRejected},
+	PostStatusRejected: {PostStatusPending},
+	PostStatusApproved: {PostStatusUpcoming, PostStatusActive, PostStatusEnded, PostStatusRejected},
+	PostStatusUpcoming: {PostStatusActive, PostStatusEnded},
+	PostStatusActive:   {PostStatusEnded},
+	PostStatusEnded:    {},
+}
+
+// PublishedPostStatuses สถานะของโพสต์ที่ผ่านการอนุมัติแล้ว ผู้ใช้ทุกคนเห็นได้
+var PublishedPostStatuses = []uint{PostStatusApproved, PostStatusUpcoming, PostStatusActive, PostStatusEnded}
+
+// StudentVisiblePostStatuses สถานะที่นักศึกษาเห็นในรายการกิจกรรม
+var StudentVisiblePostStatuses = []uint{PostStatusApproved, PostStatusUpcoming, PostStatusActive}
+
 type PostStatus struct {
 	gorm.Model
 	StatusName string `gorm:"unique" json:"status_name"`
-	Posts []Post `gorm:"foreignKey:StatusID" json:"posts"`
-} 
\ No newline at end of file
+	Posts      []Post `gorm:"foreignKey:StatusID" json:"posts"`
+}
+
+// IsPublishedPostStatus ตรวจว่าโพสต์ผ่านการอนุมัติแล้ว
+func IsPublishedPostStatus(status uint) bool {
+	for _, published := range PublishedPostStatuses {
+		if status == published {
+			return true
+		}
+	}
+	return false
+}
+
+// CanTransitionPostStatus ตรวจว่าเปลี่ยนสถานะโพสต์จาก from ไป to ได้หรือไม่ (สถานะเดิมถือว่าได้เสมอ)
+func CanTransitionPostStatus(from, to uint) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range PostStatusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsScheduledPostStatus สถานะที่ scheduler ขยับตามวันเริ่ม/สิ้นสุดกิจกรรม
+func IsScheduledPostStatus(status uint) bool {
+	return status == PostStatusApproved || status == PostStatusUpcoming || status == PostStatusActive
+}
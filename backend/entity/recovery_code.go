@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCode รหัสสำรองใช้แทน TOTP ได้ครั้งเดียว เมื่อไม่มีมือถือ เก็บเฉพาะ hash
+type RecoveryCode struct {
+	gorm.Model
+	UserID   uint       `gorm:"index;not null" json:"user_id"`
+	User     *User      `gorm:"foreignKey:UserID" json:"-"`
+	CodeHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	UsedAt   *time.Time `json:"used_at"`
+}
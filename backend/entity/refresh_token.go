@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken หนึ่ง SessionID คือหนึ่งอุปกรณ์ที่เข้าสู่ระบบ ทุกครั้งที่ refresh จะสร้างแถวใหม่ใน session เดิม
+type RefreshToken struct {
+	gorm.Model
+	SessionID string     `gorm:"index;not null" json:"session_id"`
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	User      *User      `gorm:"foreignKey:UserID" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	UserAgent string     `json:"user_agent"`
+	IPAddress string     `json:"ip_address"`
+}
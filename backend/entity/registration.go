@@ -6,6 +6,23 @@ import (
 	"github.com/asaskevich/govalidator"
 )
 
+const (
+	RegistrationStatusPending  = "pending"
+	RegistrationStatusApproved = "approved"
+	RegistrationStatusRejected = "rejected"
+	// RegistrationStatusWaitlisted ที่นั่งเต็มตอนสมัคร รอคิวตาม RegistrationDate
+	RegistrationStatusWaitlisted = "waitlisted"
+	// RegistrationStatusOffered ได้ที่นั่งจากคิวสำรอง ต้องยืนยันภายใน OfferExpiresAt
+	RegistrationStatusOffered = "offered"
+	// RegistrationStatusExpired ไม่ยืนยันที่นั่งที่ได้จากคิวสำรองภายในกำหนด
+	RegistrationStatusExpired = "expired"
+	// RegistrationStatusWithdrawn ทีมถอนการสมัครเอง เก็บประวัติไว้ไม่ลบทิ้ง
+	RegistrationStatusWithdrawn = "withdrawn"
+)
+
+// ActiveRegistrationStatuses สถานะที่นับเป็นที่นั่งของกิจกรรม
+var ActiveRegistrationStatuses = []string{RegistrationStatusPending, RegistrationStatusApproved, RegistrationStatusOffered}
+
 type Registration struct {
 	gorm.Model
 	TeamName      string  `json:"team_name" valid:"required~Team name is required,minstringlength(3)~Team name must be at least 3 characters,maxstringlength(100)~Team name must not exceed 100 characters"`
@@ -13,10 +30,21 @@ type Registration struct {
 	Status           string    `json:"status" valid:"required~Status is required"`
 	RegistrationDate time.Time  `json:"registration_date" valid:"required~Registration date is required"`
 	RejectionReason  string    `json:"rejection_reason"`
+	OfferExpiresAt   *time.Time `json:"offer_expires_at"`
+	WithdrawalReason string     `json:"withdrawal_reason"`
+	WithdrawnAt      *time.Time `json:"withdrawn_at"`
+	// WithdrawalPenalty คะแนนที่หักจากสมาชิกแต่ละคนเพราะถอนตัวหลังกำหนด
+	WithdrawalPenalty uint `gorm:"default:0" json:"withdrawal_penalty"`
+	WaitlistPosition int        `gorm:"-" json:"waitlist_position,omitempty"`
+	LeaderID         *uint      `gorm:"-" json:"leader_id"`
+	// Warnings คำเตือนตอนสมัคร เช่น สมาชิกมีกิจกรรมอื่นที่เวลาซ้อน (ไม่บันทึกลงฐานข้อมูล)
+	Warnings         []string   `gorm:"-" json:"warnings,omitempty"`
 	PostID *uint `json:"post_id"`
 	Post   *Post `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE" json:"post,omitempty"`
 	Users	  []*User         `gorm:"many2many:user_registrations;" json:"users"`
 	Results    []*Result       `gorm:"foreignKey:RegistrationID" json:"results"`
+	Answers    []*RegistrationAnswer `gorm:"foreignKey:RegistrationID;constraint:OnDelete:CASCADE" json:"answers"`
+	StatusHistory []*RegistrationStatusHistory `gorm:"foreignKey:RegistrationID;constraint:OnDelete:CASCADE" json:"status_history,omitempty"`
 	PointRecords []*PointRecord `gorm:"foreignKey:RegistrationID" json:"point_records"`
 	Certificates  []*Certificate `gorm:"foreignKey:RegistrationID" json:"certificates"`
 	ActivityEvaluationRespones []*ActivityEvaluationRespone `gorm:"foreignKey:RegistrationID" json:"activity_evaluation_responses"`
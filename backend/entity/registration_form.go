@@ -0,0 +1,36 @@
+package entity
+
+import "gorm.io/gorm"
+
+const (
+	FormFieldText        = "text"
+	FormFieldNumber      = "number"
+	FormFieldChoice      = "choice"
+	FormFieldMultiChoice = "multi_choice"
+	FormFieldFile        = "file"
+)
+
+// FormFieldTypes ชนิดของคำถามในแบบฟอร์มสมัคร
+var FormFieldTypes = []string{FormFieldText, FormFieldNumber, FormFieldChoice, FormFieldMultiChoice, FormFieldFile}
+
+// RegistrationFormField คำถามเพิ่มเติมตอนสมัครของแต่ละกิจกรรม เช่น ไซซ์เสื้อ อาหารที่แพ้ ลิงก์ GitHub
+// Options ใช้กับ choice/multi_choice เท่านั้น
+type RegistrationFormField struct {
+	gorm.Model
+	PostID   uint     `gorm:"index;not null" json:"post_id"`
+	Label    string   `gorm:"not null" json:"label"`
+	Type     string   `gorm:"not null" json:"type"`
+	Required bool     `json:"required"`
+	Options  []string `gorm:"type:text;serializer:json" json:"options"`
+	Position int      `json:"position"`
+}
+
+// RegistrationAnswer คำตอบของทีมต่อคำถามหนึ่งข้อ
+// Value เป็นข้อความ ตัวเลข ตัวเลือก หรือ path ไฟล์ ส่วน multi_choice เก็บเป็น JSON array
+type RegistrationAnswer struct {
+	gorm.Model
+	RegistrationID uint                   `gorm:"uniqueIndex:idx_registration_answer_field;not null" json:"registration_id"`
+	FieldID        uint                   `gorm:"uniqueIndex:idx_registration_answer_field;not null" json:"field_id"`
+	Field          *RegistrationFormField `gorm:"foreignKey:FieldID;constraint:-" json:"field,omitempty"`
+	Value          string                 `gorm:"type:text" json:"value"`
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// RegistrationStatusHistory ประวัติการเปลี่ยนสถานะของทีม หนึ่งแถวต่อการเปลี่ยนหนึ่งครั้ง (append-only)
+type RegistrationStatusHistory struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time `gorm:"index" json:"created_at"`
+	RegistrationID uint      `gorm:"index;not null" json:"registration_id"`
+	// FromStatus ว่างเมื่อเป็นสถานะแรกตอนสร้างทีม
+	FromStatus string `json:"from_status"`
+	ToStatus   string `gorm:"not null" json:"to_status"`
+	Reason     string `json:"reason"`
+	// ActorID ผู้เปลี่ยนสถานะ เป็น nil เมื่อระบบเปลี่ยนเอง (คิวสำรอง หมดเวลายืนยันที่นั่ง)
+	ActorID *uint `gorm:"index" json:"actor_id"`
+	Actor   *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+	// NotifiedAt เวลาที่แจ้งสมาชิกทีมแล้ว nil คือยังไม่แจ้ง
+	NotifiedAt *time.Time `gorm:"index" json:"-"`
+}
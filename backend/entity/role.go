@@ -4,8 +4,15 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleAdmin     = "admin"
+	RoleStudent   = "student"
+	RoleOrganizer = "organizer"
+)
+
 type Role struct {
 	gorm.Model
-	Name  string `gorm:"unique" json:"name"`
-	Users []User `gorm:"foreignKey:RoleID"`
+	Name        string       `gorm:"unique" json:"name"`
+	Users       []User       `gorm:"foreignKey:RoleID"`
+	Permissions []Permission `gorm:"many2many:role_permissions;" json:"permissions,omitempty"`
 }
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	InvitationStatusPending   = "pending"
+	InvitationStatusAccepted  = "accepted"
+	InvitationStatusDeclined  = "declined"
+	InvitationStatusExpired   = "expired"
+	InvitationStatusCancelled = "cancelled"
+)
+
+// TeamInvitation คำเชิญเข้าทีม ผู้ได้รับเชิญเป็นสมาชิกของทีมเมื่อตอบรับแล้วเท่านั้น
+// คำเชิญที่รอตอบจองที่ในทีมไว้ (นับรวมกับขนาดทีมสูงสุด) แต่ยังไม่นับเป็นที่นั่งของกิจกรรม
+type TeamInvitation struct {
+	gorm.Model
+	RegistrationID uint          `gorm:"index;not null" json:"registration_id"`
+	Registration   *Registration `gorm:"foreignKey:RegistrationID;constraint:OnDelete:CASCADE" json:"registration,omitempty"`
+	InviteeID      uint          `gorm:"index;not null" json:"invitee_id"`
+	Invitee        *User         `gorm:"foreignKey:InviteeID;constraint:-" json:"invitee,omitempty"`
+	InviterID      uint          `gorm:"not null" json:"inviter_id"`
+	Inviter        *User         `gorm:"foreignKey:InviterID;constraint:-" json:"inviter,omitempty"`
+	Status         string        `gorm:"not null;default:pending" json:"status"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	RespondedAt    *time.Time    `json:"responded_at"`
+	Warnings       []string      `gorm:"-" json:"warnings,omitempty"`
+}
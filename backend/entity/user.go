@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -23,6 +25,20 @@ type User struct {
 	AvatarURL string     `json:"avatar_url"`
 	UserPoint *UserPoint `gorm:"foreignKey:UserID" json:"user_point,omitempty"`
 
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+
+	// บัญชีที่ถูกระงับเข้าสู่ระบบไม่ได้ และ token เดิมใช้ไม่ได้ทันที
+	DeactivatedAt *time.Time `json:"deactivated_at"`
+	// ผู้ดูแลระบบบังคับให้ตั้งรหัสผ่านใหม่ก่อนเข้าสู่ระบบครั้งถัดไป
+	PasswordResetRequired bool `json:"password_reset_required"`
+	// ลบบัญชีตาม PDPA แล้ว: ข้อมูลส่วนตัวถูกลบ แถวนี้เหลือไว้เป็นนามแฝงของข้อความ/การประเมินเดิม
+	AnonymizedAt *time.Time `json:"anonymized_at"`
+
+	// TOTP 2FA: TOTPSecret ถูกตั้งตอนเริ่มลงทะเบียน แต่จะใช้งานจริงเมื่อ TOTPEnabledAt ไม่เป็น nil
+	TOTPSecret    string     `json:"-"`
+	TOTPEnabledAt *time.Time `json:"totp_enabled_at"`
+	TOTPLastStep  int64      `json:"-"`
+
 	RewardRedeems             []*RewardRedeem              `gorm:"foreignKey:UserID" json:"reward_redeems"`
 	PointRecords              []*PointRecord               `gorm:"foreignKey:UserID" json:"point_records"`
 	Certificates              []*Certificate               `gorm:"foreignKey:UserID" json:"certificates"`
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentity เชื่อมบัญชีผู้ใช้กับบัญชีจาก OpenID Connect provider (issuer + sub)
+type UserIdentity struct {
+	gorm.Model
+	UserID  uint   `gorm:"index;not null" json:"user_id"`
+	User    *User  `gorm:"foreignKey:UserID" json:"-"`
+	Issuer  string `gorm:"uniqueIndex:idx_identity_issuer_subject;not null" json:"issuer"`
+	Subject string `gorm:"uniqueIndex:idx_identity_issuer_subject;not null" json:"subject"`
+	Email   string `json:"email"`
+}
+
+// OIDCLoginState เก็บ state, nonce และ PKCE verifier ระหว่าง redirect ไป provider ใช้ได้ครั้งเดียว
+type OIDCLoginState struct {
+	gorm.Model
+	StateHash    string    `gorm:"uniqueIndex;not null" json:"-"`
+	Nonce        string    `gorm:"not null" json:"-"`
+	CodeVerifier string    `gorm:"not null" json:"-"`
+	ExpiresAt    time.Time `gorm:"not null" json:"expires_at"`
+}
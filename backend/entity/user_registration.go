@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+const (
+	TeamRoleLeader = "leader"
+	TeamRoleMember = "member"
+)
+
+// UserRegistration ตารางเชื่อมสมาชิกกับทีม (user_registrations) พร้อมบทบาทในทีม
+// แต่ละทีมมีหัวหน้าทีม (leader) หนึ่งคน ที่เหลือเป็น member
+type UserRegistration struct {
+	UserID         uint      `gorm:"primaryKey" json:"user_id"`
+	RegistrationID uint      `gorm:"primaryKey" json:"registration_id"`
+	Role           string    `gorm:"not null;default:member" json:"role"`
+	CreatedAt      time.Time `json:"joined_at"`
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/config"
@@ -13,13 +16,25 @@ import (
 func main() {
 
 	config.LoadEnv()
+	config.LoadKeyRing()
+	go reloadKeysOnSignal()
 	config.ConnectDatabase()
 	config.SeedAllData()
+	services.LoadRevokedSessions(config.DB)
+	services.LoadDeactivatedUsers(config.DB)
+	services.LoadRolePermissions(config.DB)
 
 	chatHub := services.NewChatHub()
 	go chatHub.Run()
+	go services.RunPostScheduler(config.DB, services.PostSchedulerInterval)
+	go services.RunWaitlistScheduler(config.DB, services.WaitlistSchedulerInterval)
+	go services.RunInvitationScheduler(config.DB, services.InvitationSchedulerInterval)
+	go services.RunStatusNotificationScheduler(config.DB, services.NewMailer(), services.StatusNotificationInterval)
 
 	r := gin.Default()
+	if err := r.SetTrustedProxies(config.Env.TrustedProxies); err != nil {
+		fmt.Println("Invalid TRUSTED_PROXIES:", err)
+	}
 
 	r.Use(middleware.CORSMiddleware())
 	api := r.Group("/api")
@@ -36,8 +51,24 @@ func main() {
 		routes.ResultsRoutes(api, config.DB)
 		routes.RegistrationRoutes(api)
 		routes.EvaluationRoutes(api)
+		routes.APITokenRoutes(api)
+		routes.AuditRoutes(api)
+		routes.SearchRoutes(api)
 	}
 
 	fmt.Println(" Server running on port:", config.Env.BackendPort)
 	r.Run(":" + config.Env.BackendPort)
 }
+
+// ส่ง SIGHUP เพื่อโหลด JWT key ใหม่โดยไม่ต้องรีสตาร์ท (ใช้ตอนหมุนเวียน key)
+func reloadKeysOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		if err := config.ReloadKeyRing(); err != nil {
+			fmt.Println("Reload JWT keys failed:", err)
+			continue
+		}
+		fmt.Println("JWT keys reloaded")
+	}
+}
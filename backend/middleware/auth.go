@@ -2,10 +2,13 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
 )
 
 func AuthMiddleware() gin.HandlerFunc {
@@ -21,6 +24,8 @@ func AuthMiddleware() gin.HandlerFunc {
 			}
 		}
 
+		fromHeader := tokenString != ""
+
 		// 2. ถ้าไม่มีใน Header ให้ลองหาใน Query Parameter (สำหรับ WebSocket)
 		if tokenString == "" {
 			tokenString = ctx.Query("token")
@@ -33,6 +38,17 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// personal API token ส่งได้เฉพาะใน header เพื่อไม่ให้ติดอยู่ใน access log
+		if services.IsAPIToken(tokenString) {
+			if !fromHeader {
+				ctx.JSON(http.StatusUnauthorized, gin.H{"error": "API token ต้องส่งใน Authorization header เท่านั้น"})
+				ctx.Abort()
+				return
+			}
+			authenticateAPIToken(ctx, tokenString)
+			return
+		}
+
 		// 4. Validate JWT
 		claims, err := config.ValidateJWT(tokenString)
 		if err != nil {
@@ -41,10 +57,40 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 5. Set ค่าลง Context
+		// 5. ตรวจว่า session ยังไม่ถูก logout / เพิกถอน (รวมถึง WebSocket ที่ส่ง ?token=)
+		if claims.SessionID == "" || services.IsSessionRevoked(claims.SessionID) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Session ถูกยกเลิกแล้ว กรุณาเข้าสู่ระบบใหม่"})
+			ctx.Abort()
+			return
+		}
+
+		// 6. บัญชีที่ถูกระงับใช้ token ใดๆ ไม่ได้ รวมถึง token สวมสิทธิ์
+		if services.IsUserDeactivated(claims.UserID) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": services.ErrAccountDeactivated.Error()})
+			ctx.Abort()
+			return
+		}
+
+		// 7. Set ค่าลง Context
 		ctx.Set("user_id", claims.UserID)
 		ctx.Set("sut_id", claims.SutId)
 		ctx.Set("role", claims.Role)
+		ctx.Set("session_id", claims.SessionID)
+		if claims.ImpersonatorID != 0 {
+			ctx.Set("impersonator_id", claims.ImpersonatorID)
+			// ทุก request ที่แก้ไขข้อมูลระหว่างสวมสิทธิ์ถูกบันทึกใน audit log ในนามของ admin ผู้สวมสิทธิ์
+			if ctx.Request.Method != http.MethodGet {
+				impersonatorID := claims.ImpersonatorID
+				services.RecordAudit(config.DB, entity.AuditLog{
+					ActorID:    &impersonatorID,
+					Action:     entity.AuditActionImpersonatedRequest,
+					TargetType: "user",
+					TargetID:   strconv.Itoa(int(claims.UserID)),
+					IPAddress:  ctx.ClientIP(),
+					Detail:     ctx.Request.Method + " " + ctx.Request.URL.Path,
+				})
+			}
+		}
 
 		ctx.Next()
 	}
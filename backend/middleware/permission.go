@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/services"
+)
+
+// RequirePermission อนุญาตเฉพาะผู้ใช้ที่ role มีสิทธิ์ตามชื่อที่กำหนด ต้องใช้ต่อจาก AuthMiddleware
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !HasPermission(ctx, permission) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "คุณไม่มีสิทธิ์เข้าถึงข้อมูลนี้"})
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequireSelfOrPermission อนุญาตเมื่อ path param เป็น id ของผู้ใช้เอง หรือผู้ใช้มีสิทธิ์ที่กำหนด
+func RequireSelfOrPermission(param, permission string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if HasPermission(ctx, permission) {
+			ctx.Next()
+			return
+		}
+
+		targetID, err := strconv.ParseUint(ctx.Param(param), 10, 64)
+		if err != nil || uint(targetID) != CurrentUserID(ctx) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "คุณไม่มีสิทธิ์เข้าถึงข้อมูลของผู้ใช้อื่น"})
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// HasPermission ตรวจสิทธิ์ของ role ที่ AuthMiddleware ใส่ไว้ใน context
+func HasPermission(ctx *gin.Context, permission string) bool {
+	role := ctx.GetString("role")
+	return role != "" && services.RoleHasPermission(role, permission)
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole อนุญาตเฉพาะผู้ใช้ที่มี role ตรงกับที่กำหนด ต้องใช้ต่อจาก AuthMiddleware
+// routes ของระบบใช้ RequirePermission แทน เพื่อให้ปรับสิทธิ์ของ role ได้โดยไม่ต้องแก้โค้ด
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !HasRole(ctx, roles...) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "คุณไม่มีสิทธิ์เข้าถึงข้อมูลนี้"})
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// RequireSelfOrRole อนุญาตเมื่อ path param เป็น id ของผู้ใช้เอง หรือผู้ใช้มี role ที่กำหนด
+// เช่น /points/total/:userId ให้นักศึกษาดูได้เฉพาะของตัวเอง แต่ admin ดูได้ทุกคน
+func RequireSelfOrRole(param string, roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if HasRole(ctx, roles...) {
+			ctx.Next()
+			return
+		}
+
+		targetID, err := strconv.ParseUint(ctx.Param(param), 10, 64)
+		if err != nil || uint(targetID) != CurrentUserID(ctx) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "คุณไม่มีสิทธิ์เข้าถึงข้อมูลของผู้ใช้อื่น"})
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// HasRole ตรวจสอบว่า role ที่ AuthMiddleware ใส่ไว้ใน context ตรงกับรายการที่กำหนดหรือไม่
+func HasRole(ctx *gin.Context, roles ...string) bool {
+	role := ctx.GetString("role")
+	if role == "" {
+		return false
+	}
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentUserID คืนค่า user_id ของผู้ใช้ที่เข้าสู่ระบบ (0 ถ้าไม่มี)
+func CurrentUserID(ctx *gin.Context) uint {
+	val, exists := ctx.Get("user_id")
+	if !exists {
+		return 0
+	}
+	switch v := val.(type) {
+	case uint:
+		return v
+	case float64:
+		return uint(v)
+	case int:
+		return uint(v)
+	case string:
+		if id, err := strconv.ParseUint(v, 10, 32); err == nil {
+			return uint(id)
+		}
+	}
+	return 0
+}
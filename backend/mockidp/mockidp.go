@@ -0,0 +1,228 @@
+// Package mockidp เป็น OpenID Connect provider จำลองสำหรับ dev และเทสต์
+// รองรับ authorization code + PKCE (S256) ให้ผู้ใช้กรอกรหัสนักศึกษาเองโดยไม่ตรวจรหัสผ่าน
+// ห้ามเปิดใช้ใน production
+package mockidp
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	jwt "github.com/golang-jwt/jwt/v5"
+	"github.com/sut68/team21/config"
+)
+
+const codeTTL = 2 * time.Minute
+
+type authCode struct {
+	clientID      string
+	redirectURI   string
+	codeChallenge string
+	nonce         string
+	sutId         string
+	firstName     string
+	lastName      string
+	expiresAt     time.Time
+}
+
+// Server คือ IdP จำลอง ออก id_token ที่เซ็นด้วย RSA key ที่สร้างตอนเริ่มทำงาน
+type Server struct {
+	issuer   string
+	clientID string
+	keys     *config.KeyRing
+
+	mu    sync.Mutex
+	codes map[string]authCode
+}
+
+// New สร้าง IdP จำลอง issuer ต้องเป็น URL ที่ใช้เข้าถึง routes ที่ Register ไว้
+func New(issuer, clientID string) (*Server, error) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := config.NewSigningKeyFromPEM("mock-idp", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		issuer:   strings.TrimRight(issuer, "/"),
+		clientID: clientID,
+		keys:     config.NewKeyRingFromKeys(key),
+		codes:    map[string]authCode{},
+	}, nil
+}
+
+// Register ผูก endpoint ของ IdP เข้ากับ router group (path ของ group ต้องตรงกับ issuer)
+func (s *Server) Register(rg gin.IRoutes) {
+	rg.GET("/.well-known/openid-configuration", s.discovery)
+	rg.GET("/jwks", s.jwks)
+	rg.GET("/authorize", s.authorize)
+	rg.POST("/authorize", s.authorize)
+	rg.POST("/token", s.token)
+}
+
+func (s *Server) discovery(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"issuer":                                s.issuer,
+		"authorization_endpoint":                s.issuer + "/authorize",
+		"token_endpoint":                        s.issuer + "/token",
+		"jwks_uri":                              s.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"scopes_supported":                      []string{"openid", "email", "profile"},
+	})
+}
+
+func (s *Server) jwks(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, s.keys.JWKS())
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!doctype html>
+<html lang="th"><head><meta charset="utf-8"><title>Mock SUT SSO</title></head>
+<body style="font-family:sans-serif;max-width:360px;margin:60px auto">
+<h2>Mock SUT SSO</h2>
+<p>IdP จำลองสำหรับพัฒนาระบบ ไม่มีการตรวจรหัสผ่าน</p>
+<form method="post">
+{{range $k, $v := .Params}}<input type="hidden" name="{{$k}}" value="{{$v}}">{{end}}
+<p><label>รหัสนักศึกษา<br><input name="login_hint" placeholder="B6614690" required></label></p>
+<p><label>ชื่อ<br><input name="given_name"></label></p>
+<p><label>นามสกุล<br><input name="family_name"></label></p>
+<button type="submit">เข้าสู่ระบบ</button>
+</form></body></html>`))
+
+// authorize ถ้ามี login_hint จะออก code ทันที ไม่เช่นนั้นแสดงฟอร์มให้กรอกรหัสนักศึกษา
+func (s *Server) authorize(ctx *gin.Context) {
+	param := func(key string) string {
+		if v := ctx.PostForm(key); v != "" {
+			return v
+		}
+		return ctx.Query(key)
+	}
+
+	clientID := param("client_id")
+	redirectURI := param("redirect_uri")
+	if clientID != s.clientID || redirectURI == "" {
+		ctx.String(http.StatusBadRequest, "invalid client_id or redirect_uri")
+		return
+	}
+	if param("response_type") != "code" || param("code_challenge_method") != "S256" || param("code_challenge") == "" {
+		ctx.String(http.StatusBadRequest, "only response_type=code with PKCE S256 is supported")
+		return
+	}
+
+	loginHint := strings.TrimSpace(param("login_hint"))
+	if loginHint == "" {
+		params := map[string]string{}
+		for _, key := range []string{"client_id", "redirect_uri", "response_type", "scope", "state", "nonce", "code_challenge", "code_challenge_method"} {
+			params[key] = param(key)
+		}
+		ctx.Header("Content-Type", "text/html; charset=utf-8")
+		loginPage.Execute(ctx.Writer, gin.H{"Params": params})
+		return
+	}
+
+	code, err := config.GenerateSecureToken(24)
+	if err != nil {
+		ctx.String(http.StatusInternalServerError, "cannot create code")
+		return
+	}
+
+	s.mu.Lock()
+	s.codes[code] = authCode{
+		clientID:      clientID,
+		redirectURI:   redirectURI,
+		codeChallenge: param("code_challenge"),
+		nonce:         param("nonce"),
+		sutId:         strings.ToUpper(loginHint),
+		firstName:     param("given_name"),
+		lastName:      param("family_name"),
+		expiresAt:     time.Now().Add(codeTTL),
+	}
+	s.mu.Unlock()
+
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, "invalid redirect_uri")
+		return
+	}
+	query := target.Query()
+	query.Set("code", code)
+	query.Set("state", param("state"))
+	target.RawQuery = query.Encode()
+	ctx.Redirect(http.StatusFound, target.String())
+}
+
+func (s *Server) token(ctx *gin.Context) {
+	clientID, _, ok := ctx.Request.BasicAuth()
+	if !ok {
+		clientID = ctx.PostForm("client_id")
+	}
+
+	code := ctx.PostForm("code")
+	s.mu.Lock()
+	issued, found := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+
+	if ctx.PostForm("grant_type") != "authorization_code" || !found || time.Now().After(issued.expiresAt) ||
+		issued.clientID != clientID || issued.redirectURI != ctx.PostForm("redirect_uri") {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant"})
+		return
+	}
+
+	sum := sha256.Sum256([]byte(ctx.PostForm("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != issued.codeChallenge {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	now := time.Now()
+	email := strings.ToLower(issued.sutId) + "@g.sut.ac.th"
+	claims := jwt.MapClaims{
+		"iss":            s.issuer,
+		"sub":            "mock|" + strings.ToLower(issued.sutId),
+		"aud":            clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"email":          email,
+		"email_verified": true,
+		"given_name":     issued.firstName,
+		"family_name":    issued.lastName,
+		"name":           strings.TrimSpace(issued.firstName + " " + issued.lastName),
+	}
+	if issued.nonce != "" {
+		claims["nonce"] = issued.nonce
+	}
+
+	idToken, err := s.keys.Sign(claims)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+	accessToken, _ := config.GenerateSecureToken(24)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
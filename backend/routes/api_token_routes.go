@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+func APITokenRoutes(rg *gin.RouterGroup) {
+	apiTokenController := controller.NewAPITokenController(services.NewAPITokenService(config.DB))
+
+	// จัดการ token ได้เฉพาะเมื่อเข้าสู่ระบบด้วย JWT (API token สร้าง token เพิ่มเองไม่ได้)
+	tokens := rg.Group("/api-tokens")
+	tokens.Use(middleware.AuthMiddleware())
+	{
+		tokens.GET("", apiTokenController.List)
+		tokens.POST("", apiTokenController.Create)
+		tokens.DELETE("/:id", apiTokenController.Revoke)
+	}
+}
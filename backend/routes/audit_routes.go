@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+func AuditRoutes(rg *gin.RouterGroup) {
+	auditController := controller.NewAuditController(services.NewAuditService(config.DB))
+
+	audit := rg.Group("/audit-logs")
+	audit.Use(middleware.AuthMiddleware(), middleware.RequirePermission(entity.PermUsersManage))
+	{
+		audit.GET("", auditController.List)
+		audit.GET("/export", auditController.Export)
+	}
+}
@@ -1,19 +1,68 @@
 package routes
 
 import (
+	"log"
+
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/config"
 	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/mockidp"
 	"github.com/sut68/team21/services"
 )
 
 func AuthRoutes(rg *gin.RouterGroup) {
-	authService := services.NewAuthService(config.DB)
-	authController := controller.NewAuthController(authService)
+	sessionService := services.NewSessionService(config.DB)
+	accountService := services.NewAccountService(config.DB, services.NewMailer(), sessionService)
+	loginGuard := services.NewLoginGuard(config.DB)
+	mfaService := services.NewMFAService(config.DB, sessionService, loginGuard)
+	authService := services.NewAuthService(config.DB, accountService, loginGuard, mfaService)
+	authController := controller.NewAuthController(authService, sessionService, accountService, loginGuard)
+	mfaController := controller.NewMFAController(mfaService)
+
+	rg.GET("/.well-known/jwks.json", authController.JWKS)
+
+	oidcConfig := services.OIDCConfigFromEnv()
+	oidcController := controller.NewOIDCController(services.NewOIDCService(config.DB, mfaService, oidcConfig))
+	if config.Env.OIDCMock && oidcConfig.Issuer == services.MockIdPIssuer() {
+		mock, err := mockidp.New(oidcConfig.Issuer, oidcConfig.ClientID)
+		if err != nil {
+			log.Fatalf("Error starting mock IdP: %v", err)
+		}
+		mock.Register(rg.Group("/mock-idp"))
+		log.Println("OIDC mock IdP enabled at", oidcConfig.Issuer)
+	}
 
 	authRoutes := rg.Group("/auth")
 	{
 		authRoutes.POST("/register", authController.Register)
 		authRoutes.POST("/login", authController.Login)
+		authRoutes.POST("/refresh", authController.Refresh)
+		authRoutes.POST("/logout", authController.Logout)
+		authRoutes.POST("/logout-all", middleware.AuthMiddleware(), authController.LogoutAll)
+		authRoutes.GET("/verify-email", authController.VerifyEmail)
+		authRoutes.POST("/verify-email", authController.VerifyEmail)
+		authRoutes.POST("/resend-verification", authController.ResendVerification)
+		authRoutes.POST("/forgot-password", authController.ForgotPassword)
+		authRoutes.POST("/reset-password", authController.ResetPassword)
+		authRoutes.GET("/oidc/config", oidcController.Config)
+		authRoutes.GET("/oidc/login", oidcController.Login)
+		authRoutes.GET("/oidc/callback", oidcController.Callback)
+		authRoutes.POST("/2fa/verify", mfaController.Verify)
+		authRoutes.POST("/2fa/challenge/setup", mfaController.ChallengeSetup)
+		authRoutes.POST("/2fa/challenge/enable", mfaController.ChallengeEnable)
+		authRoutes.GET("/lockouts", middleware.AuthMiddleware(), middleware.RequirePermission(entity.PermUsersManage), authController.ListLockouts)
+		authRoutes.POST("/unlock/:sutId", middleware.AuthMiddleware(), middleware.RequirePermission(entity.PermUsersManage), authController.UnlockAccount)
+	}
+
+	mfaRoutes := rg.Group("/auth/2fa")
+	mfaRoutes.Use(middleware.AuthMiddleware())
+	{
+		mfaRoutes.GET("/status", mfaController.Status)
+		mfaRoutes.POST("/setup", mfaController.Setup)
+		mfaRoutes.POST("/enable", mfaController.Enable)
+		mfaRoutes.POST("/disable", mfaController.Disable)
+		mfaRoutes.POST("/recovery-codes", mfaController.RegenerateRecoveryCodes)
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/config"
 	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/entity"
 	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
@@ -12,17 +13,18 @@ func CertificateRoutes(rg *gin.RouterGroup) {
 	certificateService := services.NewCertificateService(config.DB)
 	certificateController := controller.NewCertificateController(certificateService)
 
+	canIssue := middleware.RequirePermission(entity.PermCertificatesIssue)
+
 	cert := rg.Group("/certificate")
 	cert.Use(middleware.AuthMiddleware())
 	{
-		cert.POST("", certificateController.CreateCertificate)
+		cert.POST("", canIssue, certificateController.CreateCertificate)
 		cert.GET("", certificateController.GetAllCertificate)
 		cert.GET("/my", certificateController.GetMyCertificates)
 		cert.GET("/:id", certificateController.GetCertificateByID)
-		cert.PUT("/:id", certificateController.UpdateCertificate)
+		cert.PUT("/:id", canIssue, certificateController.UpdateCertificate)
 		cert.GET("/post/:id", certificateController.GetCertificateByPostID)
-		cert.DELETE("/:id", certificateController.DeleteCertificate)
+		cert.DELETE("/:id", canIssue, certificateController.DeleteCertificate)
 	}
 
 }
-
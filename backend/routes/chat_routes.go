@@ -12,11 +12,12 @@ func ChatRoutes(r *gin.RouterGroup, db *gorm.DB, hub *services.ChatHub) {
 	chatController := controller.NewChatController(db, hub)
 
 	chat := r.Group("/chat")
+	chat.Use(middleware.AuthMiddleware())
 	{
 		chat.GET("/history/:post_id", chatController.GetHistory)
-		chat.GET("/ws/lobby/:post_id", middleware.AuthMiddleware(), chatController.JoinChatLobby)
+		chat.GET("/ws/lobby/:post_id", chatController.JoinChatLobby)
 		chat.POST("/upload", chatController.UploadChatImage)
 		chat.POST("/upload/file", chatController.UploadFile)
-		chat.DELETE("/message/:message_id", middleware.AuthMiddleware(), chatController.DeleteMessage)
+		chat.DELETE("/message/:message_id", chatController.DeleteMessage)
 	}
 }
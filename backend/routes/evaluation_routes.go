@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/config"
 	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/entity"
 	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
@@ -11,16 +12,19 @@ import (
 func EvaluationRoutes(router *gin.RouterGroup) {
 	evaluationService := services.NewEvaluationService(config.DB)
 	evaluationController := controller.NewEvaluationController(evaluationService)
+
+	canManage := middleware.RequirePermission(entity.PermEvaluationsManage)
+
 	evaluation := router.Group("/evaluation")
 	evaluation.Use(middleware.AuthMiddleware())
 	{
-		evaluation.POST("/topics", evaluationController.CreateTopics)
+		evaluation.POST("/topics", canManage, evaluationController.CreateTopics)
 		evaluation.GET("/topics/:postId", evaluationController.GetTopicsByPost)
-		evaluation.PUT("/topics/:id", evaluationController.UpdateTopic)
-		evaluation.DELETE("/topics/:id", evaluationController.DeleteTopic)
+		evaluation.PUT("/topics/:id", canManage, evaluationController.UpdateTopic)
+		evaluation.DELETE("/topics/:id", canManage, evaluationController.DeleteTopic)
 		evaluation.POST("/submit", evaluationController.SubmitEvaluation)
 		evaluation.GET("/activity", evaluationController.GetMyRegisteredPosts)
-		evaluation.GET("/summary", evaluationController.GetPostsWithEvaluations)
-		evaluation.GET("/results/:postId", evaluationController.GetEvaluationResults)
+		evaluation.GET("/summary", canManage, evaluationController.GetPostsWithEvaluations)
+		evaluation.GET("/results/:postId", canManage, evaluationController.GetEvaluationResults)
 	}
 }
@@ -4,6 +4,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/config"
 	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
 
@@ -11,22 +13,26 @@ func PointRoutes(r *gin.RouterGroup) {
 	pointService := services.NewPointService(config.DB)
 	pointController := controller.NewPointController(pointService)
 
+	canManage := middleware.RequirePermission(entity.PermPointsManage)
+	selfOrManager := middleware.RequireSelfOrPermission("userId", entity.PermPointsManage)
+
 	points := r.Group("/points")
+	points.Use(middleware.AuthMiddleware())
 	{
-		points.GET("/total/:userId", pointController.GetTotalPoint)
-		points.POST("/checkin/:userId", pointController.DailyCheckin)
-		points.GET("/membership/:userId", pointController.GetMembershipLevel)
-		points.GET("/records/:userId", pointController.GetPointRecords)
-		points.POST("/rewards", pointController.CreateReward)
+		points.GET("/total/:userId", selfOrManager, pointController.GetTotalPoint)
+		points.POST("/checkin/:userId", selfOrManager, pointController.DailyCheckin)
+		points.GET("/membership/:userId", selfOrManager, pointController.GetMembershipLevel)
+		points.GET("/records/:userId", selfOrManager, pointController.GetPointRecords)
+		points.POST("/rewards", canManage, pointController.CreateReward)
 		points.GET("/rewards", pointController.GetRewards)
-		points.PUT("/rewards/:id", pointController.UpdateReward)
-		points.GET("/pending_posts", pointController.GetPendingPosts)
-		points.GET("/posts_with_points", pointController.GetPostsWithPoints)
-		points.PUT("/post_point/:postId", pointController.UpdatePostPoint)
+		points.PUT("/rewards/:id", canManage, pointController.UpdateReward)
+		points.GET("/pending_posts", canManage, pointController.GetPendingPosts)
+		points.GET("/posts_with_points", canManage, pointController.GetPostsWithPoints)
+		points.PUT("/post_point/:postId", canManage, pointController.UpdatePostPoint)
 		points.POST("/reward_redeem", pointController.RedeemReward)
-		points.GET("/redeemed/:userId", pointController.GetRedeemedRewards)
-		points.DELETE("/rewards/:id", pointController.DeleteReward)
-		points.POST("/distribute/:postId", pointController.DistributePoints)
-		points.GET("/distributed/:postId", pointController.CheckPointsDistributed)
+		points.GET("/redeemed/:userId", selfOrManager, pointController.GetRedeemedRewards)
+		points.DELETE("/rewards/:id", canManage, pointController.DeleteReward)
+		points.POST("/distribute/:postId", canManage, pointController.DistributePoints)
+		points.GET("/distributed/:postId", canManage, pointController.CheckPointsDistributed)
 	}
-}
\ No newline at end of file
+}
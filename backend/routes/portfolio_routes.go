@@ -4,6 +4,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/config"
 	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
 
@@ -12,23 +14,27 @@ func PortfolioRoutes(rg *gin.RouterGroup) {
 	portfolioService := services.NewPortfolioService(config.DB)
 	portfolioController := controller.NewPortfolioController(portfolioService)
 
+	canReview := middleware.RequirePermission(entity.PermPortfoliosReview)
+
 	// 2. Define Routes
 
 	// Group สำหรับจัดการตัว Portfolio หลัก
+	// แก้ไข/ลบ ได้เฉพาะเจ้าของหรือ admin, เปลี่ยนสถานะได้เฉพาะ admin (ตรวจใน controller)
 	portfolioRoutes := rg.Group("/portfolios")
+	portfolioRoutes.Use(middleware.AuthMiddleware())
 	{
-		portfolioRoutes.POST("", portfolioController.Create)          // POST /portfolios
-		portfolioRoutes.GET("", portfolioController.GetAllPortfolios) // GET /portfolios
-		portfolioRoutes.GET("/:id", portfolioController.GetByID)      // GET /portfolios/:id
-		portfolioRoutes.PATCH("/:id", portfolioController.Update)     // PATCH /portfolios/:id
-		portfolioRoutes.DELETE("/:id", portfolioController.Delete)    // DELETE /portfolios/:id
+		portfolioRoutes.POST("", portfolioController.Create)                     // POST /portfolios
+		portfolioRoutes.GET("", canReview, portfolioController.GetAllPortfolios) // GET /portfolios
+		portfolioRoutes.GET("/:id", portfolioController.GetByID)                 // GET /portfolios/:id
+		portfolioRoutes.PATCH("/:id", portfolioController.Update)                // PATCH /portfolios/:id
+		portfolioRoutes.DELETE("/:id", portfolioController.Delete)               // DELETE /portfolios/:id
 
 	}
 
 	// Group สำหรับ User (เพื่อดึง Portfolio ตาม UserID)
 	userRoutes := rg.Group("/users")
 	{
-		userRoutes.GET("/:user_id/portfolios", portfolioController.ListByUserID) // GET /users/:user_id/portfolios
+		userRoutes.GET("/:user_id/portfolios", middleware.AuthMiddleware(), portfolioController.ListByUserID) // GET /users/:user_id/portfolios
 	}
 
 	// Group สำหรับดึง Status (Dropdown)
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/config"
 	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/entity"
 	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
@@ -15,13 +16,24 @@ func PostRoutes(rg *gin.RouterGroup) {
 	post := rg.Group("/post")
 	post.Use(middleware.AuthMiddleware())
 	{
-		post.POST("", postController.CreatePost)
+		post.POST("", middleware.RequirePermission(entity.PermPostsCreate), postController.CreatePost)
 		post.GET("", postController.GetAllPost)
 		post.GET("/my", postController.GetMyPosts)
+		post.GET("/detailed", postController.GetDetailedPosts)
 		post.GET("/student", postController.GetStudentPosts)
+		post.GET("/review-queue", middleware.RequirePermission(entity.PermActivitiesManageAny), postController.ReviewQueue)
 		post.GET("/:id", postController.GetPostByID)
+		// แก้ไข / ลบ ได้เฉพาะเจ้าของโพสต์หรือผู้มีสิทธิ์ activities.manage_any ซึ่งเปลี่ยนสถานะได้ด้วย (ตรวจใน controller)
 		post.PUT("/:id", postController.UpdatePost)
 		post.DELETE("/:id", postController.DeletePost)
+		// ขั้นตอนอนุมัติโพสต์: เจ้าของส่งตรวจ → ผู้ดูแลกิจกรรมอนุมัติหรือไม่อนุมัติพร้อมความเห็น
+		post.POST("/:id/submit", postController.SubmitPost)
+		post.POST("/:id/approve", middleware.RequirePermission(entity.PermActivitiesManageAny), postController.ApprovePost)
+		post.POST("/:id/reject", middleware.RequirePermission(entity.PermActivitiesManageAny), postController.RejectPost)
+		post.GET("/:id/reviews", postController.GetPostReviews)
+		// แบบฟอร์มสมัคร: ทุกคนที่เห็นโพสต์อ่านได้ แก้ไขได้เฉพาะเจ้าของโพสต์หรือ activities.manage_any
+		post.GET("/:id/form", postController.GetRegistrationForm)
+		post.PUT("/:id/form", postController.SaveRegistrationForm)
 	}
 
 }
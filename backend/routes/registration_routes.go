@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/config"
 	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/entity"
 	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
@@ -13,26 +14,57 @@ func RegistrationRoutes(r *gin.RouterGroup) {
 	registrationService := services.NewRegistrationService(db)
 	registrationController := controller.NewRegistrationController(registrationService)
 
+	canApprove := middleware.RequirePermission(entity.PermRegistrationsApprove)
+
 	registrations := r.Group("/registration")
+	registrations.Use(middleware.AuthMiddleware())
 	{
 
-		registrations.POST("", middleware.AuthMiddleware(), registrationController.CreateRegistration)
+		registrations.POST("", registrationController.CreateRegistration)
+
+		registrations.GET("/my", registrationController.GetMyRegistrations)
 
-		registrations.GET("/my", middleware.AuthMiddleware(), registrationController.GetMyRegistrations)
+		// คำเชิญเข้าทีมของผู้ใช้: ดูคำเชิญที่รอตอบ และตอบรับ/ปฏิเสธ (เฉพาะผู้ได้รับเชิญ)
+		registrations.GET("/invitations", registrationController.GetMyInvitations)
+		registrations.POST("/invitations/:invitationId/accept", registrationController.AcceptInvitation)
+		registrations.POST("/invitations/:invitationId/decline", registrationController.DeclineInvitation)
 
 		registrations.GET("/post/:id", registrationController.GetRegistrationsByPostID)
+		// ส่งออกรายชื่อทีมพร้อมคำตอบแบบฟอร์มสมัคร: เจ้าของกิจกรรมหรือ activities.manage_any (ตรวจใน controller)
+		registrations.GET("/post/:id/export", registrationController.ExportRegistrations)
+		// รายงานทีมที่ถอนการสมัคร: เจ้าของกิจกรรมหรือ activities.manage_any (ตรวจใน controller)
+		registrations.GET("/post/:id/withdrawals", registrationController.GetWithdrawalReport)
+
+		// อัปโหลดไฟล์สำหรับคำถามชนิด file ในแบบฟอร์มสมัคร
+		registrations.POST("/upload", registrationController.UploadAnswerFile)
 
 		registrations.GET("/:id", registrationController.GetRegistrationByID)
 
+		// แก้ไข / จัดการสมาชิก: หัวหน้าทีมหรือ admin (ตรวจใน controller)
 		registrations.PATCH("/:id", registrationController.UpdateRegistration)
 
-		registrations.PUT("/:id/status", registrationController.UpdateRegistrationStatus)
+		registrations.PUT("/:id/status", canApprove, registrationController.UpdateRegistrationStatus)
+
+		// ลบทีมถาวร (รวมคำตอบและประวัติสถานะ) เฉพาะ activities.manage_any นักศึกษาใช้การถอนการสมัครแทน
+		registrations.DELETE("/:id", middleware.RequirePermission(entity.PermActivitiesManageAny), registrationController.DeleteRegistration)
+
+		// หัวหน้าทีมถอนการสมัคร (เก็บประวัติไว้) ถอนหลังกำหนดของกิจกรรมถูกหักคะแนน
+		registrations.POST("/:id/withdraw", registrationController.WithdrawRegistration)
+
+		// ยืนยันที่นั่งที่ได้จากคิวสำรอง (สมาชิกในทีม)
+		registrations.POST("/:id/confirm", registrationController.ConfirmRegistration)
 
-		registrations.DELETE("/:id", registrationController.DeleteRegistration)
+		// หัวหน้าทีมเชิญผู้อื่นเข้าทีม ผู้ได้รับเชิญต้องตอบรับก่อนจึงนับเป็นสมาชิก (สมาชิกในทีมดูรายการได้)
+		registrations.GET("/:id/invitations", registrationController.ListTeamInvitations)
+		registrations.POST("/:id/invitations", registrationController.InviteMember)
+		registrations.DELETE("/:id/invitations/:invitationId", registrationController.CancelInvitation)
 
+		// admin เพิ่มสมาชิกได้ทันที หัวหน้าทีมจะเป็นการส่งคำเชิญ (ตรวจใน controller)
 		registrations.POST("/:id/users", registrationController.AddUserToRegistration)
+		// สมาชิกออกจากทีมเองได้ ถ้าหัวหน้าทีมออก สมาชิกที่เข้าทีมก่อนสุดเป็นหัวหน้าแทน
 		registrations.DELETE("/:id/users", registrationController.RemoveUserFromRegistration)
+		registrations.POST("/:id/leader", registrationController.TransferLeadership)
 	}
 
-	r.GET("/posts/:id/registrations", registrationController.GetRegistrationsByPostID)
+	r.GET("/posts/:id/registrations", middleware.AuthMiddleware(), registrationController.GetRegistrationsByPostID)
 }
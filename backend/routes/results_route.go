@@ -3,6 +3,8 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 	"gorm.io/gorm"
 )
@@ -11,11 +13,14 @@ func ResultsRoutes(r *gin.RouterGroup, db *gorm.DB) {
 	resultsService := services.NewResultsService(db)
 	resultsController := controller.NewResultsController(resultsService)
 
+	canManage := middleware.RequirePermission(entity.PermResultsManage)
+
 	route := r.Group("/results")
+	route.Use(middleware.AuthMiddleware())
 	{
 		route.GET("", resultsController.GetAllResults)                   // GET /results
 		route.GET("/post/:postId", resultsController.GetResultsByPostID) // GET /results/post/:postId
-		route.POST("", resultsController.CreateResult)
-		route.PUT(":id", resultsController.UpdateResult)
+		route.POST("", canManage, resultsController.CreateResult)
+		route.PUT(":id", canManage, resultsController.UpdateResult)
 	}
 }
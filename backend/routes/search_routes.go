@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+func SearchRoutes(rg *gin.RouterGroup) {
+	searchController := controller.NewSearchController(services.NewSearchService(config.DB))
+
+	rg.GET("/search", middleware.AuthMiddleware(), searchController.Search)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sut68/team21/config"
 	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/entity"
 	"github.com/sut68/team21/middleware"
 	"github.com/sut68/team21/services"
 )
@@ -11,15 +12,23 @@ import (
 func UserRoutes(r *gin.RouterGroup) {
 	userService := services.NewUserService(config.DB)
 	userController := controller.NewUserController(userService)
-	r.GET("/users", userController.GetAllUsers)
+	sessionService := services.NewSessionService(config.DB)
+	accountService := services.NewAccountService(config.DB, services.NewMailer(), sessionService)
+	userAdminController := controller.NewUserAdminController(services.NewUserAdminService(config.DB, sessionService, accountService))
+	permissionController := controller.NewPermissionController(services.NewPermissionService(config.DB))
+	privacyController := controller.NewPrivacyController(services.NewPrivacyService(config.DB, sessionService))
+	r.GET("/users", middleware.AuthMiddleware(), middleware.RequirePermission(entity.PermUsersManage), userController.GetAllUsers)
+	// ใช้ตอนสมัครสมาชิก (ก่อนมี token) จึงไม่ผ่าน AuthMiddleware
 	r.POST("/upload/avatar", userController.UploadAvatar)
 
 	profileRoutes := r.Group("/profiles")
-	profileRoutes.GET("/:sutId", userController.GetUserProfile)
 	profileRoutes.Use(middleware.AuthMiddleware())
 	{
 		profileRoutes.GET("/me", userController.GetMyProfile)
 		profileRoutes.PUT("/me", userController.UpdateMyProfile)
+		profileRoutes.DELETE("/me", privacyController.DeleteMyAccount)
+		profileRoutes.GET("/me/export", privacyController.ExportMyData)
+		profileRoutes.GET("/:sutId", userController.GetUserProfile)
 	}
 	
 	userRoutes := r.Group("/users")
@@ -29,4 +38,20 @@ func UserRoutes(r *gin.RouterGroup) {
 		userRoutes.GET("/sut-id/:sutId", userController.GetUserBySutId)
 		userRoutes.POST("/by-sut-ids", userController.GetUsersBySutIds)
 	}
+
+	// จัดการบัญชีผู้ใช้ บทบาท และสิทธิ์
+	adminRoutes := r.Group("")
+	adminRoutes.Use(middleware.AuthMiddleware(), middleware.RequirePermission(entity.PermUsersManage))
+	{
+		adminRoutes.GET("/roles", userAdminController.ListRoles)
+		adminRoutes.POST("/roles", userAdminController.CreateRole)
+		adminRoutes.PUT("/roles/:id/permissions", permissionController.UpdateRolePermissions)
+		adminRoutes.GET("/permissions", permissionController.ListPermissions)
+		adminRoutes.PUT("/users/:id/role", userAdminController.ChangeRole)
+		adminRoutes.POST("/users/:id/deactivate", userAdminController.Deactivate)
+		adminRoutes.POST("/users/:id/reactivate", userAdminController.Reactivate)
+		adminRoutes.POST("/users/:id/force-password-reset", userAdminController.ForcePasswordReset)
+		adminRoutes.POST("/users/:id/impersonate", userAdminController.Impersonate)
+		adminRoutes.POST("/users/:id/anonymize", privacyController.Anonymize)
+	}
 }
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	EmailVerificationTTL = 24 * time.Hour
+	PasswordResetTTL     = 30 * time.Minute
+)
+
+var (
+	ErrEmailNotVerified    = errors.New("กรุณายืนยันอีเมลก่อนเข้าสู่ระบบ")
+	ErrInvalidAccountToken = errors.New("ลิงก์ไม่ถูกต้องหรือหมดอายุแล้ว")
+)
+
+// AccountService ดูแลการยืนยันอีเมลและการรีเซ็ตรหัสผ่านผ่าน token ที่ส่งทางอีเมล
+type AccountService struct {
+	db       *gorm.DB
+	mailer   Mailer
+	sessions *SessionService
+}
+
+func NewAccountService(db *gorm.DB, mailer Mailer, sessions *SessionService) *AccountService {
+	return &AccountService{db: db, mailer: mailer, sessions: sessions}
+}
+
+// CheckAccountToken ตรวจว่า token ยังใช้งานได้ (ยังไม่ถูกใช้และยังไม่หมดอายุ)
+func CheckAccountToken(token *entity.AccountToken, purpose string, now time.Time) error {
+	if token == nil || token.Purpose != purpose || token.UsedAt != nil || !now.Before(token.ExpiresAt) {
+		return ErrInvalidAccountToken
+	}
+	return nil
+}
+
+// issueToken สร้าง token ใหม่และยกเลิก token เดิมที่ยังไม่ถูกใช้ของ purpose เดียวกัน
+func (s *AccountService) issueToken(tx *gorm.DB, userID uint, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	if err := tx.Model(&entity.AccountToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", now).Error; err != nil {
+		return "", err
+	}
+
+	raw, err := config.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	token := entity.AccountToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: config.HashToken(raw),
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := tx.Create(&token).Error; err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// consumeToken ล็อกแถวแล้วทำเครื่องหมายว่าใช้แล้ว ป้องกันการใช้ token ซ้ำพร้อมกัน
+func (s *AccountService) consumeToken(tx *gorm.DB, raw, purpose string) (*entity.AccountToken, error) {
+	var token entity.AccountToken
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", config.HashToken(raw)).
+		First(&token).Error; err != nil {
+		return nil, ErrInvalidAccountToken
+	}
+
+	now := time.Now()
+	if err := CheckAccountToken(&token, purpose, now); err != nil {
+		return nil, err
+	}
+
+	token.UsedAt = &now
+	if err := tx.Save(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// SendVerificationEmail ส่งลิงก์ยืนยันอีเมลให้ผู้ใช้
+func (s *AccountService) SendVerificationEmail(user *entity.User) error {
+	var raw string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		raw, err = s.issueToken(tx, user.ID, entity.TokenPurposeEmailVerification, EmailVerificationTTL)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("ไม่สามารถสร้างลิงก์ยืนยันอีเมลได้: %w", err)
+	}
+
+	link := apiBaseURL() + "/api/auth/verify-email?token=" + url.QueryEscape(raw)
+	body := fmt.Sprintf("สวัสดีคุณ %s\n\nกรุณายืนยันอีเมลของคุณโดยคลิกลิงก์ด้านล่าง (ลิงก์มีอายุ 24 ชั่วโมง)\n%s\n\nหากคุณไม่ได้สมัครสมาชิก Engi Connect กรุณาเพิกเฉยต่ออีเมลนี้\n", user.FirstName, link)
+	return s.mailer.Send(user.Email, "ยืนยันอีเมล Engi Connect", body)
+}
+
+// VerifyEmail ยืนยันอีเมลด้วย token จากลิงก์
+func (s *AccountService) VerifyEmail(raw string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		token, err := s.consumeToken(tx, raw, entity.TokenPurposeEmailVerification)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&entity.User{}).
+			Where("id = ? AND email_verified_at IS NULL", token.UserID).
+			Update("email_verified_at", time.Now()).Error
+	})
+}
+
+// ResendVerification ส่งลิงก์ยืนยันใหม่ ไม่บอกว่าอีเมลมีอยู่ในระบบหรือไม่
+func (s *AccountService) ResendVerification(email string) error {
+	var user entity.User
+	if err := s.db.Where("email = ?", strings.ToLower(email)).First(&user).Error; err != nil {
+		return nil
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.SendVerificationEmail(&user)
+}
+
+// ForgotPassword ส่งลิงก์รีเซ็ตรหัสผ่าน ไม่บอกว่าอีเมลมีอยู่ในระบบหรือไม่
+func (s *AccountService) ForgotPassword(email string) error {
+	var user entity.User
+	if err := s.db.Where("email = ?", strings.ToLower(email)).First(&user).Error; err != nil {
+		return nil
+	}
+	return s.sendPasswordReset(&user)
+}
+
+// sendPasswordReset ออกลิงก์รีเซ็ตรหัสผ่านใหม่ (ลิงก์เดิมใช้ไม่ได้) และส่งไปที่อีเมลของผู้ใช้
+func (s *AccountService) sendPasswordReset(user *entity.User) error {
+	var raw string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		raw, err = s.issueToken(tx, user.ID, entity.TokenPurposePasswordReset, PasswordResetTTL)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("ไม่สามารถสร้างลิงก์รีเซ็ตรหัสผ่านได้: %w", err)
+	}
+
+	link := frontendBaseURL() + "/reset-password?token=" + url.QueryEscape(raw)
+	body := fmt.Sprintf("สวัสดีคุณ %s\n\nมีการขอรีเซ็ตรหัสผ่านบัญชี Engi Connect ของคุณ คลิกลิงก์ด้านล่างเพื่อตั้งรหัสผ่านใหม่ (ลิงก์มีอายุ 30 นาทีและใช้ได้ครั้งเดียว)\n%s\n\nหากคุณไม่ได้ร้องขอ กรุณาเพิกเฉยต่ออีเมลนี้\n", user.FirstName, link)
+	if err := s.mailer.Send(user.Email, "รีเซ็ตรหัสผ่าน Engi Connect", body); err != nil {
+		log.Printf("send password reset email to user %d failed: %v", user.ID, err)
+		return errors.New("ไม่สามารถส่งอีเมลได้ กรุณาลองใหม่อีกครั้ง")
+	}
+	return nil
+}
+
+// ResetPassword ตั้งรหัสผ่านใหม่ด้วย token และยกเลิก session กับ API token เดิมทั้งหมด
+func (s *AccountService) ResetPassword(raw, newPassword string) error {
+	hashed, err := config.HashPassword(newPassword)
+	if err != nil {
+		return errors.New("สร้างรหัสผ่านไม่สำเร็จ")
+	}
+
+	var userID uint
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		token, err := s.consumeToken(tx, raw, entity.TokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+		userID = token.UserID
+
+		// เปิดลิงก์จากอีเมลได้แปลว่าเป็นเจ้าของอีเมลจริง จึงถือว่ายืนยันอีเมลแล้วด้วย
+		if err := tx.Model(&entity.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"password":                hashed,
+			"email_verified_at":       gorm.Expr("COALESCE(email_verified_at, ?)", time.Now()),
+			"password_reset_required": false,
+		}).Error; err != nil {
+			return err
+		}
+		return RevokeAllAPITokens(tx, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.sessions.RevokeAllSessions(userID)
+}
+
+func frontendBaseURL() string {
+	if config.Env.FrontendURL != "" {
+		return strings.TrimRight(config.Env.FrontendURL, "/")
+	}
+	return "http://localhost:5173"
+}
+
+func apiBaseURL() string {
+	if config.Env.PublicAPIURL != "" {
+		return strings.TrimRight(config.Env.PublicAPIURL, "/")
+	}
+	return "http://localhost:" + config.Env.BackendPort
+}
+
+// FrontendURL ใช้ redirect กลับหน้าเว็บหลังยืนยันอีเมล
+func FrontendURL(path string) string {
+	return frontendBaseURL() + path
+}
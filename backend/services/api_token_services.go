@@ -0,0 +1,175 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultAPITokenDays = 90
+	MaxAPITokenDays     = 365
+	MaxAPITokensPerUser = 10
+	// apiTokenTouchInterval บันทึกเวลาใช้งานล่าสุดไม่ถี่กว่านี้ เพื่อไม่ต้องเขียน DB ทุก request
+	apiTokenTouchInterval = time.Minute
+)
+
+var (
+	ErrInvalidAPIToken     = errors.New("API token ไม่ถูกต้อง หมดอายุ หรือถูกเพิกถอนแล้ว")
+	ErrAPITokenNotFound    = errors.New("ไม่พบ API token")
+	ErrTooManyAPITokens    = fmt.Errorf("สร้าง API token ได้สูงสุด %d รายการ กรุณาเพิกถอน token ที่ไม่ใช้ก่อน", MaxAPITokensPerUser)
+	ErrInvalidAPITokenDays = fmt.Errorf("อายุของ token ต้องอยู่ระหว่าง 1 ถึง %d วัน", MaxAPITokenDays)
+)
+
+// IsAPIToken แยก personal API token ออกจาก JWT ด้วย prefix
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, entity.APITokenPrefix)
+}
+
+// ValidateAPITokenScopes ตรวจว่า scope มีอยู่จริง และ role ของผู้ใช้มีสิทธิ์ที่ scope นั้นต้องการ
+func ValidateAPITokenScopes(role string, scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("กรุณาเลือก scope อย่างน้อยหนึ่งรายการ")
+	}
+	for _, scope := range scopes {
+		permission, ok := entity.APITokenScopes[scope]
+		if !ok {
+			return fmt.Errorf("ไม่รู้จัก scope %s", scope)
+		}
+		if permission != "" && !RoleHasPermission(role, permission) {
+			return fmt.Errorf("คุณไม่มีสิทธิ์สร้าง token ที่มี scope %s", scope)
+		}
+	}
+	return nil
+}
+
+type APITokenService struct {
+	db *gorm.DB
+}
+
+func NewAPITokenService(db *gorm.DB) *APITokenService {
+	return &APITokenService{db: db}
+}
+
+func toAPITokenResponse(token *entity.APIToken) dto.APITokenResponse {
+	return dto.APITokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     token.ScopeList(),
+		CreatedAt:  token.CreatedAt,
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		LastUsedIP: token.LastUsedIP,
+		RevokedAt:  token.RevokedAt,
+	}
+}
+
+// Create สร้าง token ใหม่ คืน token จริงในครั้งนี้ครั้งเดียว
+func (s *APITokenService) Create(userID uint, role string, req dto.CreateAPITokenRequest) (*dto.APITokenResponse, error) {
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = DefaultAPITokenDays
+	}
+	if days < 1 || days > MaxAPITokenDays {
+		return nil, ErrInvalidAPITokenDays
+	}
+	scopes := uniqueStrings(req.Scopes)
+	if err := ValidateAPITokenScopes(role, scopes); err != nil {
+		return nil, err
+	}
+
+	var active int64
+	if err := s.db.Model(&entity.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Count(&active).Error; err != nil {
+		return nil, err
+	}
+	if active >= MaxAPITokensPerUser {
+		return nil, ErrTooManyAPITokens
+	}
+
+	secret, err := config.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	raw := entity.APITokenPrefix + secret
+
+	token := entity.APIToken{
+		UserID:    userID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    raw[:len(entity.APITokenPrefix)+6],
+		TokenHash: config.HashToken(raw),
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: time.Now().AddDate(0, 0, days),
+	}
+	if err := s.db.Create(&token).Error; err != nil {
+		return nil, err
+	}
+
+	res := toAPITokenResponse(&token)
+	res.Token = raw
+	return &res, nil
+}
+
+func (s *APITokenService) List(userID uint) ([]dto.APITokenResponse, error) {
+	var tokens []entity.APIToken
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	res := make([]dto.APITokenResponse, 0, len(tokens))
+	for i := range tokens {
+		res = append(res, toAPITokenResponse(&tokens[i]))
+	}
+	return res, nil
+}
+
+// Revoke เพิกถอน token ของผู้ใช้เอง มีผลทันทีกับ request ถัดไป
+func (s *APITokenService) Revoke(userID, tokenID uint) error {
+	result := s.db.Model(&entity.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
+
+// RevokeAllAPITokens เพิกถอน API token ทั้งหมดของผู้ใช้ (เมื่อรีเซ็ตรหัสผ่านหรือถูกบังคับให้รีเซ็ต)
+func RevokeAllAPITokens(db *gorm.DB, userID uint) error {
+	return db.Model(&entity.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// Authenticate ตรวจ token และคืนเจ้าของ พร้อมบันทึกเวลาและ IP ที่ใช้ล่าสุด
+func (s *APITokenService) Authenticate(raw, ip string) (*entity.User, *entity.APIToken, error) {
+	var token entity.APIToken
+	if err := s.db.Where("token_hash = ?", config.HashToken(raw)).First(&token).Error; err != nil {
+		return nil, nil, ErrInvalidAPIToken
+	}
+	now := time.Now()
+	if token.RevokedAt != nil || !now.Before(token.ExpiresAt) {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	var user entity.User
+	// บัญชีที่ถูกระงับหรือถูกบังคับให้ตั้งรหัสผ่านใหม่ใช้ token ไม่ได้ เช่นเดียวกับการเข้าสู่ระบบ
+	if err := s.db.Preload("Role").First(&user, token.UserID).Error; err != nil || checkLoginAllowed(&user) != nil || user.Role == nil {
+		return nil, nil, ErrInvalidAPIToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval || token.LastUsedIP != ip {
+		s.db.Model(&token).Updates(map[string]interface{}{"last_used_at": now, "last_used_ip": ip})
+	}
+	return &user, &token, nil
+}
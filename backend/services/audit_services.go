@@ -0,0 +1,230 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultAuditPageSize = 50
+	MaxAuditPageSize     = 200
+	// MaxAuditExportRows จำกัดจำนวนแถวของไฟล์ CSV ต่อการดาวน์โหลดหนึ่งครั้ง
+	MaxAuditExportRows = 50000
+)
+
+// auditIgnoredFields ฟิลด์ที่เปลี่ยนทุกครั้งที่บันทึกจึงไม่นับเป็นการเปลี่ยนแปลง
+var auditIgnoredFields = map[string]bool{"UpdatedAt": true, "updated_at": true}
+
+// RecordAudit เพิ่มเหตุการณ์ลง audit log ถ้าบันทึกไม่สำเร็จจะ log ไว้แต่ไม่ทำให้ request หลักล้ม
+func RecordAudit(db *gorm.DB, entry entity.AuditLog) {
+	if db == nil {
+		log.Printf("write audit log %s skipped: database not connected", entry.Action)
+		return
+	}
+	if err := db.Create(&entry).Error; err != nil {
+		log.Printf("write audit log %s failed: %v", entry.Action, err)
+	}
+}
+
+// RecordChange บันทึกการกระทำพร้อมค่าก่อน/หลังแก้ไข (เฉพาะฟิลด์ที่เปลี่ยน)
+// before หรือ after เป็น nil ได้ เช่น การลบหรือการสร้างข้อมูล
+func RecordChange(db *gorm.DB, entry entity.AuditLog, before, after any) {
+	entry.Before, entry.After = AuditDiff(before, after)
+	RecordAudit(db, entry)
+}
+
+// AuditDiff เทียบค่าระดับฟิลด์บนสุดของ before/after แล้วคืน JSON ของฟิลด์ที่ต่างกันทั้งสองฝั่ง
+func AuditDiff(before, after any) (string, string) {
+	b, a := auditFields(before), auditFields(after)
+	changedBefore := map[string]json.RawMessage{}
+	changedAfter := map[string]json.RawMessage{}
+	for key, value := range b {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if other, ok := a[key]; !ok || !bytes.Equal(value, other) {
+			changedBefore[key] = value
+		}
+	}
+	for key, value := range a {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if other, ok := b[key]; !ok || !bytes.Equal(value, other) {
+			changedAfter[key] = value
+		}
+	}
+	return auditJSON(changedBefore), auditJSON(changedAfter)
+}
+
+func auditFields(value any) map[string]json.RawMessage {
+	if value == nil {
+		return nil
+	}
+	raw, err := json.Marshal(value)
+	if err != nil || string(raw) == "null" {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return map[string]json.RawMessage{"value": raw}
+	}
+	for key, field := range fields {
+		var compact bytes.Buffer
+		if json.Compact(&compact, field) == nil {
+			fields[key] = compact.Bytes()
+		}
+	}
+	return fields
+}
+
+func auditJSON(fields map[string]json.RawMessage) string {
+	if len(fields) == 0 {
+		return ""
+	}
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
+
+type AuditService struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+func (s *AuditService) filtered(query dto.AuditLogQuery) *gorm.DB {
+	tx := s.db.Table("audit_logs").
+		Select("audit_logs.*, users.sut_id AS actor_sut_id").
+		Joins("LEFT JOIN users ON users.id = audit_logs.actor_id")
+	if query.ActorID != 0 {
+		tx = tx.Where("audit_logs.actor_id = ?", query.ActorID)
+	}
+	if query.ActorSutID != "" {
+		tx = tx.Where("users.sut_id = ?", query.ActorSutID)
+	}
+	if query.Action != "" {
+		// "registration.*" ค้นทุก action ที่ขึ้นต้นด้วย "registration."
+		if prefix, ok := strings.CutSuffix(query.Action, "*"); ok {
+			tx = tx.Where("audit_logs.action LIKE ?", prefix+"%")
+		} else {
+			tx = tx.Where("audit_logs.action = ?", query.Action)
+		}
+	}
+	if query.TargetType != "" {
+		tx = tx.Where("audit_logs.target_type = ?", query.TargetType)
+	}
+	if query.TargetID != "" {
+		tx = tx.Where("audit_logs.target_id = ?", query.TargetID)
+	}
+	if query.IPAddress != "" {
+		tx = tx.Where("audit_logs.ip_address = ?", query.IPAddress)
+	}
+	if !query.From.IsZero() {
+		tx = tx.Where("audit_logs.created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		// วันที่สิ้นสุดนับรวมทั้งวัน
+		tx = tx.Where("audit_logs.created_at < ?", query.To.AddDate(0, 0, 1))
+	}
+	return tx
+}
+
+// List ค้นหา audit log ตามเงื่อนไข เรียงจากใหม่ไปเก่า
+func (s *AuditService) List(query dto.AuditLogQuery) (*dto.AuditLogPage, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = DefaultAuditPageSize
+	}
+	if query.Limit > MaxAuditPageSize {
+		query.Limit = MaxAuditPageSize
+	}
+
+	var total int64
+	if err := s.filtered(query).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	logs := []dto.AuditLogEntry{}
+	if err := s.filtered(query).
+		Order("audit_logs.created_at DESC, audit_logs.id DESC").
+		Offset((query.Page - 1) * query.Limit).
+		Limit(query.Limit).
+		Scan(&logs).Error; err != nil {
+		return nil, err
+	}
+	return &dto.AuditLogPage{Data: logs, Total: total, Page: query.Page, Limit: query.Limit}, nil
+}
+
+var auditCSVHeader = []string{"id", "created_at", "actor_id", "actor_sut_id", "action", "target_type", "target_id", "ip_address", "detail", "before", "after"}
+
+// ExportCSV เขียน audit log ตามเงื่อนไขเป็น CSV (ไม่แบ่งหน้า แต่จำกัดที่ MaxAuditExportRows)
+func (s *AuditService) ExportCSV(query dto.AuditLogQuery, w io.Writer) error {
+	var logs []dto.AuditLogEntry
+	if err := s.filtered(query).
+		Order("audit_logs.created_at DESC, audit_logs.id DESC").
+		Limit(MaxAuditExportRows).
+		Scan(&logs).Error; err != nil {
+		return err
+	}
+	return WriteAuditCSV(w, logs)
+}
+
+// WriteAuditCSV แปลงรายการ audit log เป็น CSV
+func WriteAuditCSV(w io.Writer, logs []dto.AuditLogEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(auditCSVHeader); err != nil {
+		return err
+	}
+	for _, entry := range logs {
+		actorID := ""
+		if entry.ActorID != nil {
+			actorID = strconv.FormatUint(uint64(*entry.ActorID), 10)
+		}
+		record := []string{
+			strconv.FormatUint(uint64(entry.ID), 10),
+			entry.CreatedAt.Format(time.RFC3339),
+			actorID,
+			entry.ActorSutID,
+			entry.Action,
+			entry.TargetType,
+			entry.TargetID,
+			entry.IPAddress,
+			entry.Detail,
+			entry.Before,
+			entry.After,
+		}
+		for i, value := range record {
+			record[i] = csvSafe(value)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvSafe กันค่าที่ผู้ใช้กรอก (เช่นเหตุผล) ถูก Excel ตีความเป็นสูตร
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
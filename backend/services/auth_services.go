@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"log"
 	"regexp"
 	"strings"
 
@@ -13,11 +14,14 @@ import (
 )
 
 type AuthService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	accounts *AccountService
+	guard    *LoginGuard
+	mfa      *MFAService
 }
 
-func NewAuthService(db *gorm.DB) *AuthService {
-	return &AuthService{db: db}
+func NewAuthService(db *gorm.DB, accounts *AccountService, guard *LoginGuard, mfa *MFAService) *AuthService {
+	return &AuthService{db: db, accounts: accounts, guard: guard, mfa: mfa}
 }
 
 func (s *AuthService) Register(req dto.RegisterRequest) error {
@@ -105,50 +109,80 @@ func (s *AuthService) Register(req dto.RegisterRequest) error {
 	if err := s.db.Create(&user).Error; err != nil {
 		return fmt.Errorf("ไม่สามารถลงทะเบียนได้: %w", err)
 	}
-	pointRecord := entity.PointRecord{
-		UserID: user.ID,
-		Points: 50,
-		Type:   "สมัครสมาชิกใหม่",
-	}
-	s.db.Create(&pointRecord)
-	userPoint := entity.UserPoint{
-		UserID:      user.ID,
-		TotalPoints: 50,
+	grantWelcomePoints(s.db, user.ID)
+
+	// ส่งอีเมลไม่สำเร็จไม่ถือว่าสมัครไม่สำเร็จ ผู้ใช้ขอส่งลิงก์ใหม่ได้ที่ /auth/resend-verification
+	if err := s.accounts.SendVerificationEmail(&user); err != nil {
+		log.Printf("send verification email to user %d failed: %v", user.ID, err)
 	}
-	s.db.Create(&userPoint)
 
 	return nil
 }
 
-func (s *AuthService) Login(req dto.LoginRequest) (dto.LoginResponse, error) {
+func (s *AuthService) Login(req dto.LoginRequest, userAgent, ip string) (dto.LoginResponse, error) {
 	var user entity.User
 
 	sutId := strings.ToUpper(req.SutId)
 	errMsg := "รหัสนักศึกษาหรือรหัสผ่านไม่ถูกต้อง"
+
+	// ระหว่างถูกหน่วงเวลา/ล็อก จะไม่ตรวจรหัสผ่านเลย
+	if err := s.guard.Check(sutId, ip); err != nil {
+		return dto.LoginResponse{}, err
+	}
+
 	if err := s.db.Preload("Role").
 		Where("sut_id = ?", sutId).
 		First(&user).Error; err != nil {
+		s.guard.RecordFailure(sutId, ip)
 		return dto.LoginResponse{}, errors.New(errMsg)
 	}
 
 	if !config.CheckPasswordHash(req.Password, user.Password) {
+		s.guard.RecordFailure(sutId, ip)
 		return dto.LoginResponse{}, errors.New(errMsg)
 	}
+	s.guard.RecordSuccess(sutId)
+
+	if user.EmailVerifiedAt == nil {
+		return dto.LoginResponse{}, ErrEmailNotVerified
+	}
+	if err := checkLoginAllowed(&user); err != nil {
+		return dto.LoginResponse{}, err
+	}
 
-	token, err := config.GenerateJWT(user.ID, user.SutId, user.Role.Name)
+	res, err := s.mfa.StartLogin(&user, userAgent, ip)
 	if err != nil {
 		return dto.LoginResponse{}, fmt.Errorf("ไม่สามารถสร้าง token ได้: %w", err)
 	}
 
-	res := dto.LoginResponse{
-		ID:        user.ID,
-		SutId:     user.SutId,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Role:      user.Role.Name,
-		Token:     token,
+	return res, nil
+}
+
+// grantWelcomePoints แต้มต้อนรับสมาชิกใหม่
+func grantWelcomePoints(db *gorm.DB, userID uint) {
+	pointRecord := entity.PointRecord{
+		UserID: userID,
+		Points: 50,
+		Type:   "สมัครสมาชิกใหม่",
 	}
+	db.Create(&pointRecord)
+	userPoint := entity.UserPoint{
+		UserID:      userID,
+		TotalPoints: 50,
+	}
+	db.Create(&userPoint)
+}
 
-	return res, nil
+func newLoginResponse(user *entity.User, tokens *dto.TokenPair) dto.LoginResponse {
+	return dto.LoginResponse{
+		ID:           user.ID,
+		SutId:        user.SutId,
+		Email:        user.Email,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		Role:         user.Role.Name,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}
 }
@@ -76,6 +76,20 @@ func (s *CertificateService) CreateCertificate(certificate *entity.Certificate)
 
 
 
+// IsPostOwner ตรวจสอบว่าผู้ใช้เป็นผู้สร้างกิจกรรม (ผู้จัดออกเกียรติบัตรได้เฉพาะกิจกรรมของตนเอง)
+func (s *CertificateService) IsPostOwner(postID, userID uint) bool {
+	return IsPostOwner(s.db, postID, userID)
+}
+
+// IsCertificateOwner ตรวจสอบว่าผู้ใช้เป็นผู้สร้างกิจกรรมของเกียรติบัตรนี้
+func (s *CertificateService) IsCertificateOwner(id, userID uint) bool {
+	var certificate entity.Certificate
+	if err := s.db.Select("post_id").First(&certificate, id).Error; err != nil {
+		return false
+	}
+	return IsPostOwner(s.db, certificate.PostID, userID)
+}
+
 func (s *CertificateService) GetAllCertificate() ([]entity.Certificate, error) {
 	var certificates []entity.Certificate
 
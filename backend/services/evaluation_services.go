@@ -10,7 +10,6 @@ import (
 const (
 	MinEvaluationScore float64 = 0.0
 	MaxEvaluationScore float64 = 5.0
-	PostStatusEnded uint = 6
 )
 
 type EvaluationService struct {
@@ -234,7 +233,7 @@ func (s *EvaluationService) GetMyRegisteredPosts(userID uint) ([]entity.Registra
 	err := s.db.
 		Joins("JOIN user_registrations ur ON ur.registration_id = registrations.id").
 		Joins("JOIN posts ON posts.id = registrations.post_id").
-		Where("ur.user_id = ? AND posts.status_id = ?", userID, PostStatusEnded).
+		Where("ur.user_id = ? AND posts.status_id = ?", userID, entity.PostStatusEnded).
 		Where("posts.stop_date < NOW()").
 		Where("registrations.id NOT IN (SELECT registration_id FROM activity_evaluation_respones WHERE user_id = ? AND deleted_at IS NULL)", userID).
 		Where("posts.id IN (SELECT post_id FROM activity_evaluation_topics WHERE deleted_at IS NULL)").
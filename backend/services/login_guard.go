@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginPolicy กำหนดการหน่วงเวลาและล็อกเมื่อเข้าสู่ระบบผิดติดกัน
+type LoginPolicy struct {
+	DelayAfter   int           // ผิดครบกี่ครั้งจึงเริ่มหน่วงเวลา
+	LockAfter    int           // ผิดครบกี่ครั้งจึงล็อก
+	BaseDelay    time.Duration // หน่วงครั้งแรก แล้วเพิ่มเป็นสองเท่าทุกครั้งที่ผิด
+	MaxDelay     time.Duration
+	LockDuration time.Duration
+	ResetAfter   time.Duration // ไม่มีการผิดเพิ่มนานเท่านี้ให้เริ่มนับใหม่
+}
+
+var (
+	AccountLoginPolicy = LoginPolicy{
+		DelayAfter:   3,
+		LockAfter:    5,
+		BaseDelay:    time.Second,
+		MaxDelay:     30 * time.Second,
+		LockDuration: 15 * time.Minute,
+		ResetAfter:   time.Hour,
+	}
+	// IP เดียวกันอาจมีผู้ใช้หลายคน (เช่น wifi มหาวิทยาลัย) จึงผ่อนกว่าต่อบัญชี
+	IPLoginPolicy = LoginPolicy{
+		DelayAfter:   10,
+		LockAfter:    50,
+		BaseDelay:    time.Second,
+		MaxDelay:     30 * time.Second,
+		LockDuration: 15 * time.Minute,
+		ResetAfter:   time.Hour,
+	}
+)
+
+// LoginThrottledError บอกว่าต้องรอนานเท่าไรก่อนลองเข้าสู่ระบบใหม่
+type LoginThrottledError struct {
+	Wait   time.Duration
+	Locked bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("บัญชีถูกล็อกชั่วคราวเนื่องจากเข้าสู่ระบบผิดหลายครั้ง กรุณาลองใหม่ในอีก %d นาที", int(e.Wait.Minutes())+1)
+	}
+	return fmt.Sprintf("เข้าสู่ระบบผิดหลายครั้ง กรุณารอ %d วินาทีแล้วลองใหม่", int(e.Wait.Seconds())+1)
+}
+
+func (p LoginPolicy) stale(t *entity.LoginThrottle, now time.Time) bool {
+	return now.Sub(t.LastFailedAt) > p.ResetAfter
+}
+
+// Wait คืนเวลาที่ต้องรอก่อนลองใหม่ (0 = ลองได้เลย) และบอกว่าถูกล็อกอยู่หรือไม่
+func (p LoginPolicy) Wait(t *entity.LoginThrottle, now time.Time) (time.Duration, bool) {
+	if t == nil {
+		return 0, false
+	}
+	if t.LockedUntil != nil && now.Before(*t.LockedUntil) {
+		return t.LockedUntil.Sub(now), true
+	}
+	if t.LockedUntil != nil || p.stale(t, now) || t.FailedCount < p.DelayAfter {
+		return 0, false
+	}
+
+	delay := p.BaseDelay
+	for i := p.DelayAfter; i < t.FailedCount && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if ready := t.LastFailedAt.Add(delay); now.Before(ready) {
+		return ready.Sub(now), false
+	}
+	return 0, false
+}
+
+// Fail นับการเข้าสู่ระบบผิดหนึ่งครั้ง คืน true เมื่อครั้งนี้ทำให้ถูกล็อก
+func (p LoginPolicy) Fail(t *entity.LoginThrottle, now time.Time) bool {
+	if p.stale(t, now) || (t.LockedUntil != nil && !now.Before(*t.LockedUntil)) {
+		t.FailedCount = 0
+		t.LockedUntil = nil
+	}
+	t.FailedCount++
+	t.LastFailedAt = now
+	if t.FailedCount >= p.LockAfter {
+		until := now.Add(p.LockDuration)
+		t.LockedUntil = &until
+		return true
+	}
+	return false
+}
+
+// LoginGuard เก็บสถานะการเข้าสู่ระบบผิดไว้ในฐานข้อมูล จึงใช้ได้แม้รันหลาย instance
+type LoginGuard struct {
+	db *gorm.DB
+}
+
+func NewLoginGuard(db *gorm.DB) *LoginGuard {
+	return &LoginGuard{db: db}
+}
+
+func accountThrottleKey(sutId string) string {
+	return "account:" + strings.ToUpper(sutId)
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check ตรวจก่อนเช็ครหัสผ่าน ถ้ายังต้องรอจะคืน *LoginThrottledError
+func (g *LoginGuard) Check(sutId, ip string) error {
+	now := time.Now()
+	var result *LoginThrottledError
+
+	checks := []struct {
+		key    string
+		policy LoginPolicy
+	}{
+		{accountThrottleKey(sutId), AccountLoginPolicy},
+		{ipThrottleKey(ip), IPLoginPolicy},
+	}
+	for _, c := range checks {
+		var throttle entity.LoginThrottle
+		if err := g.db.Where("key = ?", c.key).First(&throttle).Error; err != nil {
+			continue
+		}
+		wait, locked := c.policy.Wait(&throttle, now)
+		if wait > 0 && (result == nil || wait > result.Wait) {
+			result = &LoginThrottledError{Wait: wait, Locked: locked}
+		}
+	}
+
+	if result != nil {
+		return result
+	}
+	return nil
+}
+
+// RecordFailure นับการเข้าสู่ระบบผิดทั้งต่อบัญชีและต่อ IP และบันทึก audit เมื่อถูกล็อก
+func (g *LoginGuard) RecordFailure(sutId, ip string) {
+	now := time.Now()
+	g.fail(accountThrottleKey(sutId), AccountLoginPolicy, now, entity.AuditLog{
+		Action:     entity.AuditActionAccountLocked,
+		TargetType: "user",
+		TargetID:   strings.ToUpper(sutId),
+		IPAddress:  ip,
+	})
+	g.fail(ipThrottleKey(ip), IPLoginPolicy, now, entity.AuditLog{
+		Action:     entity.AuditActionIPLocked,
+		TargetType: "ip",
+		TargetID:   ip,
+		IPAddress:  ip,
+	})
+}
+
+func (g *LoginGuard) fail(key string, policy LoginPolicy, now time.Time, lockEvent entity.AuditLog) {
+	var locked bool
+	var failedCount int
+	err := g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&entity.LoginThrottle{Key: key, LastFailedAt: now}).Error; err != nil {
+			return err
+		}
+
+		var throttle entity.LoginThrottle
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ?", key).
+			First(&throttle).Error; err != nil {
+			return err
+		}
+
+		locked = policy.Fail(&throttle, now)
+		failedCount = throttle.FailedCount
+		return tx.Save(&throttle).Error
+	})
+	if err != nil || !locked {
+		return
+	}
+
+	lockEvent.Detail = fmt.Sprintf("failed_count=%d locked_for=%s", failedCount, policy.LockDuration)
+	RecordAudit(g.db, lockEvent)
+}
+
+// RecordSuccess ล้างตัวนับของบัญชีเมื่อเข้าสู่ระบบสำเร็จ (ตัวนับของ IP ไม่ล้าง)
+func (g *LoginGuard) RecordSuccess(sutId string) {
+	g.db.Where("key = ?", accountThrottleKey(sutId)).Delete(&entity.LoginThrottle{})
+}
+
+// ListLocked รายการบัญชี/IP ที่ถูกล็อกอยู่ในขณะนี้
+func (g *LoginGuard) ListLocked() ([]entity.LoginThrottle, error) {
+	var throttles []entity.LoginThrottle
+	err := g.db.Where("locked_until > ?", time.Now()).Order("locked_until desc").Find(&throttles).Error
+	return throttles, err
+}
+
+// Unlock ปลดล็อกบัญชีโดยผู้ดูแลระบบ
+func (g *LoginGuard) Unlock(sutId string, actorID uint, ip string) error {
+	result := g.db.Where("key = ?", accountThrottleKey(sutId)).Delete(&entity.LoginThrottle{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("บัญชีนี้ไม่ได้ถูกล็อก")
+	}
+
+	RecordAudit(g.db, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionAccountUnlocked,
+		TargetType: "user",
+		TargetID:   strings.ToUpper(sutId),
+		IPAddress:  ip,
+	})
+	return nil
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/config"
+)
+
+// Mailer คือช่องทางส่งอีเมลของระบบ เปลี่ยน implementation ได้ตาม MAIL_DRIVER
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer เลือก Mailer ตามค่าใน .env (smtp สำหรับ production, log สำหรับ dev และ test)
+func NewMailer() Mailer {
+	if strings.EqualFold(config.Env.MailDriver, "smtp") {
+		return &SMTPMailer{
+			Host:     config.Env.SMTPHost,
+			Port:     config.Env.SMTPPort,
+			Username: config.Env.SMTPUser,
+			Password: config.Env.SMTPPassword,
+			From:     config.Env.MailFrom,
+		}
+	}
+	return &LogMailer{Dir: config.Env.MailLogDir}
+}
+
+// SMTPMailer ส่งอีเมลผ่าน SMTP server (รองรับ STARTTLS ผ่าน net/smtp)
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+	msg, err := buildMessage(m.From, to, subject, body)
+	if err != nil {
+		return err
+	}
+	addr := m.Host + ":" + m.Port
+	return smtp.SendMail(addr, auth, m.From, []string{to}, msg)
+}
+
+// LogMailer ไม่ส่งอีเมลจริง แต่เขียนเป็นไฟล์ .eml ลงใน Dir (ถ้าไม่กำหนด Dir จะ log อย่างเดียว)
+type LogMailer struct {
+	Dir string
+}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	msg, err := buildMessage("", to, subject, body)
+	if err != nil {
+		return err
+	}
+	log.Printf("[mail] to=%s subject=%q\n%s", to, subject, body)
+	if m.Dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(m.Dir, 0755); err != nil {
+		return err
+	}
+	filename := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), sanitizeFilename(to))
+	return os.WriteFile(filepath.Join(m.Dir, filename), msg, 0644)
+}
+
+// ErrInvalidMailAddress ที่อยู่อีเมลมีขึ้นบรรทัดใหม่ (กันการแทรก header)
+var ErrInvalidMailAddress = errors.New("ที่อยู่อีเมลไม่ถูกต้อง")
+
+// buildMessage สร้างอีเมล ที่อยู่ที่มี CR/LF ถูกปฏิเสธ หัวเรื่องตัด CR/LF ออกแล้ว encode แบบ RFC 2047
+// ผู้เรียกจึงแทรก header เพิ่มผ่านข้อมูลที่ผู้ใช้กรอก (เช่น ชื่อทีม) ไม่ได้
+func buildMessage(from, to, subject, body string) ([]byte, error) {
+	if strings.ContainsAny(from, "\r\n") || strings.ContainsAny(to, "\r\n") {
+		return nil, ErrInvalidMailAddress
+	}
+	subject = strings.Join(strings.Fields(strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)), " ")
+
+	var b strings.Builder
+	if from != "" {
+		b.WriteString("From: " + from + "\r\n")
+	}
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(body)
+	return []byte(b.String()), nil
+}
+
+func sanitizeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,413 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	MFAChallengeTTL   = 5 * time.Minute
+	MFAMaxAttempts    = 5
+	RecoveryCodeCount = 10
+	totpIssuer        = "Engi Connect"
+)
+
+var (
+	ErrInvalidMFAChallenge = errors.New("คำขอยืนยันตัวตนหมดอายุหรือไม่ถูกต้อง กรุณาเข้าสู่ระบบใหม่")
+	ErrInvalidMFACode      = errors.New("รหัสยืนยันไม่ถูกต้อง")
+	ErrMFARequiredForRole  = errors.New("ผู้ดูแลระบบต้องเปิดใช้การยืนยันตัวตนสองขั้นตอนเสมอ")
+	ErrMFAAlreadyEnabled   = errors.New("เปิดใช้การยืนยันตัวตนสองขั้นตอนอยู่แล้ว")
+	ErrMFANotEnabled       = errors.New("ยังไม่ได้เปิดใช้การยืนยันตัวตนสองขั้นตอน")
+	ErrMFASetupNotStarted  = errors.New("กรุณาเริ่มตั้งค่าการยืนยันตัวตนสองขั้นตอนก่อน")
+)
+
+// MFARequired บังคับ 2FA กับ role ที่จัดการบัญชีผู้ใช้ได้ (เช่น admin)
+func MFARequired(user *entity.User) bool {
+	return user.Role != nil && RoleHasPermission(user.Role.Name, entity.PermUsersManage)
+}
+
+// MFAService ดูแล TOTP 2FA และเป็นจุดเดียวที่ตัดสินว่าการเข้าสู่ระบบ (รหัสผ่านหรือ SSO)
+// จะได้ session ทันทีหรือต้องผ่านขั้นที่สองก่อน
+type MFAService struct {
+	db       *gorm.DB
+	sessions *SessionService
+	guard    *LoginGuard
+}
+
+func NewMFAService(db *gorm.DB, sessions *SessionService, guard *LoginGuard) *MFAService {
+	return &MFAService{db: db, sessions: sessions, guard: guard}
+}
+
+// StartLogin เรียกหลังตรวจรหัสผ่าน/SSO สำเร็จ ถ้าต้องใช้ 2FA จะคืน challenge token แทน JWT
+func (s *MFAService) StartLogin(user *entity.User, userAgent, ip string) (dto.LoginResponse, error) {
+	enabled := user.TOTPEnabledAt != nil
+	if !enabled && !MFARequired(user) {
+		tokens, err := s.sessions.CreateSession(user, userAgent, ip)
+		if err != nil {
+			return dto.LoginResponse{}, err
+		}
+		return newLoginResponse(user, tokens), nil
+	}
+
+	raw, err := config.GenerateSecureToken(32)
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+	challenge := entity.AccountToken{
+		UserID:    user.ID,
+		Purpose:   entity.TokenPurposeMFAChallenge,
+		TokenHash: config.HashToken(raw),
+		ExpiresAt: time.Now().Add(MFAChallengeTTL),
+	}
+	if err := s.db.Create(&challenge).Error; err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	return dto.LoginResponse{
+		ID:               user.ID,
+		SutId:            user.SutId,
+		ChallengeToken:   raw,
+		MFARequired:      enabled,
+		MFASetupRequired: !enabled,
+		ExpiresIn:        int(MFAChallengeTTL.Seconds()),
+	}, nil
+}
+
+// loadChallenge หา challenge ที่ยังใช้ได้และผู้ใช้เจ้าของ (lock=true สำหรับขั้นตอนที่จะเปลี่ยนสถานะ)
+func (s *MFAService) loadChallenge(tx *gorm.DB, raw string, lock bool) (*entity.AccountToken, *entity.User, error) {
+	query := tx
+	if lock {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var challenge entity.AccountToken
+	if err := query.Where("token_hash = ?", config.HashToken(raw)).First(&challenge).Error; err != nil {
+		return nil, nil, ErrInvalidMFAChallenge
+	}
+	if CheckAccountToken(&challenge, entity.TokenPurposeMFAChallenge, time.Now()) != nil ||
+		challenge.Attempts >= MFAMaxAttempts {
+		return nil, nil, ErrInvalidMFAChallenge
+	}
+
+	var user entity.User
+	if err := tx.Preload("Role").First(&user, challenge.UserID).Error; err != nil || user.DeactivatedAt != nil {
+		return nil, nil, ErrInvalidMFAChallenge
+	}
+	return &challenge, &user, nil
+}
+
+// failChallenge นับรหัสผิด ครบ MFAMaxAttempts แล้ว challenge ใช้ต่อไม่ได้ และนับรวมกับการล็อกบัญชี
+func (s *MFAService) failChallenge(tx *gorm.DB, challenge *entity.AccountToken, user *entity.User, ip string) error {
+	challenge.Attempts++
+	if challenge.Attempts >= MFAMaxAttempts {
+		now := time.Now()
+		challenge.UsedAt = &now
+	}
+	s.guard.RecordFailure(user.SutId, ip)
+	return tx.Save(challenge).Error
+}
+
+func (s *MFAService) completeChallenge(tx *gorm.DB, challenge *entity.AccountToken) error {
+	now := time.Now()
+	challenge.UsedAt = &now
+	return tx.Save(challenge).Error
+}
+
+// verifyCode ตรวจรหัส TOTP หรือรหัสสำรอง (รหัสสำรองใช้ได้ครั้งเดียว)
+func (s *MFAService) verifyCode(tx *gorm.DB, user *entity.User, code string) (bool, error) {
+	if step, ok := config.ValidateTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep); ok {
+		user.TOTPLastStep = step
+		return true, tx.Model(user).Update("totp_last_step", step).Error
+	}
+
+	result := tx.Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// VerifyChallenge ขั้นที่สองของการเข้าสู่ระบบสำหรับผู้ที่เปิดใช้ 2FA แล้ว
+func (s *MFAService) VerifyChallenge(raw, code, userAgent, ip string) (dto.LoginResponse, error) {
+	var user *entity.User
+	var codeErr error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		challenge, u, err := s.loadChallenge(tx, raw, true)
+		if err != nil {
+			return err
+		}
+		user = u
+		if user.TOTPEnabledAt == nil {
+			return ErrInvalidMFAChallenge
+		}
+
+		ok, err := s.verifyCode(tx, user, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// commit จำนวนครั้งที่ผิดไว้ แล้วค่อยคืน error หลัง transaction
+			codeErr = ErrInvalidMFACode
+			return s.failChallenge(tx, challenge, user, ip)
+		}
+		return s.completeChallenge(tx, challenge)
+	})
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+	if codeErr != nil {
+		return dto.LoginResponse{}, codeErr
+	}
+
+	s.guard.RecordSuccess(user.SutId)
+	tokens, err := s.sessions.CreateSession(user, userAgent, ip)
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+	return newLoginResponse(user, tokens), nil
+}
+
+// Setup เริ่มลงทะเบียน 2FA: สร้าง secret ใหม่ (ยังไม่เปิดใช้จนกว่าจะ Enable ด้วยรหัสที่ถูกต้อง)
+func (s *MFAService) Setup(userID uint) (dto.MFASetupResponse, error) {
+	var user entity.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return dto.MFASetupResponse{}, errors.New("ไม่พบผู้ใช้")
+	}
+	return s.setup(s.db, &user)
+}
+
+// SetupForChallenge ลงทะเบียน 2FA ระหว่างเข้าสู่ระบบ (ผู้ดูแลระบบที่ยังไม่เคยตั้งค่า)
+func (s *MFAService) SetupForChallenge(raw string) (dto.MFASetupResponse, error) {
+	_, user, err := s.loadChallenge(s.db, raw, false)
+	if err != nil {
+		return dto.MFASetupResponse{}, err
+	}
+	return s.setup(s.db, user)
+}
+
+func (s *MFAService) setup(tx *gorm.DB, user *entity.User) (dto.MFASetupResponse, error) {
+	if user.TOTPEnabledAt != nil {
+		return dto.MFASetupResponse{}, ErrMFAAlreadyEnabled
+	}
+	secret, err := config.GenerateTOTPSecret()
+	if err != nil {
+		return dto.MFASetupResponse{}, err
+	}
+	if err := tx.Model(user).Update("totp_secret", secret).Error; err != nil {
+		return dto.MFASetupResponse{}, err
+	}
+
+	uri := config.TOTPProvisioningURI(secret, user.SutId, totpIssuer)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return dto.MFASetupResponse{}, err
+	}
+	return dto.MFASetupResponse{
+		Secret:     secret,
+		OTPAuthURI: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// enable เปิดใช้ 2FA เมื่อรหัสจากแอปตรงกับ secret ที่ตั้งไว้ แล้วออกรหัสสำรองชุดใหม่
+func (s *MFAService) enable(tx *gorm.DB, user *entity.User, code string) ([]string, bool, error) {
+	if user.TOTPEnabledAt != nil {
+		return nil, false, ErrMFAAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, false, ErrMFASetupNotStarted
+	}
+	step, ok := config.ValidateTOTP(user.TOTPSecret, code, time.Now(), 0)
+	if !ok {
+		return nil, false, nil
+	}
+
+	now := time.Now()
+	if err := tx.Model(user).Updates(map[string]interface{}{
+		"totp_enabled_at": now,
+		"totp_last_step":  step,
+	}).Error; err != nil {
+		return nil, false, err
+	}
+	codes, err := s.replaceRecoveryCodes(tx, user.ID)
+	return codes, true, err
+}
+
+// Enable เปิดใช้ 2FA สำหรับผู้ใช้ที่เข้าสู่ระบบอยู่
+func (s *MFAService) Enable(userID uint, code string) ([]string, error) {
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user entity.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return errors.New("ไม่พบผู้ใช้")
+		}
+		var ok bool
+		var err error
+		codes, ok, err = s.enable(tx, &user, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		return nil
+	})
+	return codes, err
+}
+
+// EnableForChallenge เปิดใช้ 2FA ระหว่างเข้าสู่ระบบ แล้วออก session ให้เลย
+func (s *MFAService) EnableForChallenge(raw, code, userAgent, ip string) (dto.LoginResponse, []string, error) {
+	var user *entity.User
+	var codes []string
+	var codeErr error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		challenge, u, err := s.loadChallenge(tx, raw, true)
+		if err != nil {
+			return err
+		}
+		user = u
+
+		var ok bool
+		codes, ok, err = s.enable(tx, user, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			codeErr = ErrInvalidMFACode
+			return s.failChallenge(tx, challenge, user, ip)
+		}
+		return s.completeChallenge(tx, challenge)
+	})
+	if err != nil {
+		return dto.LoginResponse{}, nil, err
+	}
+	if codeErr != nil {
+		return dto.LoginResponse{}, nil, codeErr
+	}
+
+	s.guard.RecordSuccess(user.SutId)
+	tokens, err := s.sessions.CreateSession(user, userAgent, ip)
+	if err != nil {
+		return dto.LoginResponse{}, nil, err
+	}
+	return newLoginResponse(user, tokens), codes, nil
+}
+
+// Disable ปิด 2FA (ต้องยืนยันด้วยรหัส TOTP หรือรหัสสำรอง) ผู้ดูแลระบบปิดไม่ได้
+func (s *MFAService) Disable(userID uint, code string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var user entity.User
+		if err := tx.Preload("Role").First(&user, userID).Error; err != nil {
+			return errors.New("ไม่พบผู้ใช้")
+		}
+		if MFARequired(&user) {
+			return ErrMFARequiredForRole
+		}
+		if user.TOTPEnabledAt == nil {
+			return ErrMFANotEnabled
+		}
+		ok, err := s.verifyCode(tx, &user, code)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidMFACode
+		}
+
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"totp_secret":     "",
+			"totp_enabled_at": nil,
+			"totp_last_step":  0,
+		}).Error
+	})
+}
+
+// RegenerateRecoveryCodes ออกรหัสสำรองชุดใหม่ (ชุดเดิมใช้ไม่ได้อีก) ต้องยืนยันด้วยรหัส TOTP
+func (s *MFAService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user entity.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return errors.New("ไม่พบผู้ใช้")
+		}
+		if user.TOTPEnabledAt == nil {
+			return ErrMFANotEnabled
+		}
+		step, ok := config.ValidateTOTP(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		if err := tx.Model(&user).Update("totp_last_step", step).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	return codes, err
+}
+
+// Status สถานะ 2FA ของผู้ใช้
+func (s *MFAService) Status(userID uint) (dto.MFAStatusResponse, error) {
+	var user entity.User
+	if err := s.db.Preload("Role").First(&user, userID).Error; err != nil {
+		return dto.MFAStatusResponse{}, errors.New("ไม่พบผู้ใช้")
+	}
+	var remaining int64
+	s.db.Model(&entity.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", user.ID).Count(&remaining)
+
+	return dto.MFAStatusResponse{
+		Enabled:                user.TOTPEnabledAt != nil,
+		Required:               MFARequired(&user),
+		RecoveryCodesRemaining: int(remaining),
+	}, nil
+}
+
+func (s *MFAService) replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		if err := tx.Create(&entity.RecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(code)}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return codes, nil
+}
+
+// GenerateRecoveryCodes สร้างรหัสสำรองรูปแบบ xxxxx-xxxxx (base32 ตัวพิมพ์เล็ก)
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		secret, err := config.GenerateTOTPSecret()
+		if err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(secret[:10])
+		codes = append(codes, code[:5]+"-"+code[5:])
+	}
+	return codes, nil
+}
+
+// hashRecoveryCode ไม่สนตัวพิมพ์ ช่องว่าง และขีด เพื่อให้พิมพ์รหัสได้ง่าย
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	return config.HashToken(normalized)
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+var (
+	ErrOIDCDisabled     = errors.New("ระบบยังไม่ได้เปิดใช้การเข้าสู่ระบบด้วยบัญชีมหาวิทยาลัย")
+	ErrOIDCInvalidState = errors.New("คำขอเข้าสู่ระบบหมดอายุหรือไม่ถูกต้อง กรุณาลองใหม่")
+)
+
+var oidcSutIdPattern = regexp.MustCompile(`^[BMD]\d{7}$`)
+
+// DefaultOIDCEmailDomain โดเมนอีเมลมหาวิทยาลัยที่ยอมรับเมื่อหา SutId จาก claim "email"
+const DefaultOIDCEmailDomain = "g.sut.ac.th"
+
+// OIDCConfig ค่าตั้งของ OpenID Connect provider
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	SutIdClaim   string // claim ที่ใช้หา SutId: "email" (ค่าเริ่มต้น) หรือ "sub"
+	EmailDomain  string // โดเมนอีเมลที่ยอมรับเมื่อใช้ claim "email" (ค่าเริ่มต้น g.sut.ac.th)
+}
+
+// OIDCConfigFromEnv อ่านค่าจาก Env ถ้าเปิด OIDC_MOCK และไม่ได้กำหนด issuer จะใช้ IdP จำลองใน backend
+func OIDCConfigFromEnv() OIDCConfig {
+	cfg := OIDCConfig{
+		Issuer:       config.Env.OIDCIssuer,
+		ClientID:     config.Env.OIDCClientID,
+		ClientSecret: config.Env.OIDCClientSecret,
+		RedirectURL:  config.Env.OIDCRedirectURL,
+		SutIdClaim:   config.Env.OIDCSutIdClaim,
+		EmailDomain:  config.Env.OIDCEmailDomain,
+	}
+	if cfg.Issuer == "" && config.Env.OIDCMock {
+		cfg.Issuer = MockIdPIssuer()
+		if cfg.ClientID == "" {
+			cfg.ClientID = "engiconnect-dev"
+		}
+	}
+	if cfg.RedirectURL == "" {
+		cfg.RedirectURL = apiBaseURL() + "/api/auth/oidc/callback"
+	}
+	if cfg.SutIdClaim == "" {
+		cfg.SutIdClaim = "email"
+	}
+	return cfg
+}
+
+// MockIdPIssuer URL ของ IdP จำลองที่ mount ไว้ที่ /api/mock-idp
+func MockIdPIssuer() string {
+	return apiBaseURL() + "/api/mock-idp"
+}
+
+// OIDCIdentity ข้อมูลผู้ใช้จาก id_token ที่ตรวจสอบแล้ว
+type OIDCIdentity struct {
+	Issuer    string
+	Subject   string
+	Email     string
+	FirstName string
+	LastName  string
+	SutId     string
+}
+
+type OIDCService struct {
+	db  *gorm.DB
+	mfa *MFAService
+	cfg OIDCConfig
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOIDCService(db *gorm.DB, mfa *MFAService, cfg OIDCConfig) *OIDCService {
+	if cfg.EmailDomain == "" {
+		cfg.EmailDomain = DefaultOIDCEmailDomain
+	}
+	return &OIDCService{db: db, mfa: mfa, cfg: cfg}
+}
+
+func (s *OIDCService) Enabled() bool {
+	return s.cfg.Issuer != "" && s.cfg.ClientID != ""
+}
+
+// oauthConfig โหลด discovery document ครั้งแรกที่ใช้งาน (ไม่ทำตอนเริ่มโปรแกรมเพราะ IdP จำลองอยู่ใน server เดียวกัน)
+func (s *OIDCService) oauthConfig(ctx context.Context) (*oauth2.Config, *oidc.Provider, error) {
+	if !s.Enabled() {
+		return nil, nil, ErrOIDCDisabled
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.provider == nil {
+		provider, err := oidc.NewProvider(ctx, s.cfg.Issuer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ไม่สามารถเชื่อมต่อผู้ให้บริการยืนยันตัวตนได้: %w", err)
+		}
+		s.provider = provider
+	}
+
+	return &oauth2.Config{
+		ClientID:     s.cfg.ClientID,
+		ClientSecret: s.cfg.ClientSecret,
+		RedirectURL:  s.cfg.RedirectURL,
+		Endpoint:     s.provider.Endpoint(),
+		Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+	}, s.provider, nil
+}
+
+// AuthCodeURL สร้างลิงก์ไปหน้า login ของ provider พร้อม state, nonce และ PKCE challenge
+func (s *OIDCService) AuthCodeURL(ctx context.Context) (string, string, error) {
+	conf, _, err := s.oauthConfig(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := config.GenerateSecureToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := config.GenerateSecureToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	record := entity.OIDCLoginState{
+		StateHash:    config.HashToken(state),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	}
+	if err := s.db.Create(&record).Error; err != nil {
+		return "", "", err
+	}
+	// ลบคำขอที่หมดอายุแล้ว (ผู้ใช้กดเข้าสู่ระบบแต่ไม่กลับมาที่ callback)
+	s.db.Unscoped().Where("expires_at < ?", time.Now()).Delete(&entity.OIDCLoginState{})
+
+	return conf.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), state, nil
+}
+
+// Exchange แลก code เป็น token แล้วตรวจ id_token (ลายเซ็น, audience, nonce)
+func (s *OIDCService) Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error) {
+	conf, provider, err := s.oauthConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := conf.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("แลกเปลี่ยน authorization code ไม่สำเร็จ: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("ผู้ให้บริการไม่ได้ส่ง id_token กลับมา")
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: s.cfg.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("id_token ไม่ถูกต้อง: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("id_token ไม่ถูกต้อง: nonce ไม่ตรงกัน")
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified *bool  `json:"email_verified"`
+		GivenName     string `json:"given_name"`
+		FamilyName    string `json:"family_name"`
+		Name          string `json:"name"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		return nil, errors.New("อีเมลของบัญชีนี้ยังไม่ได้รับการยืนยันจากผู้ให้บริการ")
+	}
+
+	var sutId string
+	if s.cfg.SutIdClaim == "sub" {
+		sutId, err = SutIdFromClaim(idToken.Subject)
+	} else {
+		sutId, err = SutIdFromEmail(claims.Email, s.cfg.EmailDomain)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && claims.Name != "" {
+		parts := strings.SplitN(claims.Name, " ", 2)
+		firstName = parts[0]
+		if len(parts) > 1 {
+			lastName = parts[1]
+		}
+	}
+
+	return &OIDCIdentity{
+		Issuer:    idToken.Issuer,
+		Subject:   idToken.Subject,
+		Email:     strings.ToLower(claims.Email),
+		FirstName: firstName,
+		LastName:  lastName,
+		SutId:     sutId,
+	}, nil
+}
+
+// HandleCallback ตรวจ state, แลก code, หา/สร้างบัญชี แล้วเข้าสู่ระบบเหมือนการใช้รหัสผ่าน
+func (s *OIDCService) HandleCallback(ctx context.Context, code, state, userAgent, ip string) (dto.LoginResponse, error) {
+	var record entity.OIDCLoginState
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("state_hash = ?", config.HashToken(state)).
+			First(&record).Error; err != nil {
+			return ErrOIDCInvalidState
+		}
+		if err := tx.Unscoped().Delete(&record).Error; err != nil {
+			return err
+		}
+		if !time.Now().Before(record.ExpiresAt) {
+			return ErrOIDCInvalidState
+		}
+		return nil
+	})
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	identity, err := s.Exchange(ctx, code, record.CodeVerifier, record.Nonce)
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	user, err := s.findOrProvisionUser(identity)
+	if err != nil {
+		return dto.LoginResponse{}, err
+	}
+
+	// SSO ไม่ใช้รหัสผ่านของระบบ จึงตรวจเฉพาะการระงับบัญชี ไม่ตรวจการบังคับรีเซ็ตรหัสผ่าน
+	if user.DeactivatedAt != nil {
+		return dto.LoginResponse{}, ErrAccountDeactivated
+	}
+
+	// ผู้ดูแลระบบ/ผู้ที่เปิด 2FA ต้องยืนยันขั้นที่สองเหมือนเข้าสู่ระบบด้วยรหัสผ่าน
+	res, err := s.mfa.StartLogin(user, userAgent, ip)
+	if err != nil {
+		return dto.LoginResponse{}, fmt.Errorf("ไม่สามารถสร้าง token ได้: %w", err)
+	}
+	return res, nil
+}
+
+// findOrProvisionUser หาผู้ใช้จาก identity ที่เคยเชื่อมไว้ จากนั้นจาก SutId ถ้าไม่พบจะสร้างบัญชีใหม่
+func (s *OIDCService) findOrProvisionUser(identity *OIDCIdentity) (*entity.User, error) {
+	var user entity.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var link entity.UserIdentity
+		if err := tx.Where("issuer = ? AND subject = ?", identity.Issuer, identity.Subject).First(&link).Error; err == nil {
+			return tx.Preload("Role").First(&user, link.UserID).Error
+		}
+
+		err := tx.Preload("Role").Where("sut_id = ?", identity.SutId).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := s.provisionUser(tx, identity, &user); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		if user.EmailVerifiedAt == nil {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+			if err := tx.Model(&user).Update("email_verified_at", now).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&entity.UserIdentity{
+			UserID:  user.ID,
+			Issuer:  identity.Issuer,
+			Subject: identity.Subject,
+			Email:   identity.Email,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *OIDCService) provisionUser(tx *gorm.DB, identity *OIDCIdentity, user *entity.User) error {
+	var role entity.Role
+	if err := tx.Where("name = ?", entity.RoleStudent).First(&role).Error; err != nil {
+		return errors.New("ไม่พบข้อมูลบทบาท student")
+	}
+	var faculty entity.Faculty
+	if err := tx.Order("id").First(&faculty).Error; err != nil {
+		return errors.New("ไม่พบข้อมูลคณะ")
+	}
+	var major entity.Major
+	if err := tx.Where("name = ?", "ยังไม่สังกัดสาขา").First(&major).Error; err != nil {
+		return errors.New("ไม่พบข้อมูลสาขา")
+	}
+
+	// บัญชีที่สร้างจาก SSO ไม่มีรหัสผ่านที่ใช้ได้ ตั้งรหัสผ่านได้ภายหลังผ่านลืมรหัสผ่าน
+	randomPassword, err := config.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+	hashed, err := config.HashPassword(randomPassword)
+	if err != nil {
+		return err
+	}
+
+	email := identity.Email
+	if email == "" {
+		email = strings.ToLower(identity.SutId) + "@g.sut.ac.th"
+	}
+	now := time.Now()
+	*user = entity.User{
+		SutId:           identity.SutId,
+		Email:           email,
+		Password:        hashed,
+		FirstName:       identity.FirstName,
+		LastName:        identity.LastName,
+		FacultyID:       faculty.ID,
+		MajorID:         major.ID,
+		Year:            YearFromSutId(identity.SutId, now),
+		RoleID:          role.ID,
+		EmailVerifiedAt: &now,
+	}
+	if err := tx.Create(user).Error; err != nil {
+		return fmt.Errorf("ไม่สามารถสร้างบัญชีได้: %w", err)
+	}
+	grantWelcomePoints(tx, user.ID)
+	user.Role = &role
+	return nil
+}
+
+// SutIdFromClaim แปลงค่า claim (เช่น b6614690@g.sut.ac.th หรือ B6614690) เป็นรหัสนักศึกษา
+func SutIdFromClaim(value string) (string, error) {
+	local := value
+	if at := strings.Index(local, "@"); at >= 0 {
+		local = local[:at]
+	}
+	sutId := strings.ToUpper(strings.TrimSpace(local))
+	if !oidcSutIdPattern.MatchString(sutId) {
+		return "", fmt.Errorf("ไม่สามารถหารหัสนักศึกษาจากบัญชี %q ได้", value)
+	}
+	return sutId, nil
+}
+
+// SutIdFromEmail หา SutId จากอีเมล ยอมรับเฉพาะอีเมลในโดเมนของมหาวิทยาลัย
+// กันไม่ให้บัญชีจากโดเมนอื่นที่ตั้งชื่อเหมือนรหัสนักศึกษาเข้าสู่ระบบแทนนักศึกษาได้
+func SutIdFromEmail(email, domain string) (string, error) {
+	email = strings.TrimSpace(email)
+	at := strings.LastIndex(email, "@")
+	if at < 0 || !strings.EqualFold(email[at+1:], domain) {
+		return "", fmt.Errorf("บัญชี %q ไม่ใช่อีเมลของมหาวิทยาลัย (@%s)", email, domain)
+	}
+	return SutIdFromClaim(email[:at])
+}
+
+// YearFromSutId คำนวณชั้นปีจากปีที่เข้าศึกษาในรหัสนักศึกษา (B66xxxxx = เข้าปี 2566) ปีการศึกษาเริ่มเดือนสิงหาคม
+func YearFromSutId(sutId string, now time.Time) uint {
+	if len(sutId) < 3 {
+		return 1
+	}
+	var entry int
+	if _, err := fmt.Sscanf(sutId[1:3], "%d", &entry); err != nil {
+		return 1
+	}
+	academicYear := now.Year() + 543
+	if now.Month() < time.August {
+		academicYear--
+	}
+	year := academicYear - (2500 + entry) + 1
+	if year < 1 {
+		return 1
+	}
+	if year > 8 {
+		return 8
+	}
+	return uint(year)
+}
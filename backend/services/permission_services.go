@@ -0,0 +1,146 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+var ErrUnknownPermission = errors.New("ไม่พบสิทธิ์ที่ระบุ")
+
+// rolePermissions แผนที่ role → สิทธิ์ เก็บในหน่วยความจำให้ middleware ตรวจได้โดยไม่ต้อง query DB
+// เริ่มจาก entity.DefaultRolePermissions และถูกแทนที่ด้วยข้อมูลจริงใน DB ตอนเริ่มเซิร์ฟเวอร์
+var rolePermissions = struct {
+	sync.RWMutex
+	byRole map[string]map[string]bool
+}{byRole: permissionSets(entity.DefaultRolePermissions)}
+
+func permissionSets(mapping map[string][]string) map[string]map[string]bool {
+	sets := make(map[string]map[string]bool, len(mapping))
+	for role, names := range mapping {
+		set := make(map[string]bool, len(names))
+		for _, name := range names {
+			set[name] = true
+		}
+		sets[role] = set
+	}
+	return sets
+}
+
+// SetRolePermissions แทนที่แผนที่สิทธิ์ทั้งหมดในหน่วยความจำ
+func SetRolePermissions(mapping map[string][]string) {
+	sets := permissionSets(mapping)
+	rolePermissions.Lock()
+	defer rolePermissions.Unlock()
+	rolePermissions.byRole = sets
+}
+
+// RoleHasPermission ตรวจว่า role มีสิทธิ์ตามชื่อที่ระบุหรือไม่
+func RoleHasPermission(role, permission string) bool {
+	rolePermissions.RLock()
+	defer rolePermissions.RUnlock()
+	return rolePermissions.byRole[role][permission]
+}
+
+// LoadRolePermissions โหลดแผนที่สิทธิ์จาก DB (เรียกตอนเริ่มเซิร์ฟเวอร์และหลังแก้ไขสิทธิ์)
+func LoadRolePermissions(db *gorm.DB) {
+	var roles []entity.Role
+	if err := db.Preload("Permissions").Find(&roles).Error; err != nil {
+		log.Printf("Error loading role permissions: %v", err)
+		return
+	}
+	mapping := make(map[string][]string, len(roles))
+	for _, role := range roles {
+		names := make([]string, 0, len(role.Permissions))
+		for _, permission := range role.Permissions {
+			names = append(names, permission.Name)
+		}
+		mapping[role.Name] = names
+	}
+	SetRolePermissions(mapping)
+}
+
+// IsPostOwner ตรวจว่าผู้ใช้เป็นผู้สร้างกิจกรรม ใช้กับสิทธิ์ที่จำกัดเฉพาะกิจกรรมของตนเอง
+func IsPostOwner(db *gorm.DB, postID, userID uint) bool {
+	var count int64
+	db.Model(&entity.Post{}).Where("id = ? AND user_id = ?", postID, userID).Count(&count)
+	return count > 0
+}
+
+type PermissionService struct {
+	db *gorm.DB
+}
+
+func NewPermissionService(db *gorm.DB) *PermissionService {
+	return &PermissionService{db: db}
+}
+
+func (s *PermissionService) ListPermissions() ([]entity.Permission, error) {
+	var permissions []entity.Permission
+	if err := s.db.Order("name").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// UpdateRolePermissions กำหนดสิทธิ์ทั้งหมดของ role ใหม่ และมีผลทันทีกับ token ที่ออกไปแล้ว
+func (s *PermissionService) UpdateRolePermissions(roleID uint, names []string, actorID uint, ip string) (*entity.Role, error) {
+	var role entity.Role
+	if err := s.db.Preload("Permissions").First(&role, roleID).Error; err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	var permissions []entity.Permission
+	if len(names) > 0 {
+		if err := s.db.Where("name IN ?", names).Find(&permissions).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(permissions) != len(uniqueStrings(names)) {
+		return nil, ErrUnknownPermission
+	}
+
+	before := permissionNames(role.Permissions)
+	if err := s.db.Model(&role).Association("Permissions").Replace(permissions); err != nil {
+		return nil, err
+	}
+	role.Permissions = permissions
+	LoadRolePermissions(s.db)
+
+	RecordAudit(s.db, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionRolePermissionsChanged,
+		TargetType: "role",
+		TargetID:   strconv.Itoa(int(role.ID)),
+		IPAddress:  ip,
+		Detail:     before + " -> " + permissionNames(permissions),
+	})
+	return &role, nil
+}
+
+func permissionNames(permissions []entity.Permission) string {
+	names := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		names = append(names, permission.Name)
+	}
+	sort.Strings(names)
+	return "[" + strings.Join(names, ",") + "]"
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/sut68/team21/entity"
@@ -200,13 +202,25 @@ func (s *PointService) GetPostsWithPoints() ([]entity.Post, error) {
 }
 
 // อัปเดตคะแนนกิจกรรม (PostPoint)
-func (s *PointService) UpdatePostPoint(postId uint, point uint) error {
+func (s *PointService) UpdatePostPoint(postId uint, point uint, actorID uint, ip string) error {
 	var post entity.Post
 	if err := s.DB.First(&post, postId).Error; err != nil {
 		return err
 	}
+	before := post
 	post.PostPoint = point
-	return s.DB.Save(&post).Error
+	if err := s.DB.Save(&post).Error; err != nil {
+		return err
+	}
+
+	RecordChange(s.DB, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionPostPointChanged,
+		TargetType: "post",
+		TargetID:   strconv.Itoa(int(post.ID)),
+		IPAddress:  ip,
+	}, before, post)
+	return nil
 }
 
 // RedeemRewardService สำหรับแลกรางวัล
@@ -281,12 +295,28 @@ func (s *PointService) GetRedeemedRewardIDsByUserID(userID uint) ([]uint, error)
 }
 
 // DeleteReward deletes a reward by ID (soft delete)
-func (s *PointService) DeleteReward(rewardID uint) error {
-	return s.DB.Delete(&entity.Reward{}, rewardID).Error
+func (s *PointService) DeleteReward(rewardID uint, actorID uint, ip string) error {
+	var reward entity.Reward
+	if err := s.DB.First(&reward, rewardID).Error; err != nil {
+		return err
+	}
+	if err := s.DB.Delete(&reward).Error; err != nil {
+		return err
+	}
+
+	RecordChange(s.DB, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionRewardDeleted,
+		TargetType: "reward",
+		TargetID:   strconv.Itoa(int(reward.ID)),
+		IPAddress:  ip,
+		Detail:     reward.RewardName,
+	}, reward, nil)
+	return nil
 }
 
 // DistributePointsToParticipants แจกคะแนนให้ผู้เข้าร่วมกิจกรรม
-func (s *PointService) DistributePointsToParticipants(postID uint) error {
+func (s *PointService) DistributePointsToParticipants(postID uint, actorID uint, ip string) error {
 	// 1. Get activity with registrations and users
 	var post entity.Post
 	err := s.DB.Preload("Registrations.Users").
@@ -302,6 +332,7 @@ func (s *PointService) DistributePointsToParticipants(postID uint) error {
 	}
 
 	// 3. Loop through registrations
+	registrations, users := 0, 0
 	for _, reg := range post.Registrations {
 		// Check if already distributed to this registration
 		alreadyDistributed := false
@@ -315,6 +346,7 @@ func (s *PointService) DistributePointsToParticipants(postID uint) error {
 		if alreadyDistributed {
 			continue // Skip this registration
 		}
+		registrations++
 
 		// Distribute to all users in registration
 		for _, user := range reg.Users {
@@ -333,9 +365,18 @@ func (s *PointService) DistributePointsToParticipants(postID uint) error {
 			if err := s.AddPointsToUser(user.ID, int(post.PostPoint)); err != nil {
 				return err
 			}
+			users++
 		}
 	}
 
+	RecordChange(s.DB, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionPointsDistributed,
+		TargetType: "post",
+		TargetID:   strconv.Itoa(int(post.ID)),
+		IPAddress:  ip,
+		Detail:     fmt.Sprintf("แจก %d คะแนนให้ %d คน จาก %d ทีม", post.PostPoint, users, registrations),
+	}, nil, map[string]any{"post_point": post.PostPoint, "registrations": registrations, "users": users})
 	return nil
 }
 
@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/sut68/team21/entity"
@@ -13,6 +14,7 @@ type PortfolioService interface {
 	GetPortfolioByID(id uint) (*entity.Portfolio, error)
 	GetPortfoliosByUserID(userID uint) ([]entity.Portfolio, error)
 	UpdatePortfolio(id uint, data interface{}) error
+	ReviewPortfolio(id uint, data map[string]interface{}, actorID uint, ip string) error
 	DeletePortfolio(id uint) error
 	GetAllPortfolios() ([]entity.Portfolio, error)
 
@@ -73,6 +75,44 @@ func (s *portfolioService) UpdatePortfolio(id uint, data interface{}) error {
 	return nil
 }
 
+// ReviewPortfolio แก้ไขผลงานโดยผู้ตรวจ ถ้าสถานะหรือความเห็นเปลี่ยนจะบันทึกลง audit log
+func (s *portfolioService) ReviewPortfolio(id uint, data map[string]interface{}, actorID uint, ip string) error {
+	var before entity.Portfolio
+	if err := s.db.First(&before, id).Error; err != nil {
+		return err
+	}
+	if err := s.UpdatePortfolio(id, data); err != nil {
+		return err
+	}
+	var after entity.Portfolio
+	if err := s.db.First(&after, id).Error; err != nil {
+		return err
+	}
+
+	if before.PortfolioStatusID != after.PortfolioStatusID || !equalStringPtr(before.AdminComment, after.AdminComment) {
+		RecordChange(s.db, entity.AuditLog{
+			ActorID:    &actorID,
+			Action:     entity.AuditActionPortfolioReviewed,
+			TargetType: "portfolio",
+			TargetID:   strconv.Itoa(int(id)),
+			IPAddress:  ip,
+		}, portfolioReview(before), portfolioReview(after))
+	}
+	return nil
+}
+
+// portfolioReview ฟิลด์ที่ผู้ตรวจเปลี่ยนได้ ใช้เทียบก่อน/หลังใน audit log
+func portfolioReview(p entity.Portfolio) map[string]any {
+	return map[string]any{"portfolio_status_id": p.PortfolioStatusID, "admin_comment": p.AdminComment}
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
 func (s *portfolioService) DeletePortfolio(id uint) error {
 	return s.db.Delete(&entity.Portfolio{}, id).Error
 }
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+// PostSchedulerInterval ความถี่ที่ scheduler ตรวจและขยับสถานะโพสต์ตามเวลา
+const PostSchedulerInterval = time.Minute
+
+var ErrInvalidPostStatusTransition = errors.New("invalid post status transition")
+
+func postStatusName(id uint) string {
+	if name, ok := entity.PostStatusNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+func postTransitionError(from, to uint) error {
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidPostStatusTransition, postStatusName(from), postStatusName(to))
+}
+
+// PostStatusAt สถานะตามเวลาของโพสต์ที่อนุมัติแล้ว: ก่อนวันเริ่ม = Upcoming, ระหว่างกิจกรรม = Active, หลังวันสิ้นสุด = Ended
+func PostStatusAt(post entity.Post, now time.Time) uint {
+	switch {
+	case now.Before(post.StartDate):
+		return entity.PostStatusUpcoming
+	case !now.After(post.StopDate):
+		return entity.PostStatusActive
+	default:
+		return entity.PostStatusEnded
+	}
+}
+
+// ScheduledPostStatus คืนสถานะที่โพสต์ควรเป็น ณ เวลา now และ true ถ้าต้องเปลี่ยน
+// สถานะที่ไม่ได้ขึ้นกับเวลา (Pending, Rejected, Ended) และการถอยสถานะจะไม่ถูกเปลี่ยน
+func ScheduledPostStatus(current uint, post entity.Post, now time.Time) (uint, bool) {
+	if !entity.IsScheduledPostStatus(current) {
+		return current, false
+	}
+	next := PostStatusAt(post, now)
+	if next == current || !entity.CanTransitionPostStatus(current, next) {
+		return current, false
+	}
+	return next, true
+}
+
+// AdvancePostStatuses ขยับสถานะโพสต์ทั้งหมดที่ถึงเวลาแล้ว คืนจำนวนโพสต์ที่เปลี่ยน
+func AdvancePostStatuses(db *gorm.DB, now time.Time) (int, error) {
+	var posts []entity.Post
+	if err := db.Select("id", "status_id", "start_date", "stop_date").
+		Where("status_id IN ?", []uint{entity.PostStatusApproved, entity.PostStatusUpcoming, entity.PostStatusActive}).
+		Find(&posts).Error; err != nil {
+		return 0, err
+	}
+
+	advanced := 0
+	for _, post := range posts {
+		if post.StatusID == nil {
+			continue
+		}
+		next, ok := ScheduledPostStatus(*post.StatusID, post, now)
+		if !ok {
+			continue
+		}
+		// เงื่อนไข status_id เดิมกันการเขียนทับถ้ามีคนเปลี่ยนสถานะไประหว่างนี้
+		result := db.Model(&entity.Post{}).
+			Where("id = ? AND status_id = ?", post.ID, *post.StatusID).
+			Update("status_id", next)
+		if result.Error != nil {
+			return advanced, result.Error
+		}
+		advanced += int(result.RowsAffected)
+	}
+	return advanced, nil
+}
+
+// RunPostScheduler ขยับสถานะโพสต์ตามเวลาเป็นระยะ (เรียกด้วย goroutine ตอนเริ่มเซิร์ฟเวอร์)
+func RunPostScheduler(db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := AdvancePostStatuses(db, time.Now()); err != nil {
+			log.Printf("post scheduler: %v", err)
+		} else if n > 0 {
+			log.Printf("post scheduler: advanced %d post(s)", n)
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPostPageSize = 20
+	MaxPostPageSize     = 200
+)
+
+var ErrInvalidPostSort = errors.New("รูปแบบการเรียงลำดับไม่ถูกต้อง")
+
+// postRegistrationCountSQL จำนวนทีมที่ใช้ที่นั่งของโพสต์ (สถานะเป็นค่าคงที่ในโค้ด จึงต่อเป็น SQL ได้)
+var postRegistrationCountSQL = "(SELECT COUNT(*) FROM registrations WHERE registrations.post_id = posts.id AND registrations.deleted_at IS NULL AND registrations.status IN ('" +
+	strings.Join(entity.ActiveRegistrationStatuses, "', '") + "'))"
+
+// postSortColumns ฟิลด์ที่ใช้เรียงรายการโพสต์ได้
+var postSortColumns = map[string]string{
+	"created_at":    "posts.created_at",
+	"start_date":    "posts.start_date",
+	"stop_date":     "posts.stop_date",
+	"start":         "posts.start",
+	"stop":          "posts.stop",
+	"title":         "posts.title",
+	"registrations": postRegistrationCountSQL,
+}
+
+// PostOrderClause แปลงค่า sort เช่น "-start_date" เป็น ORDER BY (ค่าว่างคือโพสต์ใหม่สุดก่อน)
+func PostOrderClause(sort string) (string, error) {
+	if sort == "" {
+		sort = "-created_at"
+	}
+	direction := "ASC"
+	if field, ok := strings.CutPrefix(sort, "-"); ok {
+		sort, direction = field, "DESC"
+	}
+	column, ok := postSortColumns[sort]
+	if !ok {
+		return "", ErrInvalidPostSort
+	}
+	return column + " " + direction + ", posts.id " + direction, nil
+}
+
+// IsRegistrationOpen ตรวจว่าโพสต์ที่เผยแพร่แล้วกำลังเปิดรับสมัครอยู่ ณ เวลา now
+func IsRegistrationOpen(post entity.Post, now time.Time) bool {
+	if post.StatusID == nil || !isStudentVisible(*post.StatusID) {
+		return false
+	}
+	return !now.Before(post.Start) && !now.After(post.Stop)
+}
+
+func isStudentVisible(status uint) bool {
+	for _, visible := range entity.StudentVisiblePostStatuses {
+		if status == visible {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *PostService) filteredPosts(query dto.PostListQuery, viewerID uint, manageAny bool, now time.Time) *gorm.DB {
+	tx := s.db.Model(&entity.Post{})
+	if !manageAny {
+		tx = tx.Where("posts.status_id IN ? OR posts.user_id = ?", entity.PublishedPostStatuses, viewerID)
+	}
+	if q := strings.TrimSpace(query.Q); q != "" {
+		tx = tx.Where("posts.title ILIKE ?", "%"+EscapeLike(q)+"%")
+	}
+	if query.Type != "" {
+		tx = tx.Where("posts.type = ?", query.Type)
+	}
+	if query.StatusID != 0 {
+		tx = tx.Where("posts.status_id = ?", query.StatusID)
+	}
+	if query.LocationID != 0 {
+		tx = tx.Where("posts.location_id = ?", query.LocationID)
+	}
+	if organizer := strings.TrimSpace(query.Organizer); organizer != "" {
+		tx = tx.Where("posts.organizer ILIKE ?", "%"+EscapeLike(organizer)+"%")
+	}
+	// ช่วงวันที่: กิจกรรมที่จัดคาบเกี่ยวกับช่วง from–to (นับรวมวันสุดท้าย)
+	if !query.From.IsZero() {
+		tx = tx.Where("posts.stop_date >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		tx = tx.Where("posts.start_date < ?", query.To.AddDate(0, 0, 1))
+	}
+	if query.Open {
+		tx = tx.Where("posts.status_id IN ? AND posts.start <= ? AND posts.stop >= ?", entity.StudentVisiblePostStatuses, now, now)
+	}
+	return tx
+}
+
+// ListPosts รายการโพสต์แบบแบ่งหน้า ผู้ที่ไม่มีสิทธิ์จัดการทุกกิจกรรมเห็นเฉพาะโพสต์ที่เผยแพร่แล้วและโพสต์ของตนเอง
+func (s *PostService) ListPosts(query dto.PostListQuery, viewerID uint, manageAny bool) (*dto.PostListPage, error) {
+	order, err := PostOrderClause(query.Sort)
+	if err != nil {
+		return nil, err
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = DefaultPostPageSize
+	}
+	if query.Limit > MaxPostPageSize {
+		query.Limit = MaxPostPageSize
+	}
+	now := time.Now()
+
+	var total int64
+	if err := s.filteredPosts(query, viewerID, manageAny, now).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var posts []entity.Post
+	err = s.filteredPosts(query, viewerID, manageAny, now).
+		Preload("Status").
+		Preload("Location", func(db *gorm.DB) *gorm.DB { return db.Select("id", "building") }).
+		Preload("User", func(db *gorm.DB) *gorm.DB { return db.Select("id", "first_name", "last_name", "role_id") }).
+		Preload("User.Role").
+		Order(order).
+		Offset((query.Page - 1) * query.Limit).
+		Limit(query.Limit).
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	usage, err := capacityUsage(s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.PostListItem, 0, len(posts))
+	for _, post := range posts {
+		post.Capacity = CapacityOf(post, usage[post.ID])
+		items = append(items, PostListItemOf(post, now))
+	}
+	return &dto.PostListPage{Data: items, Total: total, Page: query.Page, Limit: query.Limit}, nil
+}
+
+// PostListItemOf แปลงโพสต์ (ที่ preload Status/Location/User และคำนวณ Capacity แล้ว) เป็นข้อมูลสำหรับหน้ารายการ
+func PostListItemOf(post entity.Post, now time.Time) dto.PostListItem {
+	item := dto.PostListItem{
+		ID:               post.ID,
+		CreatedAt:        post.CreatedAt,
+		Title:            post.Title,
+		Detail:           post.Detail,
+		Picture:          post.Picture,
+		Type:             post.Type,
+		Organizer:        post.Organizer,
+		StartDate:        post.StartDate,
+		StopDate:         post.StopDate,
+		Start:            post.Start,
+		Stop:             post.Stop,
+		Comment:          post.Comment,
+		PostPoint:        post.PostPoint,
+		UserID:           post.UserID,
+		StatusID:         post.StatusID,
+		LocationID:       post.LocationID,
+		MaxTeams:         post.MaxTeams,
+		MinTeamSize:      post.MinTeamSize,
+		MaxTeamSize:      post.MaxTeamSize,
+		MaxSeats:         post.MaxSeats,
+		Capacity:         post.Capacity,
+		RegistrationOpen: IsRegistrationOpen(post, now),
+	}
+	if post.Capacity != nil {
+		item.RegistrationCount = post.Capacity.Teams
+	}
+	if post.Status != nil {
+		item.Status = &dto.PostStatusSummary{ID: post.Status.ID, StatusName: post.Status.StatusName}
+	}
+	if post.Location != nil {
+		item.Location = &dto.PostLocationSummary{ID: post.Location.ID, Building: post.Location.Building}
+	}
+	if post.User != nil {
+		item.User = &dto.PostOwnerSummary{ID: post.User.ID, FirstName: post.User.FirstName, LastName: post.User.LastName}
+		if post.User.Role != nil {
+			item.User.Role = &dto.PostOwnerRole{Name: post.User.Role.Name}
+		}
+	}
+	return item
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPostNotFound         = errors.New("post not found")
+	ErrPostNotPending       = errors.New("โพสต์นี้ไม่ได้อยู่ในสถานะรอตรวจ")
+	ErrPostNotRejected      = errors.New("ส่งตรวจใหม่ได้เฉพาะโพสต์ที่ไม่ผ่านการอนุมัติ")
+	ErrPostLocked           = errors.New("โพสต์ที่อนุมัติแล้วแก้ไขได้เฉพาะผู้ดูแลกิจกรรม")
+	ErrRejectCommentMissing = errors.New("กรุณาระบุเหตุผลที่ไม่อนุมัติ")
+)
+
+func (s *PostService) findPost(id uint) (*entity.Post, error) {
+	var post entity.Post
+	if err := s.db.First(&post, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	return &post, nil
+}
+
+func currentPostStatus(post *entity.Post) uint {
+	if post.StatusID == nil {
+		return 0
+	}
+	return *post.StatusID
+}
+
+// SubmitPost ส่งโพสต์ที่ไม่ผ่านการอนุมัติกลับเข้าคิวตรวจอีกครั้ง (Rejected → Pending)
+func (s *PostService) SubmitPost(id, userID uint) (*entity.Post, error) {
+	post, err := s.findPost(id)
+	if err != nil {
+		return nil, err
+	}
+	if currentPostStatus(post) != entity.PostStatusRejected {
+		return nil, ErrPostNotRejected
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(post).Update("status_id", entity.PostStatusPending).Error; err != nil {
+			return err
+		}
+		return tx.Create(&entity.PostReview{PostID: post.ID, ActorID: userID, Action: entity.PostReviewSubmitted}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// ReviewPost อนุมัติหรือไม่อนุมัติโพสต์ที่รอตรวจ ความเห็นของผู้ตรวจเก็บใน Post.Comment
+// โพสต์ที่อนุมัติแล้วจะได้สถานะตามวันกิจกรรมทันที (Upcoming/Active/Ended)
+func (s *PostService) ReviewPost(id, reviewerID uint, approve bool, comment, ip string) (*entity.Post, error) {
+	post, err := s.findPost(id)
+	if err != nil {
+		return nil, err
+	}
+	from := currentPostStatus(post)
+	if from != entity.PostStatusPending {
+		return nil, ErrPostNotPending
+	}
+	comment = strings.TrimSpace(comment)
+
+	to, action := entity.PostStatusApproved, entity.PostReviewApproved
+	if !approve {
+		if comment == "" {
+			return nil, ErrRejectCommentMissing
+		}
+		to, action = entity.PostStatusRejected, entity.PostReviewRejected
+	} else if next, ok := ScheduledPostStatus(to, *post, time.Now()); ok {
+		to = next
+	}
+
+	before := *post
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(post).Updates(map[string]interface{}{"status_id": to, "comment": comment}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&entity.PostReview{PostID: post.ID, ActorID: reviewerID, Action: action, Comment: comment}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	post.StatusID = &to
+	post.Comment = comment
+
+	RecordChange(s.db, entity.AuditLog{
+		ActorID:    &reviewerID,
+		Action:     entity.AuditActionPostReviewed,
+		TargetType: "post",
+		TargetID:   strconv.Itoa(int(post.ID)),
+		IPAddress:  ip,
+		Detail:     action,
+	}, postReviewFields(before), postReviewFields(*post))
+	return post, nil
+}
+
+func postReviewFields(post entity.Post) map[string]any {
+	return map[string]any{"status_id": currentPostStatus(&post), "comment": post.Comment}
+}
+
+// GetReviewQueue โพสต์ที่รอตรวจ เรียงจากที่รอนานที่สุด
+func (s *PostService) GetReviewQueue() ([]entity.Post, error) {
+	var posts []entity.Post
+	err := s.db.
+		Where("status_id = ?", entity.PostStatusPending).
+		Preload("User").
+		Preload("Status").
+		Preload("Location").
+		Order("updated_at ASC").
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// GetPostReviews ประวัติการส่งตรวจและผลการตรวจของโพสต์
+func (s *PostService) GetPostReviews(postID uint) ([]entity.PostReview, error) {
+	var reviews []entity.PostReview
+	err := s.db.
+		Where("post_id = ?", postID).
+		Preload("Actor").
+		Order("created_at ASC").
+		Find(&reviews).Error
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
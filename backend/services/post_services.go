@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/sut68/team21/entity"
@@ -25,7 +26,17 @@ func (s *PostService) CreatePost(post *entity.Post) (*entity.Post, error) {
 		return nil, err
 	}
 
-	if err := s.db.Create(post).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		// โพสต์ที่รออนุมัติถือว่าส่งตรวจตั้งแต่สร้าง
+		if currentPostStatus(post) != entity.PostStatusPending || post.UserID == nil {
+			return nil
+		}
+		return tx.Create(&entity.PostReview{PostID: post.ID, ActorID: *post.UserID, Action: entity.PostReviewSubmitted}).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -53,6 +64,29 @@ func (s *PostService) GetAllPost() ([]entity.Post, error) {
 	return posts, nil
 }
 
+// GetVisiblePosts โพสต์ที่ผู้ใช้ทั่วไปเห็นได้: โพสต์ที่อนุมัติแล้ว และโพสต์ของตนเองทุกสถานะ
+func (s *PostService) GetVisiblePosts(userID uint) ([]entity.Post, error) {
+	var posts []entity.Post
+
+	err := s.db.
+		Where("status_id IN ? OR user_id = ?", entity.PublishedPostStatuses, userID).
+		Preload("User").
+		Preload("User.Role").
+		Preload("Status").
+		Preload("Location").
+		Preload("Chatroom").
+		Preload("Registrations.Users").
+		Preload("Registrations.Results").
+		Preload("Registrations.Results.Award").
+		Find(&posts).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
 func (s *PostService) GetPostByID(id uint) (*entity.Post, error) {
 	var post entity.Post
 
@@ -67,11 +101,15 @@ func (s *PostService) GetPostByID(id uint) (*entity.Post, error) {
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("post not found")
+			return nil, ErrPostNotFound
 		}
 		return nil, err
 	}
 
+	if err := attachCapacity(s.db, &post); err != nil {
+		return nil, err
+	}
+
 	return &post, nil
 }
 
@@ -96,7 +134,7 @@ func (s *PostService) UpdatePost(id uint, updatedData *entity.Post) error {
 	var existingPost entity.Post
 	if err := s.db.First(&existingPost, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("post not found")
+			return ErrPostNotFound
 		}
 		return err
 	}
@@ -106,6 +144,17 @@ func (s *PostService) UpdatePost(id uint, updatedData *entity.Post) error {
 		return err
 	}
 
+	// สถานะต้องเปลี่ยนตาม state machine และโพสต์ที่อนุมัติแล้วใช้สถานะตามวันกิจกรรมที่แก้ไขใหม่
+	status := *updatedData.StatusID
+	if existingPost.StatusID != nil {
+		if !entity.CanTransitionPostStatus(*existingPost.StatusID, status) {
+			return postTransitionError(*existingPost.StatusID, status)
+		}
+		if next, ok := ScheduledPostStatus(status, *updatedData, time.Now()); ok {
+			status = next
+		}
+	}
+
 	result := s.db.Model(&entity.Post{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
@@ -119,9 +168,18 @@ func (s *PostService) UpdatePost(id uint, updatedData *entity.Post) error {
 			"comment":     updatedData.Comment,
 			"start":       updatedData.Start,
 			"stop":        updatedData.Stop,
-			"status_id":   updatedData.StatusID,
+			"status_id":   status,
 			"location_id": updatedData.LocationID,
 			"post_point":  updatedData.PostPoint,
+
+			"max_teams":     updatedData.MaxTeams,
+			"min_team_size": updatedData.MinTeamSize,
+			"max_team_size": updatedData.MaxTeamSize,
+			"max_seats":     updatedData.MaxSeats,
+
+			"block_schedule_conflicts": updatedData.BlockScheduleConflicts,
+			"withdrawal_cutoff_hours":  updatedData.WithdrawalCutoffHours,
+			"late_withdrawal_penalty":  updatedData.LateWithdrawalPenalty,
 		})
 
 	if result.Error != nil {
@@ -134,6 +192,21 @@ func (s *PostService) UpdatePost(id uint, updatedData *entity.Post) error {
 		}
 	}
 
+	// map ไม่ผ่าน serializer ของ gorm จึงบันทึกเงื่อนไขผู้มีสิทธิ์สมัครผ่าน struct
+	if err := s.db.Model(&entity.Post{}).Where("id = ?", id).
+		Select("eligible_faculty_ids", "eligible_major_ids", "eligible_years", "eligible_roles").
+		Updates(&entity.Post{
+			EligibleFacultyIDs: updatedData.EligibleFacultyIDs,
+			EligibleMajorIDs:   updatedData.EligibleMajorIDs,
+			EligibleYears:      updatedData.EligibleYears,
+			EligibleRoles:      updatedData.EligibleRoles,
+		}).Error; err != nil {
+		return err
+	}
+
+	// โควตาที่เพิ่มขึ้นส่งต่อให้คิวสำรองทันที
+	PromoteWaitlist(s.db, id, time.Now())
+
 	return nil
 }
 
@@ -141,7 +214,7 @@ func (s *PostService) DeletePost(id uint) error {
 	var existingPost entity.Post
 	if err := s.db.First(&existingPost, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("post not found")
+			return ErrPostNotFound
 		}
 		return err
 	}
@@ -195,18 +268,40 @@ func ValidatePost(p entity.Post) (bool, error) {
 		}
 	}
 
+	// 4. Team size limits (0 = unlimited)
+	if p.MaxTeamSize > 0 && p.MinTeamSize > p.MaxTeamSize {
+		return false, govalidator.Error{
+			Name: "MinTeamSize",
+			Err:  errors.New("minimum team size must not be greater than maximum team size"),
+		}
+	}
+	if p.MaxSeats > 0 && p.MinTeamSize > p.MaxSeats {
+		return false, govalidator.Error{
+			Name: "MaxSeats",
+			Err:  errors.New("total seats must be at least the minimum team size"),
+		}
+	}
+
+	// 5. Eligible years must be real academic years
+	for _, year := range p.EligibleYears {
+		if year < 1 || year > 8 {
+			return false, govalidator.Error{
+				Name: "EligibleYears",
+				Err:  errors.New("eligible years must be between 1 and 8"),
+			}
+		}
+	}
+
 	return true, nil
 }
 
 // GetActivePostsForStudent returns posts visible to students
-// Only shows Approved (2) and Active (5) posts
+// Only shows Approved, Upcoming and Active posts
 func (s *PostService) GetActivePostsForStudent() ([]entity.Post, error) {
 	var posts []entity.Post
 
-	// Filter posts where status_id IN (2, 5)
-	// 2 = Approved, 4 = Upcoming, 5 = Active
 	err := s.db.
-		Where("status_id IN ?", []uint{2, 4, 5}).
+		Where("status_id IN ?", entity.StudentVisiblePostStatuses).
 		Preload("User").
 		Preload("User.Role").
 		Preload("Status").
@@ -218,5 +313,13 @@ func (s *PostService) GetActivePostsForStudent() ([]entity.Post, error) {
 		return nil, err
 	}
 
+	refs := make([]*entity.Post, len(posts))
+	for i := range posts {
+		refs[i] = &posts[i]
+	}
+	if err := attachCapacity(s.db, refs...); err != nil {
+		return nil, err
+	}
+
 	return posts, nil
 }
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+// AnonymizedFirstName ชื่อที่แสดงแทนผู้ใช้ที่ลบบัญชีแล้ว
+const AnonymizedFirstName = "ผู้ใช้ที่ลบบัญชี"
+
+var (
+	ErrInvalidPassword      = errors.New("รหัสผ่านไม่ถูกต้อง")
+	ErrCannotDeleteAdmin    = errors.New("บัญชีผู้ดูแลระบบลบตัวเองไม่ได้ กรุณาให้ผู้ดูแลระบบคนอื่นดำเนินการ")
+	ErrAlreadyAnonymized    = errors.New("บัญชีนี้ถูกลบข้อมูลส่วนบุคคลไปแล้ว")
+	ErrImpersonatedDeletion = errors.New("ไม่สามารถลบบัญชีระหว่างสวมสิทธิ์ผู้ใช้ได้")
+)
+
+// PrivacyService สิทธิ์ของเจ้าของข้อมูลตาม PDPA: ขอสำเนาข้อมูล และลบบัญชี
+type PrivacyService struct {
+	db       *gorm.DB
+	sessions *SessionService
+}
+
+func NewPrivacyService(db *gorm.DB, sessions *SessionService) *PrivacyService {
+	return &PrivacyService{db: db, sessions: sessions}
+}
+
+// Export เขียน ZIP ของข้อมูลทั้งหมดที่ระบบเก็บเกี่ยวกับผู้ใช้ ไฟล์ละหมวด (JSON) พร้อมไฟล์ที่อัปโหลดไว้ใน files/
+func (s *PrivacyService) Export(userID uint, w io.Writer) error {
+	var user entity.User
+	if err := preloadUserRelationsWithPoints(s.db).First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	var identities []entity.UserIdentity
+	var registrations []entity.Registration
+	var messages []entity.Messages
+	var pointRecords []entity.PointRecord
+	var redemptions []entity.RewardRedeem
+	var portfolios []entity.Portfolio
+	var certificates []entity.Certificate
+	var evaluations []entity.ActivityEvaluationRespone
+
+	queries := []*gorm.DB{
+		s.db.Where("user_id = ?", userID).Find(&identities),
+		preloadAnswers(s.db.Preload("Post")).
+			Joins("JOIN user_registrations ON user_registrations.registration_id = registrations.id").
+			Where("user_registrations.user_id = ?", userID).Find(&registrations),
+		s.db.Where("user_id = ?", userID).Order("created_at").Find(&messages),
+		s.db.Where("user_id = ?", userID).Order("created_at").Find(&pointRecords),
+		s.db.Where("user_id = ?", userID).Order("created_at").Find(&redemptions),
+		s.db.Preload("PortfolioStatus").Where("user_id = ?", userID).Find(&portfolios),
+		s.db.Where("user_id = ?", userID).Find(&certificates),
+		s.db.Preload("ActivityEvaluationScores").Where("user_id = ?", userID).Find(&evaluations),
+	}
+	for _, q := range queries {
+		if q.Error != nil {
+			return q.Error
+		}
+	}
+
+	archive := zip.NewWriter(w)
+	sections := []struct {
+		name string
+		data any
+	}{
+		{"export_info.json", map[string]any{"user_id": user.ID, "sut_id": user.SutId, "generated_at": time.Now()}},
+		{"profile.json", user},
+		{"identities.json", identities},
+		{"registrations.json", registrations},
+		{"messages.json", messages},
+		{"point_records.json", pointRecords},
+		{"redemptions.json", redemptions},
+		{"portfolios.json", portfolios},
+		{"certificates.json", certificates},
+		{"evaluations.json", evaluations},
+	}
+	for _, section := range sections {
+		if err := writeZipJSON(archive, section.name, section.data); err != nil {
+			return err
+		}
+	}
+
+	uploads := UserUploads(&user, messages)
+	for _, registration := range registrations {
+		uploads = append(uploads, RegistrationUploads(registration.Answers)...)
+	}
+	for _, file := range uploads {
+		if err := copyUploadToZip(archive, file); err != nil {
+			log.Printf("export upload %s for user %d skipped: %v", file, user.ID, err)
+		}
+	}
+	return archive.Close()
+}
+
+func writeZipJSON(archive *zip.Writer, name string, data any) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+func copyUploadToZip(archive *zip.Writer, file string) error {
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := archive.Create("files/" + strings.TrimPrefix(file, "upload/"))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// localUpload คืน path ของไฟล์ในโฟลเดอร์ upload/ (ไม่รับ URL ภายนอกหรือ path ที่หลุดออกนอกโฟลเดอร์)
+func localUpload(p string) (string, bool) {
+	if p == "" || isExternalURL(p) {
+		return "", false
+	}
+	clean := path.Clean(strings.TrimPrefix(p, "/"))
+	if !strings.HasPrefix(clean, "upload/") {
+		return "", false
+	}
+	return clean, true
+}
+
+// UserUploads ไฟล์ที่ผู้ใช้อัปโหลดเอง: รูปโปรไฟล์ และรูป/ไฟล์ที่ส่งในแชท
+func UserUploads(user *entity.User, messages []entity.Messages) []string {
+	var files []string
+	if file, ok := localUpload(user.AvatarURL); ok {
+		files = append(files, file)
+	}
+	for _, message := range messages {
+		if message.MessagesTypeID == 1 {
+			continue
+		}
+		if file, ok := localUpload(message.Body); ok {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// RegistrationUploads ไฟล์ที่แนบในคำตอบแบบฟอร์มสมัคร (คำถามชนิด file) ต้อง preload Answers.Field
+func RegistrationUploads(answers []*entity.RegistrationAnswer) []string {
+	var files []string
+	for _, answer := range answers {
+		if answer.Field == nil || answer.Field.Type != entity.FormFieldFile {
+			continue
+		}
+		if file, ok := localUpload(answer.Value); ok {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// DeleteOwnAccount ลบบัญชีตามคำขอของเจ้าของ ต้องยืนยันด้วยรหัสผ่าน
+func (s *PrivacyService) DeleteOwnAccount(userID uint, password, ip string) error {
+	var user entity.User
+	if err := s.db.Preload("Role").First(&user, userID).Error; err != nil {
+		return ErrUserNotFound
+	}
+	if !config.CheckPasswordHash(password, user.Password) {
+		return ErrInvalidPassword
+	}
+	if user.Role != nil && RoleHasPermission(user.Role.Name, entity.PermUsersManage) {
+		return ErrCannotDeleteAdmin
+	}
+	return s.Anonymize(userID, userID, ip)
+}
+
+// Anonymize ลบข้อมูลส่วนบุคคลของผู้ใช้โดยไม่ทำลายสถิติรวม
+//
+// แถวใน users ยังอยู่แต่กลายเป็นนามแฝง ("ผู้ใช้ที่ลบบัญชี" + รหัส deleted-<id>) ข้อความแชท
+// ผลประเมินกิจกรรม การสมัคร คะแนน และการแลกรางวัลจึงยังนับได้ครบแต่ไม่ระบุตัวบุคคล
+// ส่วนข้อมูลที่ระบุตัวตนโดยตรง (ทักษะ ช่องทางติดต่อ ผลงาน ไฟล์ที่อัปโหลด บัญชี SSO) ถูกลบทิ้ง
+// ไฟล์แนบในแบบฟอร์มสมัครถูกลบเฉพาะทีมที่ผู้ใช้เป็นสมาชิกคนเดียว ทีมที่มีสมาชิกอื่นยังใช้ไฟล์ร่วมกันอยู่
+func (s *PrivacyService) Anonymize(userID, actorID uint, ip string) error {
+	var user entity.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return ErrUserNotFound
+	}
+	if user.AnonymizedAt != nil {
+		return ErrAlreadyAnonymized
+	}
+
+	var messages []entity.Messages
+	if err := s.db.Where("user_id = ? AND messages_type_id <> ?", userID, 1).Find(&messages).Error; err != nil {
+		return err
+	}
+	uploads := UserUploads(&user, messages)
+
+	var soloRegistrations []entity.Registration
+	if err := preloadAnswers(s.db).
+		Where("id IN (SELECT registration_id FROM user_registrations WHERE user_id = ?)", userID).
+		Where("(SELECT COUNT(*) FROM user_registrations ur WHERE ur.registration_id = registrations.id) = 1").
+		Find(&soloRegistrations).Error; err != nil {
+		return err
+	}
+	var answerIDs []uint
+	for _, registration := range soloRegistrations {
+		for _, answer := range registration.Answers {
+			if files := RegistrationUploads([]*entity.RegistrationAnswer{answer}); len(files) > 0 {
+				uploads = append(uploads, files...)
+				answerIDs = append(answerIDs, answer.ID)
+			}
+		}
+	}
+
+	randomPassword, err := config.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+	passwordHash, err := config.HashPassword(randomPassword)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	pseudonym := "deleted-" + strconv.Itoa(int(user.ID))
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"sut_id":                  pseudonym,
+			"email":                   pseudonym + "@deleted.invalid",
+			"password":                passwordHash,
+			"first_name":              AnonymizedFirstName,
+			"last_name":               "",
+			"phone":                   "",
+			"bio":                     "",
+			"avatar_url":              "",
+			"email_verified_at":       nil,
+			"totp_secret":             "",
+			"totp_enabled_at":         nil,
+			"password_reset_required": false,
+			"deactivated_at":          now,
+			"anonymized_at":           now,
+		}).Error; err != nil {
+			return err
+		}
+
+		personal := []any{
+			&entity.Skill{}, &entity.Interest{}, &entity.Tool{}, &entity.Social{},
+			&entity.Portfolio{}, &entity.UserIdentity{}, &entity.RecoveryCode{},
+			&entity.AccountToken{}, &entity.APIToken{},
+		}
+		for _, model := range personal {
+			if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(answerIDs) > 0 {
+			if err := tx.Model(&entity.RegistrationAnswer{}).Where("id IN ?", answerIDs).Update("value", "[DELETED]").Error; err != nil {
+				return err
+			}
+		}
+
+		// ข้อความแชทยังอยู่ (ผู้เขียนเป็นนามแฝงแล้ว) แต่รูปและไฟล์ที่แนบถูกลบ
+		return tx.Model(&entity.Messages{}).
+			Where("user_id = ? AND messages_type_id <> ?", user.ID, 1).
+			Update("body", "[DELETED]").Error
+	})
+	if err != nil {
+		return err
+	}
+
+	SetUserDeactivated(user.ID, true)
+	if err := s.sessions.RevokeAllSessions(user.ID); err != nil {
+		log.Printf("revoke sessions of user %d after anonymization failed: %v", user.ID, err)
+	}
+	for _, file := range uploads {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			log.Printf("remove upload %s of user %d failed: %v", file, user.ID, err)
+		}
+	}
+
+	RecordAudit(s.db, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionUserAnonymized,
+		TargetType: "user",
+		TargetID:   strconv.Itoa(int(user.ID)),
+		IPAddress:  ip,
+		Detail:     fmt.Sprintf("ลบข้อมูลส่วนบุคคล ไฟล์ %d ไฟล์", len(uploads)),
+	})
+	return nil
+}
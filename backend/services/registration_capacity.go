@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTeamsFull    = errors.New("กิจกรรมนี้รับสมัครครบจำนวนทีมแล้ว")
+	ErrSeatsFull    = errors.New("ที่นั่งของกิจกรรมนี้เต็มแล้ว")
+	ErrTeamTooSmall = errors.New("จำนวนสมาชิกในทีมน้อยกว่าที่กิจกรรมกำหนด")
+	ErrTeamTooLarge = errors.New("จำนวนสมาชิกในทีมเกินกว่าที่กิจกรรมกำหนด")
+)
+
+// CapacityUsage จำนวนทีมและผู้เข้าร่วมที่ใช้ที่นั่งของกิจกรรมอยู่
+type CapacityUsage struct {
+	Teams int64
+	Seats int64
+}
+
+// CheckTeamSize ตรวจจำนวนสมาชิกของทีมตามขีดจำกัดของกิจกรรม
+func CheckTeamSize(post entity.Post, size int) error {
+	if post.MinTeamSize > 0 && size < int(post.MinTeamSize) {
+		return fmt.Errorf("%w (อย่างน้อย %d คน)", ErrTeamTooSmall, post.MinTeamSize)
+	}
+	if post.MaxTeamSize > 0 && size > int(post.MaxTeamSize) {
+		return fmt.Errorf("%w (ไม่เกิน %d คน)", ErrTeamTooLarge, post.MaxTeamSize)
+	}
+	return nil
+}
+
+// CheckCapacity ตรวจว่ารับทีมเพิ่ม addTeams ทีม และผู้เข้าร่วมเพิ่ม addSeats คนได้หรือไม่
+func CheckCapacity(post entity.Post, usage CapacityUsage, addTeams, addSeats int) error {
+	capacity := CapacityOf(post, usage)
+	if capacity.RemainingTeams != nil && int64(addTeams) > *capacity.RemainingTeams {
+		return ErrTeamsFull
+	}
+	if capacity.RemainingSeats != nil && int64(addSeats) > *capacity.RemainingSeats {
+		return fmt.Errorf("%w (เหลือ %d ที่นั่ง)", ErrSeatsFull, *capacity.RemainingSeats)
+	}
+	return nil
+}
+
+// CapacityOf คำนวณที่ว่างคงเหลือของกิจกรรม
+func CapacityOf(post entity.Post, usage CapacityUsage) *entity.PostCapacity {
+	capacity := &entity.PostCapacity{Teams: usage.Teams, Seats: usage.Seats}
+	if post.MaxTeams > 0 {
+		remaining := max(int64(post.MaxTeams)-usage.Teams, 0)
+		capacity.RemainingTeams = &remaining
+	}
+	if post.MaxSeats > 0 {
+		remaining := max(int64(post.MaxSeats)-usage.Seats, 0)
+		capacity.RemainingSeats = &remaining
+	}
+	return capacity
+}
+
+// lockPost อ่านโพสต์พร้อมล็อกแถว (SELECT ... FOR UPDATE) ให้การสมัครพร้อมกันของกิจกรรมเดียวกันทำทีละรายการ
+func lockPost(tx *gorm.DB, postID uint) (*entity.Post, error) {
+	var post entity.Post
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&post, postID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	return &post, nil
+}
+
+// capacityUsage นับทีมและผู้เข้าร่วมของแต่ละกิจกรรม ไม่นับทีมที่ถูกปฏิเสธ
+func capacityUsage(db *gorm.DB, postIDs []uint) (map[uint]CapacityUsage, error) {
+	usage := make(map[uint]CapacityUsage, len(postIDs))
+	if len(postIDs) == 0 {
+		return usage, nil
+	}
+
+	var teams, seats []struct {
+		PostID uint
+		Count  int64
+	}
+	err := db.Model(&entity.Registration{}).
+		Select("post_id, COUNT(*) AS count").
+		Where("post_id IN ? AND status IN ?", postIDs, entity.ActiveRegistrationStatuses).
+		Group("post_id").
+		Scan(&teams).Error
+	if err != nil {
+		return nil, err
+	}
+	err = db.Table("user_registrations").
+		Select("registrations.post_id, COUNT(*) AS count").
+		Joins("JOIN registrations ON registrations.id = user_registrations.registration_id").
+		Where("registrations.deleted_at IS NULL AND registrations.post_id IN ? AND registrations.status IN ?", postIDs, entity.ActiveRegistrationStatuses).
+		Group("registrations.post_id").
+		Scan(&seats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range teams {
+		u := usage[row.PostID]
+		u.Teams = row.Count
+		usage[row.PostID] = u
+	}
+	for _, row := range seats {
+		u := usage[row.PostID]
+		u.Seats = row.Count
+		usage[row.PostID] = u
+	}
+	return usage, nil
+}
+
+// attachCapacity เติมข้อมูลที่ว่างคงเหลือให้โพสต์ที่จะส่งกลับ
+func attachCapacity(db *gorm.DB, posts ...*entity.Post) error {
+	ids := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	usage, err := capacityUsage(db, ids)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		post.Capacity = CapacityOf(*post, usage[post.ID])
+	}
+	return nil
+}
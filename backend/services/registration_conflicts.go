@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDuplicateRegistration = errors.New("สมาชิกมีทีมในกิจกรรมนี้อยู่แล้ว")
+	ErrScheduleConflict      = errors.New("สมาชิกลงทะเบียนกิจกรรมอื่นที่จัดในเวลาซ้อนกัน")
+)
+
+// scheduleStatuses สถานะทีมที่ถือว่าจะไปร่วมกิจกรรม ใช้ตรวจเวลาซ้อน
+var scheduleStatuses = append(slices.Clone(entity.ActiveRegistrationStatuses), entity.RegistrationStatusWaitlisted)
+
+// HoldsPlace ทีมในสถานะนี้ยังถือที่ของสมาชิกในกิจกรรม (ใช้ที่นั่งหรือรอคิวสำรอง)
+// สมาชิกของทีมที่ถูกปฏิเสธ หมดเวลายืนยัน หรือถอนการสมัครแล้ว จึงเข้าทีมอื่นในโพสต์เดียวกันได้
+func HoldsPlace(status string) bool {
+	return slices.Contains(scheduleStatuses, status)
+}
+
+// SchedulesOverlap ช่วงวันจัดกิจกรรม (start_date–stop_date) ของสองโพสต์ซ้อนกัน ขอบที่ชนกันพอดีไม่นับว่าซ้อน
+func SchedulesOverlap(a, b entity.Post) bool {
+	return a.StartDate.Before(b.StopDate) && b.StartDate.Before(a.StopDate)
+}
+
+type memberSchedule struct {
+	SutID     string
+	Title     string
+	StartDate time.Time
+	StopDate  time.Time
+}
+
+// checkMembership ตรวจผู้ใช้ก่อนเข้าทีมของโพสต์ ใช้กับทุกช่องทางที่เพิ่มสมาชิก
+// ผู้ใช้มีทีมอื่นที่ยังถือที่ในโพสต์เดียวกันไม่ได้ (ดู HoldsPlace) ส่วนกิจกรรมอื่นที่เวลาซ้อนคืนเป็นคำเตือน
+// หรือ ErrScheduleConflict เมื่อผู้จัดตั้ง BlockScheduleConflicts ไว้
+func checkMembership(tx *gorm.DB, post *entity.Post, userIDs []uint, registrationID uint) ([]string, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	var duplicates []string
+	if err := memberRegistrations(tx, userIDs).
+		Where("registrations.post_id = ? AND registrations.id <> ? AND registrations.status IN ?",
+			post.ID, registrationID, scheduleStatuses).
+		Pluck("users.sut_id", &duplicates).Error; err != nil {
+		return nil, err
+	}
+	if len(duplicates) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateRegistration, strings.Join(uniqueStrings(duplicates), ", "))
+	}
+
+	var schedules []memberSchedule
+	if err := memberRegistrations(tx, userIDs).
+		Select("users.sut_id, posts.title, posts.start_date, posts.stop_date").
+		Joins("JOIN posts ON posts.id = registrations.post_id AND posts.deleted_at IS NULL").
+		Where("registrations.post_id <> ? AND registrations.status IN ?", post.ID, scheduleStatuses).
+		Order("users.sut_id, posts.start_date").
+		Scan(&schedules).Error; err != nil {
+		return nil, err
+	}
+	var conflicts []string
+	for _, schedule := range schedules {
+		if SchedulesOverlap(*post, entity.Post{StartDate: schedule.StartDate, StopDate: schedule.StopDate}) {
+			conflicts = append(conflicts, fmt.Sprintf("%s ลงทะเบียน %s ซึ่งจัดในเวลาซ้อนกัน", schedule.SutID, schedule.Title))
+		}
+	}
+	if len(conflicts) > 0 && post.BlockScheduleConflicts {
+		return nil, fmt.Errorf("%w: %s", ErrScheduleConflict, strings.Join(conflicts, "; "))
+	}
+	return conflicts, nil
+}
+
+// memberRegistrations ทีมทั้งหมดที่ผู้ใช้เป็นสมาชิก (join กับ users และ registrations แล้ว)
+func memberRegistrations(tx *gorm.DB, userIDs []uint) *gorm.DB {
+	return tx.Table("user_registrations").
+		Joins("JOIN registrations ON registrations.id = user_registrations.registration_id AND registrations.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = user_registrations.user_id").
+		Where("user_registrations.user_id IN ?", userIDs)
+}
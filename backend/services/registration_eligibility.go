@@ -0,0 +1,83 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRegistrationNotOpen = errors.New("กิจกรรมนี้ยังไม่เปิดรับสมัคร")
+	ErrRegistrationClosed  = errors.New("กิจกรรมนี้ปิดรับสมัครแล้ว")
+	ErrNotEligible         = errors.New("สมาชิกไม่มีสิทธิ์สมัครกิจกรรมนี้")
+)
+
+// CheckRegistrationWindow ตรวจว่าโพสต์เผยแพร่แล้วและ now อยู่ในช่วงรับสมัคร (Start ถึง Stop)
+func CheckRegistrationWindow(post entity.Post, now time.Time) error {
+	if post.StatusID == nil || !isStudentVisible(*post.StatusID) {
+		return ErrRegistrationNotOpen
+	}
+	if now.Before(post.Start) {
+		return fmt.Errorf("%w (เปิดรับสมัคร %s)", ErrRegistrationNotOpen, post.Start.Format("02/01/2006 15:04"))
+	}
+	if now.After(post.Stop) {
+		return ErrRegistrationClosed
+	}
+	return nil
+}
+
+// IneligibleReasons เหตุผลที่ผู้ใช้ไม่ผ่านเงื่อนไขผู้มีสิทธิ์สมัครของโพสต์ (ว่าง = มีสิทธิ์)
+// ตรวจบทบาทต้อง preload user.Role
+func IneligibleReasons(post entity.Post, user entity.User) []string {
+	var reasons []string
+	if len(post.EligibleFacultyIDs) > 0 && !slices.Contains(post.EligibleFacultyIDs, user.FacultyID) {
+		reasons = append(reasons, "คณะ")
+	}
+	if len(post.EligibleMajorIDs) > 0 && !slices.Contains(post.EligibleMajorIDs, user.MajorID) {
+		reasons = append(reasons, "สาขา")
+	}
+	if len(post.EligibleYears) > 0 && !slices.Contains(post.EligibleYears, user.Year) {
+		reasons = append(reasons, "ชั้นปี")
+	}
+	if len(post.EligibleRoles) > 0 && (user.Role == nil || !slices.Contains(post.EligibleRoles, user.Role.Name)) {
+		reasons = append(reasons, "บทบาท")
+	}
+	return reasons
+}
+
+// CheckEligibility ตรวจสมาชิกทุกคนตามเงื่อนไขของโพสต์ error ระบุรหัสนักศึกษา ชื่อ และเงื่อนไขที่ไม่ผ่านของทุกคน
+func CheckEligibility(post entity.Post, users []*entity.User) error {
+	var members []string
+	for _, user := range users {
+		reasons := IneligibleReasons(post, *user)
+		if len(reasons) == 0 {
+			continue
+		}
+		members = append(members, fmt.Sprintf("%s %s %s (%s ไม่ตรงตามเงื่อนไข)",
+			user.SutId, user.FirstName, user.LastName, strings.Join(reasons, ", ")))
+	}
+	if len(members) > 0 {
+		return fmt.Errorf("%w: %s", ErrNotEligible, strings.Join(members, "; "))
+	}
+	return nil
+}
+
+// findMembers โหลดผู้ใช้ตาม id พร้อมบทบาท ใช้ตรวจสิทธิ์สมัคร
+func findMembers(tx *gorm.DB, userIDs []uint) ([]*entity.User, error) {
+	var users []*entity.User
+	if len(userIDs) == 0 {
+		return users, nil
+	}
+	if err := tx.Preload("Role").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	if len(users) != len(userIDs) {
+		return nil, errors.New("one or more users not found")
+	}
+	return users, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/entity"
+)
+
+var registrationCSVHeader = []string{"id", "team_name", "status", "registration_date", "member_sut_ids", "member_names", "description",
+	"withdrawn_at", "withdrawal_reason", "withdrawal_penalty"}
+
+// ExportRegistrationsCSV เขียนรายชื่อทีมของกิจกรรมเป็น CSV หนึ่งคอลัมน์ต่อคำถามในแบบฟอร์มสมัครปัจจุบัน
+// ทีมที่ถอนการสมัครอยู่ในไฟล์ด้วย พร้อมเวลา เหตุผล และคะแนนที่ถูกหัก
+func (s *RegistrationService) ExportRegistrationsCSV(postID uint, w io.Writer) error {
+	fields, err := formFields(s.db, postID)
+	if err != nil {
+		return err
+	}
+	var registrations []entity.Registration
+	if err := s.db.Preload("Users").Preload("Answers").
+		Where("post_id = ?", postID).
+		Order("registration_date, id").
+		Find(&registrations).Error; err != nil {
+		return err
+	}
+	return WriteRegistrationsCSV(w, fields, registrations)
+}
+
+// WriteRegistrationsCSV แปลงรายชื่อทีมพร้อมคำตอบเป็น CSV (multi_choice คั่นด้วย "; ")
+func WriteRegistrationsCSV(w io.Writer, fields []entity.RegistrationFormField, registrations []entity.Registration) error {
+	writer := csv.NewWriter(w)
+	header := append([]string{}, registrationCSVHeader...)
+	for _, field := range fields {
+		header = append(header, field.Label)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, registration := range registrations {
+		sutIDs := make([]string, 0, len(registration.Users))
+		names := make([]string, 0, len(registration.Users))
+		for _, user := range registration.Users {
+			sutIDs = append(sutIDs, user.SutId)
+			names = append(names, strings.TrimSpace(user.FirstName+" "+user.LastName))
+		}
+		answers := make(map[uint]string, len(registration.Answers))
+		for _, answer := range registration.Answers {
+			answers[answer.FieldID] = answer.Value
+		}
+
+		record := []string{
+			strconv.FormatUint(uint64(registration.ID), 10),
+			registration.TeamName,
+			registration.Status,
+			registration.RegistrationDate.Format(time.RFC3339),
+			strings.Join(sutIDs, "; "),
+			strings.Join(names, "; "),
+			registration.Description,
+			"",
+			registration.WithdrawalReason,
+			"",
+		}
+		if registration.WithdrawnAt != nil {
+			record[7] = registration.WithdrawnAt.Format(time.RFC3339)
+			record[9] = strconv.FormatUint(uint64(registration.WithdrawalPenalty), 10)
+		}
+		for _, field := range fields {
+			record = append(record, answerText(field, answers[field.ID]))
+		}
+		for i, value := range record {
+			record[i] = csvSafe(value)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func answerText(field entity.RegistrationFormField, value string) string {
+	if field.Type != entity.FormFieldMultiChoice || value == "" {
+		return value
+	}
+	var selected []string
+	if err := json.Unmarshal([]byte(value), &selected); err != nil {
+		return value
+	}
+	return strings.Join(selected, "; ")
+}
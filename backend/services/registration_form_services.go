@@ -0,0 +1,282 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+const (
+	MaxFormFields         = 50
+	MaxFormLabelLength    = 200
+	MaxFormAnswerLength   = 2000
+	RegistrationUploadDir = "upload/registration/"
+)
+
+var (
+	ErrInvalidFormField = errors.New("คำถามในแบบฟอร์มไม่ถูกต้อง")
+	ErrInvalidAnswer    = errors.New("คำตอบในแบบฟอร์มสมัครไม่ถูกต้อง")
+)
+
+// ValidateFormFields ตรวจและจัดรูปแบบฟอร์มสมัครก่อนบันทึก
+func ValidateFormFields(inputs []dto.RegistrationFormFieldInput) ([]dto.RegistrationFormFieldInput, error) {
+	if len(inputs) > MaxFormFields {
+		return nil, fmt.Errorf("%w: มีได้ไม่เกิน %d ข้อ", ErrInvalidFormField, MaxFormFields)
+	}
+	fields := make([]dto.RegistrationFormFieldInput, 0, len(inputs))
+	seen := make(map[uint]bool, len(inputs))
+	for i, input := range inputs {
+		if input.ID != 0 {
+			if seen[input.ID] {
+				return nil, fmt.Errorf("%w: คำถามรหัส %d ซ้ำ", ErrInvalidFormField, input.ID)
+			}
+			seen[input.ID] = true
+		}
+		input.Label = strings.TrimSpace(input.Label)
+		if input.Label == "" || utf8.RuneCountInString(input.Label) > MaxFormLabelLength {
+			return nil, fmt.Errorf("%w: ข้อ %d ต้องมีชื่อคำถามไม่เกิน %d ตัวอักษร", ErrInvalidFormField, i+1, MaxFormLabelLength)
+		}
+		if !slices.Contains(entity.FormFieldTypes, input.Type) {
+			return nil, fmt.Errorf("%w: ข้อ %d ชนิดคำถาม %q ไม่รองรับ", ErrInvalidFormField, i+1, input.Type)
+		}
+
+		if input.Type == entity.FormFieldChoice || input.Type == entity.FormFieldMultiChoice {
+			options := make([]string, 0, len(input.Options))
+			for _, option := range input.Options {
+				option = strings.TrimSpace(option)
+				if option == "" || slices.Contains(options, option) {
+					return nil, fmt.Errorf("%w: ข้อ %d ตัวเลือกต้องไม่ว่างและไม่ซ้ำกัน", ErrInvalidFormField, i+1)
+				}
+				options = append(options, option)
+			}
+			if len(options) == 0 {
+				return nil, fmt.Errorf("%w: ข้อ %d ต้องมีตัวเลือกอย่างน้อย 1 ตัวเลือก", ErrInvalidFormField, i+1)
+			}
+			input.Options = options
+		} else {
+			input.Options = nil
+		}
+		fields = append(fields, input)
+	}
+	return fields, nil
+}
+
+// ValidateAnswers ตรวจคำตอบตามแบบฟอร์มของกิจกรรม คืนคำตอบที่จะบันทึก (ไม่รวมข้อที่ไม่บังคับและเว้นว่าง)
+func ValidateAnswers(fields []entity.RegistrationFormField, inputs []dto.RegistrationAnswerInput) ([]*entity.RegistrationAnswer, error) {
+	values := make(map[uint]json.RawMessage, len(inputs))
+	for _, input := range inputs {
+		if !slices.ContainsFunc(fields, func(field entity.RegistrationFormField) bool { return field.ID == input.FieldID }) {
+			return nil, fmt.Errorf("%w: ไม่พบคำถามรหัส %d ในแบบฟอร์มของกิจกรรมนี้", ErrInvalidAnswer, input.FieldID)
+		}
+		if _, ok := values[input.FieldID]; ok {
+			return nil, fmt.Errorf("%w: ตอบคำถามรหัส %d ซ้ำ", ErrInvalidAnswer, input.FieldID)
+		}
+		values[input.FieldID] = input.Value
+	}
+
+	answers := make([]*entity.RegistrationAnswer, 0, len(fields))
+	for _, field := range fields {
+		value, err := answerValue(field, values[field.ID])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %v", ErrInvalidAnswer, field.Label, err)
+		}
+		if value == "" {
+			if field.Required {
+				return nil, fmt.Errorf("%w: กรุณาตอบ %s", ErrInvalidAnswer, field.Label)
+			}
+			continue
+		}
+		answers = append(answers, &entity.RegistrationAnswer{FieldID: field.ID, Value: value})
+	}
+	return answers, nil
+}
+
+// answerValue แปลงคำตอบเป็นค่าที่บันทึก ค่าว่าง (null, "", []) คืน ""
+func answerValue(field entity.RegistrationFormField, raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return "", nil
+	}
+
+	switch field.Type {
+	case entity.FormFieldNumber:
+		var number float64
+		if err := json.Unmarshal(raw, &number); err != nil {
+			return "", errors.New("ต้องเป็นตัวเลข")
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+
+	case entity.FormFieldMultiChoice:
+		var selected []string
+		if err := json.Unmarshal(raw, &selected); err != nil {
+			return "", errors.New("ต้องเป็นรายการตัวเลือก")
+		}
+		if len(selected) == 0 {
+			return "", nil
+		}
+		selected = uniqueStrings(selected)
+		for _, option := range selected {
+			if !slices.Contains(field.Options, option) {
+				return "", fmt.Errorf("ไม่มีตัวเลือก %q", option)
+			}
+		}
+		encoded, err := json.Marshal(selected)
+		return string(encoded), err
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		return "", errors.New("ต้องเป็นข้อความ")
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", nil
+	}
+	switch field.Type {
+	case entity.FormFieldChoice:
+		if !slices.Contains(field.Options, text) {
+			return "", fmt.Errorf("ไม่มีตัวเลือก %q", text)
+		}
+	case entity.FormFieldFile:
+		// ไฟล์ต้องอัปโหลดผ่าน POST /registration/upload ก่อน
+		file, ok := localUpload(text)
+		if !ok || !strings.HasPrefix(file, RegistrationUploadDir) {
+			return "", errors.New("ต้องเป็นไฟล์ที่อัปโหลดผ่านระบบ")
+		}
+		text = file
+	default:
+		if utf8.RuneCountInString(text) > MaxFormAnswerLength {
+			return "", fmt.Errorf("ยาวเกิน %d ตัวอักษร", MaxFormAnswerLength)
+		}
+	}
+	return text, nil
+}
+
+// formFields คำถามของกิจกรรมตามลำดับที่แสดง
+func formFields(db *gorm.DB, postID uint) ([]entity.RegistrationFormField, error) {
+	var fields []entity.RegistrationFormField
+	if err := db.Where("post_id = ?", postID).Order("position, id").Find(&fields).Error; err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// GetRegistrationForm แบบฟอร์มสมัครของกิจกรรม
+func (s *PostService) GetRegistrationForm(postID uint) ([]entity.RegistrationFormField, error) {
+	return formFields(s.db, postID)
+}
+
+// SaveRegistrationForm แทนที่แบบฟอร์มสมัครทั้งชุด คำถามที่มี id เดิมถูกแก้ไข คำถามที่ไม่ส่งมาถูกลบ
+// คำตอบเดิมของคำถามที่ถูกลบยังอยู่ จึงยังดูย้อนหลังได้
+func (s *PostService) SaveRegistrationForm(postID uint, inputs []dto.RegistrationFormFieldInput, actorID uint, ip string) ([]entity.RegistrationFormField, error) {
+	inputs, err := ValidateFormFields(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	var before, after []entity.RegistrationFormField
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := lockPost(tx, postID); err != nil {
+			return err
+		}
+		if before, err = formFields(tx, postID); err != nil {
+			return err
+		}
+
+		kept := make([]uint, 0, len(inputs))
+		for i, input := range inputs {
+			field := entity.RegistrationFormField{
+				PostID:   postID,
+				Label:    input.Label,
+				Type:     input.Type,
+				Required: input.Required,
+				Options:  input.Options,
+				Position: i,
+			}
+			if input.ID != 0 {
+				if !slices.ContainsFunc(before, func(f entity.RegistrationFormField) bool { return f.ID == input.ID }) {
+					return fmt.Errorf("%w: ไม่พบคำถามรหัส %d ในกิจกรรมนี้", ErrInvalidFormField, input.ID)
+				}
+				field.ID = input.ID
+				if err := tx.Model(&field).Select("label", "type", "required", "options", "position").Updates(&field).Error; err != nil {
+					return err
+				}
+			} else if err := tx.Create(&field).Error; err != nil {
+				return err
+			}
+			kept = append(kept, field.ID)
+		}
+
+		removed := tx.Where("post_id = ?", postID)
+		if len(kept) > 0 {
+			removed = removed.Where("id NOT IN ?", kept)
+		}
+		if err := removed.Delete(&entity.RegistrationFormField{}).Error; err != nil {
+			return err
+		}
+
+		after, err = formFields(tx, postID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	RecordChange(s.db, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionPostFormChanged,
+		TargetType: "post",
+		TargetID:   strconv.Itoa(int(postID)),
+		IPAddress:  ip,
+	}, map[string]any{"fields": before}, map[string]any{"fields": after})
+	return after, nil
+}
+
+// saveAnswers ตรวจคำตอบตามแบบฟอร์มปัจจุบันของกิจกรรม แล้วแทนที่คำตอบเดิมของทีม
+func saveAnswers(tx *gorm.DB, registration *entity.Registration, inputs []dto.RegistrationAnswerInput) error {
+	if registration.PostID == nil {
+		return nil
+	}
+	fields, err := formFields(tx, *registration.PostID)
+	if err != nil {
+		return err
+	}
+	answers, err := ValidateAnswers(fields, inputs)
+	if err != nil {
+		return err
+	}
+
+	// คำตอบของคำถามที่ถูกลบไปแล้วเก็บไว้ตามเดิม
+	if len(fields) > 0 {
+		fieldIDs := make([]uint, len(fields))
+		for i, field := range fields {
+			fieldIDs[i] = field.ID
+		}
+		if err := tx.Unscoped().Where("registration_id = ? AND field_id IN ?", registration.ID, fieldIDs).
+			Delete(&entity.RegistrationAnswer{}).Error; err != nil {
+			return err
+		}
+	}
+	for _, answer := range answers {
+		answer.RegistrationID = registration.ID
+	}
+	if len(answers) == 0 {
+		return nil
+	}
+	return tx.Create(&answers).Error
+}
+
+// preloadAnswers โหลดคำตอบพร้อมคำถาม รวมคำถามที่ถูกลบไปแล้ว
+func preloadAnswers(db *gorm.DB) *gorm.DB {
+	return db.Preload("Answers").Preload("Answers.Field", func(tx *gorm.DB) *gorm.DB {
+		return tx.Unscoped()
+	})
+}
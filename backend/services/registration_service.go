@@ -3,10 +3,14 @@ package services
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"time"
 
+	"github.com/sut68/team21/dto"
 	"github.com/sut68/team21/entity"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RegistrationService struct {
@@ -21,7 +25,22 @@ func (s *RegistrationService) GetDB() *gorm.DB {
 	return s.db
 }
 
-func (s *RegistrationService) CreateRegistrationWithUserIDs(registration *entity.Registration, userIDs []uint) (*entity.Registration, error) {
+// RegistrationOptions ข้อมูลประกอบการสร้างทีมนอกจากสมาชิกที่เข้าทีมทันที
+type RegistrationOptions struct {
+	// InviteeIDs ผู้ที่ถูกเชิญเข้าทีม จะเป็นสมาชิกเมื่อตอบรับคำเชิญแล้วเท่านั้น
+	InviteeIDs []uint
+	// InviterID ผู้ส่งคำเชิญ (ผู้สร้างทีม)
+	InviterID uint
+	// LeaderID หัวหน้าทีม ถ้าไม่ใช่สมาชิกที่เข้าทีมทันทีจะใช้สมาชิกคนแรกแทน
+	LeaderID uint
+	Answers   []dto.RegistrationAnswerInput
+	// OverrideWindow ให้ผู้ดูแลเพิ่มทีมนอกช่วงรับสมัครได้ แต่สมาชิกยังต้องมีสิทธิ์ตามเงื่อนไขของกิจกรรม
+	OverrideWindow bool
+}
+
+// CreateRegistrationWithUserIDs สร้างทีมพร้อมสมาชิก คำเชิญ และคำตอบแบบฟอร์มสมัคร ตรวจช่วงรับสมัคร ผู้มีสิทธิ์สมัคร ขนาดทีม และที่นั่ง
+// ขนาดทีมนับรวมผู้ถูกเชิญ ส่วนที่นั่งของกิจกรรมนับเฉพาะสมาชิกที่เข้าทีมแล้ว
+func (s *RegistrationService) CreateRegistrationWithUserIDs(registration *entity.Registration, userIDs []uint, opts RegistrationOptions) (*entity.Registration, error) {
 
 	if registration.RegistrationDate.IsZero() {
 		registration.RegistrationDate = time.Now()
@@ -47,43 +66,125 @@ func (s *RegistrationService) CreateRegistrationWithUserIDs(registration *entity
 	}()
 
 	registration.Users = nil
+	registration.Answers = nil
+	userIDs = uniqueUints(userIDs)
+	inviteeIDs := make([]uint, 0, len(opts.InviteeIDs))
+	for _, id := range uniqueUints(opts.InviteeIDs) {
+		if !slices.Contains(userIDs, id) {
+			inviteeIDs = append(inviteeIDs, id)
+		}
+	}
+	now := time.Now()
 
-	if err := tx.Create(registration).Error; err != nil {
+	// ล็อกโพสต์ก่อนนับที่นั่ง การสมัครพร้อมกันจึงไม่เกินจำนวนที่กิจกรรมรับได้
+	if registration.PostID == nil {
+		tx.Rollback()
+		return nil, ErrPostNotFound
+	}
+	post, err := lockPost(tx, *registration.PostID)
+	if err != nil {
 		tx.Rollback()
 		return nil, err
 	}
-
-	if len(userIDs) > 0 {
-		fmt.Printf(" Associating Users: %v to Registration ID: %d\n", userIDs, registration.ID)
-
-		var users []*entity.User
-		if err := tx.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+	if !opts.OverrideWindow {
+		if err := CheckRegistrationWindow(*post, now); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
-
-		if len(users) != len(userIDs) {
+	}
+	if err := CheckTeamSize(*post, len(userIDs)+len(inviteeIDs)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	users, err := findMembers(tx, userIDs)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	invitees, err := findMembers(tx, inviteeIDs)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := CheckEligibility(*post, append(users, invitees...)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if isActiveRegistration(registration.Status) {
+		usage, err := capacityUsage(tx, []uint{post.ID})
+		if err != nil {
 			tx.Rollback()
-			return nil, errors.New("one or more users not found")
+			return nil, err
 		}
+		// ที่นั่งเต็มแล้วให้เข้าคิวสำรองแทนการปฏิเสธ
+		if err := CheckCapacity(*post, usage[post.ID], 1, len(userIDs)); err != nil {
+			if !errors.Is(err, ErrTeamsFull) && !errors.Is(err, ErrSeatsFull) {
+				tx.Rollback()
+				return nil, err
+			}
+			registration.Status = entity.RegistrationStatusWaitlisted
+		}
+	}
+
+	if err := tx.Create(registration).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := recordStatusChange(tx, registration.ID, "", registration.Status, "", actorRef(opts.InviterID)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := saveAnswers(tx, registration, opts.Answers); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// ผู้ถูกเชิญตรวจด้วย เพื่อให้รู้ตั้งแต่ตอนสร้างทีม (ตรวจซ้ำอีกครั้งตอนตอบรับ)
+	warnings, err := checkMembership(tx, post, slices.Concat(userIDs, inviteeIDs), registration.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if len(userIDs) > 0 {
+		fmt.Printf(" Associating Users: %v to Registration ID: %d\n", userIDs, registration.ID)
 
 		if err := tx.Model(registration).Association("Users").Append(users); err != nil {
 			tx.Rollback()
 			return nil, fmt.Errorf("failed to associate users: %v", err)
 		}
 		fmt.Println(" Users Associated Successfully")
+
+		leaderID := userIDs[0]
+		if slices.Contains(userIDs, opts.LeaderID) {
+			leaderID = opts.LeaderID
+		}
+		if err := setLeader(tx, registration.ID, leaderID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
 	} else {
 		fmt.Println(" No UserIDs provided for association")
 	}
 
+	for _, invitee := range invitees {
+		if err := tx.Create(newInvitation(registration.ID, opts.InviterID, invitee.ID, now)).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
 
 	var result entity.Registration
-	if err := s.db.Preload("Post").Preload("Users").Preload("Results.Award").First(&result, registration.ID).Error; err != nil {
+	if err := preloadAnswers(s.db.Preload("Post").Preload("Users").Preload("Results.Award")).First(&result, registration.ID).Error; err != nil {
 		return nil, err
 	}
+	s.withWaitlistPosition(&result)
+	s.withLeader(&result)
+	result.Warnings = warnings
 
 	return &result, nil
 }
@@ -121,6 +222,76 @@ func (s *RegistrationService) UpdateRegistration(id string, updatedData *entity.
 	return &registration, nil
 }
 
+// UpdateRegistrationStatus อนุมัติ/ปฏิเสธการสมัคร และบันทึกสถานะก่อน-หลังลง audit log
+func (s *RegistrationService) UpdateRegistrationStatus(id, status, reason string, actorID uint, ip string) (*entity.Registration, error) {
+	var before entity.Registration
+	if err := s.db.First(&before, id).Error; err != nil {
+		return nil, errors.New("registration not found")
+	}
+	// ทีมที่ถอนการสมัครแล้วเก็บไว้เป็นประวัติ เปลี่ยนสถานะไม่ได้
+	if before.Status == entity.RegistrationStatusWithdrawn {
+		return nil, ErrRegistrationWithdrawn
+	}
+
+	var registration *entity.Registration
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// ทีมที่ถูกปฏิเสธแล้วกลับมาใช้ที่นั่งอีกครั้ง ต้องมีที่ว่างพอ
+		if !isActiveRegistration(before.Status) && isActiveRegistration(status) && before.PostID != nil {
+			post, err := lockPost(tx, *before.PostID)
+			if err != nil {
+				return err
+			}
+			members := tx.Model(&before).Association("Users").Count()
+			usage, err := capacityUsage(tx, []uint{post.ID})
+			if err != nil {
+				return err
+			}
+			if err := CheckCapacity(*post, usage[post.ID], 1, int(members)); err != nil {
+				return err
+			}
+		}
+		// สมาชิกที่ยังไม่ตอบรับคำเชิญไม่นับ ทีมที่มีสมาชิกไม่ถึงขั้นต่ำจึงยังอนุมัติไม่ได้
+		if status == entity.RegistrationStatusApproved && before.PostID != nil {
+			var post entity.Post
+			if err := tx.First(&post, *before.PostID).Error; err != nil {
+				return ErrPostNotFound
+			}
+			members := tx.Model(&before).Association("Users").Count()
+			if err := CheckTeamSize(post, int(members)); errors.Is(err, ErrTeamTooSmall) {
+				return err
+			}
+		}
+		updated, err := NewRegistrationService(tx).UpdateRegistration(id, &entity.Registration{Status: status, RejectionReason: reason})
+		if err != nil {
+			return err
+		}
+		registration = updated
+		// ทีมที่ถูกปิดรับสมาชิกเพิ่มไม่ได้ คำเชิญที่ค้างจึงยกเลิกทั้งหมด
+		if CheckTeamOpen(status) != nil {
+			if err := cancelPendingInvitations(tx, before.ID); err != nil {
+				return err
+			}
+		}
+		return recordStatusChange(tx, before.ID, before.Status, status, reason, actorRef(actorID))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if isActiveRegistration(before.Status) && !isActiveRegistration(status) && before.PostID != nil {
+		PromoteWaitlist(s.db, *before.PostID, time.Now())
+	}
+
+	RecordChange(s.db, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionRegistrationStatusChanged,
+		TargetType: "registration",
+		TargetID:   strconv.Itoa(int(registration.ID)),
+		IPAddress:  ip,
+		Detail:     reason,
+	}, before, registration)
+	return registration, nil
+}
+
 func (s *RegistrationService) DeleteRegistration(id string) error {
 	fmt.Printf(" Attempting to delete Registration ID: %s\n", id)
 
@@ -164,74 +335,230 @@ func (s *RegistrationService) DeleteRegistration(id string) error {
 		return err
 	}
 
+	if isActiveRegistration(registration.Status) && registration.PostID != nil {
+		PromoteWaitlist(s.db, *registration.PostID, time.Now())
+	}
+
 	return nil
 }
 
 func (s *RegistrationService) GetRegistrationByID(id string) (*entity.Registration, error) {
 	var registration entity.Registration
-	if err := s.db.Preload("Post").Preload("Users").Preload("Results.Award").First(&registration, id).Error; err != nil {
+	if err := preloadStatusHistory(preloadAnswers(s.db.Preload("Post").Preload("Users").Preload("Results.Award"))).First(&registration, id).Error; err != nil {
 		return nil, errors.New("registration not found")
 	}
+	s.withWaitlistPosition(&registration)
+	s.withLeader(&registration)
 	return &registration, nil
 }
 
 func (s *RegistrationService) GetRegistrationsByPostID(postID string) ([]entity.Registration, error) {
 	var registrations []entity.Registration
-	if err := s.db.Preload("Post").Preload("Users").Preload("Users.Major").Preload("Results.Award").Where("post_id = ?", postID).Find(&registrations).Error; err != nil {
+	if err := preloadAnswers(s.db.Preload("Post").Preload("Users").Preload("Users.Major").Preload("Results.Award")).Where("post_id = ?", postID).Find(&registrations).Error; err != nil {
 		return nil, errors.New("registrations not found")
 	}
+	for i := range registrations {
+		s.withLeader(&registrations[i])
+	}
 	return registrations, nil
 }
 
-func (s *RegistrationService) AddUserToRegistration(registrationID string, userID uint) error {
-	var registration entity.Registration
-	if err := s.db.First(&registration, registrationID).Error; err != nil {
-		return errors.New("registration not found")
+// UpdateTeamDetails แก้ชื่อทีม คำอธิบาย และคำตอบแบบฟอร์มสมัคร (ตามแบบฟอร์มปัจจุบัน) ใน transaction เดียว
+// answers เป็น nil คือไม่แก้คำตอบ ทีมที่ปิดไปแล้ว (ถูกปฏิเสธ หมดเวลายืนยัน ถอนการสมัคร) แก้ไขไม่ได้
+func (s *RegistrationService) UpdateTeamDetails(id, teamName, description string, answers []dto.RegistrationAnswerInput) (*entity.Registration, error) {
+	var updated *entity.Registration
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var registration entity.Registration
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&registration, id).Error; err != nil {
+			return errors.New("registration not found")
+		}
+		if err := CheckTeamOpen(registration.Status); err != nil {
+			return err
+		}
+		if answers != nil {
+			if err := saveAnswers(tx, &registration, answers); err != nil {
+				return err
+			}
+		}
+		var err error
+		if updated, err = NewRegistrationService(tx).UpdateRegistration(id, &entity.Registration{TeamName: teamName, Description: description}); err != nil {
+			return err
+		}
+		_, err = updated.Validate()
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
+	return updated, nil
+}
 
-	var user entity.User
-	if err := s.db.First(&user, userID).Error; err != nil {
-		return errors.New("user not found")
-	}
+var ErrAlreadyMember = errors.New("ผู้ใช้นี้เป็นสมาชิกของทีมอยู่แล้ว")
 
-	var existingUsers []*entity.User
-	s.db.Model(&registration).Association("Users").Find(&existingUsers)
+// AddUserToRegistration เพิ่มสมาชิกเข้าทีม สมาชิกใหม่ต้องมีสิทธิ์สมัคร ไม่มีทีมอื่นในกิจกรรมเดียวกัน
+// และอยู่ภายใต้ขีดจำกัดขนาดทีมและที่นั่งของกิจกรรม คืนคำเตือนกิจกรรมที่เวลาซ้อน
+func (s *RegistrationService) AddUserToRegistration(registrationID string, userID uint) ([]string, error) {
+	var warnings []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var registration entity.Registration
+		if err := tx.First(&registration, registrationID).Error; err != nil {
+			return errors.New("registration not found")
+		}
 
-	for _, u := range existingUsers {
-		if u.ID == userID {
-			return errors.New("")
+		var user entity.User
+		if err := tx.Preload("Role").First(&user, userID).Error; err != nil {
+			return errors.New("user not found")
 		}
-	}
 
-	if err := s.db.Model(&registration).Association("Users").Append(&user); err != nil {
-		return err
-	}
+		if registration.PostID != nil {
+			post, err := lockPost(tx, *registration.PostID)
+			if err != nil {
+				return err
+			}
+			if err := CheckEligibility(*post, []*entity.User{&user}); err != nil {
+				return err
+			}
+			if err := checkMemberChange(tx, post, &registration, 1); err != nil {
+				return err
+			}
+			if warnings, err = checkMembership(tx, post, []uint{user.ID}, registration.ID); err != nil {
+				return err
+			}
+		}
 
-	return nil
+		var existingUsers []*entity.User
+		if err := tx.Model(&registration).Association("Users").Find(&existingUsers); err != nil {
+			return err
+		}
+		for _, u := range existingUsers {
+			if u.ID == userID {
+				return ErrAlreadyMember
+			}
+		}
+
+		if err := tx.Model(&registration).Association("Users").Append(&user); err != nil {
+			return err
+		}
+		return ensureLeader(tx, registration.ID)
+	})
+	return warnings, err
 }
 
 func (s *RegistrationService) RemoveUserFromRegistration(registrationID string, userID uint) error {
 	var registration entity.Registration
-	if err := s.db.First(&registration, registrationID).Error; err != nil {
-		return errors.New("registration not found")
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&registration, registrationID).Error; err != nil {
+			return errors.New("registration not found")
+		}
+
+		var user entity.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return errors.New("user not found")
+		}
+
+		if registration.PostID != nil {
+			post, err := lockPost(tx, *registration.PostID)
+			if err != nil {
+				return err
+			}
+			if err := checkMemberChange(tx, post, &registration, -1); err != nil {
+				return err
+			}
+		}
+
+		// หัวหน้าทีมออกจากทีม สมาชิกที่เข้าทีมก่อนสุดเป็นหัวหน้าแทน
+		if err := tx.Model(&registration).Association("Users").Delete(&user); err != nil {
+			return err
+		}
+		return ensureLeader(tx, registration.ID)
+	})
+	if err != nil {
+		return err
 	}
 
-	var user entity.User
-	if err := s.db.First(&user, userID).Error; err != nil {
-		return errors.New("user not found")
+	// ที่นั่งที่คืนมาส่งต่อให้คิวสำรอง
+	if isActiveRegistration(registration.Status) && registration.PostID != nil {
+		PromoteWaitlist(s.db, *registration.PostID, time.Now())
 	}
+	return nil
+}
 
-	if err := s.db.Model(&registration).Association("Users").Delete(&user); err != nil {
+// checkMemberChange ตรวจขนาดทีมหลังเพิ่ม/ลดสมาชิก delta คน (ทีมที่ถูกปฏิเสธไม่ต้องตรวจ)
+// การเพิ่มสมาชิกตรวจเฉพาะขนาดสูงสุดและที่นั่ง ส่วนการลดตรวจเฉพาะขนาดขั้นต่ำ
+func checkMemberChange(tx *gorm.DB, post *entity.Post, registration *entity.Registration, delta int) error {
+	if !isActiveRegistration(registration.Status) {
+		return nil
+	}
+	size := int(tx.Model(registration).Association("Users").Count()) + delta
+	err := CheckTeamSize(*post, size)
+	if delta > 0 && errors.Is(err, ErrTeamTooSmall) || delta < 0 && errors.Is(err, ErrTeamTooLarge) {
+		err = nil
+	}
+	if err != nil || delta < 0 {
 		return err
 	}
 
-	return nil
+	usage, err := capacityUsage(tx, []uint{post.ID})
+	if err != nil {
+		return err
+	}
+	return CheckCapacity(*post, usage[post.ID], 0, delta)
+}
+
+// ErrTeamClosed ทีมที่ถูกปฏิเสธ หมดเวลายืนยันที่นั่ง หรือถอนการสมัครแล้ว แก้ไขทีมหรือรับสมาชิกเพิ่มไม่ได้
+var ErrTeamClosed = errors.New("ทีมนี้ถูกปฏิเสธ หมดเวลายืนยัน หรือถอนการสมัครแล้ว")
+
+// CheckTeamOpen ทีมต้องยังอยู่ในการพิจารณา (ใช้ที่นั่งอยู่หรือรอในคิวสำรอง) จึงแก้ไขหรือรับสมาชิกเพิ่มได้
+func CheckTeamOpen(status string) error {
+	if HoldsPlace(status) {
+		return nil
+	}
+	return ErrTeamClosed
+}
+
+func isActiveRegistration(status string) bool {
+	for _, active := range entity.ActiveRegistrationStatuses {
+		if status == active {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueUints(values []uint) []uint {
+	seen := make(map[uint]bool, len(values))
+	unique := make([]uint, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// IsMember ตรวจสอบว่าผู้ใช้เป็นสมาชิกของทีมที่ลงทะเบียนนี้หรือไม่
+func (s *RegistrationService) IsMember(registrationID string, userID uint) bool {
+	var count int64
+	s.db.Table("user_registrations").
+		Where("registration_id = ? AND user_id = ?", registrationID, userID).
+		Count(&count)
+	return count > 0
+}
+
+// IsPostOwner ตรวจสอบว่าผู้ใช้เป็นผู้สร้างกิจกรรมที่ทีมนี้ลงทะเบียน
+func (s *RegistrationService) IsPostOwner(registrationID string, userID uint) bool {
+	var registration entity.Registration
+	if err := s.db.Select("post_id").First(&registration, registrationID).Error; err != nil || registration.PostID == nil {
+		return false
+	}
+	return IsPostOwner(s.db, *registration.PostID, userID)
 }
 
 func (s *RegistrationService) GetUserRegistrations(userID uint) ([]entity.Registration, error) {
 	var registrations []entity.Registration
 
-	err := s.db.Debug().
+	err := preloadAnswers(s.db.Debug()).
 		Preload("Post").
 		Preload("Post.Status").
 		Preload("Users").
@@ -244,5 +571,10 @@ func (s *RegistrationService) GetUserRegistrations(userID uint) ([]entity.Regist
 		return nil, err
 	}
 
+	for i := range registrations {
+		s.withWaitlistPosition(&registrations[i])
+		s.withLeader(&registrations[i])
+	}
+
 	return registrations, nil
 }
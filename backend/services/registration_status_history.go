@@ -0,0 +1,133 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+const (
+	// StatusNotificationInterval ความถี่ที่ส่งอีเมลแจ้งสถานะทีมที่เปลี่ยนไป
+	StatusNotificationInterval = time.Minute
+	// statusNotificationBatch จำนวนประวัติที่แจ้งต่อรอบ
+	statusNotificationBatch = 100
+)
+
+// registrationStatusLabels ชื่อสถานะที่ใช้ในอีเมลแจ้งเตือน
+var registrationStatusLabels = map[string]string{
+	entity.RegistrationStatusPending:    "รอการอนุมัติ",
+	entity.RegistrationStatusApproved:   "อนุมัติแล้ว",
+	entity.RegistrationStatusRejected:   "ไม่อนุมัติ",
+	entity.RegistrationStatusWaitlisted: "อยู่ในคิวสำรอง",
+	entity.RegistrationStatusOffered:    "ได้ที่นั่งจากคิวสำรอง รอยืนยัน",
+	entity.RegistrationStatusExpired:    "หมดเวลายืนยันที่นั่ง",
+	entity.RegistrationStatusWithdrawn:  "ถอนการสมัครแล้ว",
+}
+
+// RegistrationStatusLabel ชื่อสถานะภาษาไทย (ไม่รู้จักคืนค่าเดิม)
+func RegistrationStatusLabel(status string) string {
+	if label, ok := registrationStatusLabels[status]; ok {
+		return label
+	}
+	return status
+}
+
+// actorRef ผู้ใช้ที่ทำรายการ 0 หมายถึงระบบ
+func actorRef(userID uint) *uint {
+	if userID == 0 {
+		return nil
+	}
+	return &userID
+}
+
+// recordStatusChange บันทึกประวัติเมื่อสถานะของทีมเปลี่ยน เรียกภายใน transaction เดียวกับที่เปลี่ยนสถานะ
+func recordStatusChange(tx *gorm.DB, registrationID uint, from, to, reason string, actorID *uint) error {
+	if from == to {
+		return nil
+	}
+	return tx.Create(&entity.RegistrationStatusHistory{
+		RegistrationID: registrationID,
+		FromStatus:     from,
+		ToStatus:       to,
+		Reason:         reason,
+		ActorID:        actorID,
+	}).Error
+}
+
+// StatusChangeMessage หัวเรื่องและเนื้อหาอีเมลแจ้งสมาชิกเมื่อสถานะทีมเปลี่ยน
+func StatusChangeMessage(registration entity.Registration, change entity.RegistrationStatusHistory) (string, string) {
+	title := "กิจกรรม"
+	if registration.Post != nil {
+		title = registration.Post.Title
+	}
+	// ชื่อทีมมาจากผู้ใช้ ตัดการขึ้นบรรทัดใหม่ออกก่อนนำไปใส่หัวเรื่อง
+	teamName := strings.Join(strings.Fields(registration.TeamName), " ")
+	subject := fmt.Sprintf("ทีม %s: %s", teamName, RegistrationStatusLabel(change.ToStatus))
+
+	body := fmt.Sprintf("สถานะการสมัคร %s ของทีม %s เปลี่ยนเป็น \"%s\"", title, registration.TeamName, RegistrationStatusLabel(change.ToStatus))
+	if change.FromStatus != "" {
+		body += fmt.Sprintf(" (จากเดิม \"%s\")", RegistrationStatusLabel(change.FromStatus))
+	}
+	body += "\n"
+	if change.Reason != "" {
+		body += "\nเหตุผล: " + change.Reason + "\n"
+	}
+	if change.ToStatus == entity.RegistrationStatusOffered && registration.OfferExpiresAt != nil {
+		body += "\nกรุณายืนยันที่นั่งภายใน " + registration.OfferExpiresAt.Format("02/01/2006 15:04") + "\n"
+	}
+	body += "\nดูรายละเอียด: " + frontendBaseURL() + "/student/registrations/" + strconv.Itoa(int(registration.ID)) + "\n"
+	return subject, body
+}
+
+// NotifyStatusChanges ส่งอีเมลแจ้งสมาชิกทีมตามประวัติสถานะที่ยังไม่แจ้ง (ไม่แจ้งผู้ที่เปลี่ยนสถานะเอง) คืนจำนวนประวัติที่แจ้ง
+// ประวัติถูกทำเครื่องหมายว่าแจ้งแล้วแม้ส่งไม่สำเร็จ เพื่อไม่ให้ส่งซ้ำทุกรอบ
+func NotifyStatusChanges(db *gorm.DB, mailer Mailer, now time.Time) (int, error) {
+	var changes []entity.RegistrationStatusHistory
+	if err := db.Where("notified_at IS NULL").Order("id").Limit(statusNotificationBatch).Find(&changes).Error; err != nil {
+		return 0, err
+	}
+
+	for _, change := range changes {
+		var registration entity.Registration
+		if err := db.Preload("Post").Preload("Users").First(&registration, change.RegistrationID).Error; err == nil {
+			subject, body := StatusChangeMessage(registration, change)
+			for _, user := range registration.Users {
+				if user.Email == "" || (change.ActorID != nil && *change.ActorID == user.ID) {
+					continue
+				}
+				if err := mailer.Send(user.Email, subject, "สวัสดีคุณ "+user.FirstName+"\n\n"+body); err != nil {
+					log.Printf("notify registration %d status to user %d failed: %v", registration.ID, user.ID, err)
+				}
+			}
+		}
+		if err := db.Model(&entity.RegistrationStatusHistory{}).Where("id = ?", change.ID).Update("notified_at", now).Error; err != nil {
+			return 0, err
+		}
+	}
+	return len(changes), nil
+}
+
+// RunStatusNotificationScheduler แจ้งสถานะทีมที่เปลี่ยนไปเป็นระยะ (เรียกด้วย goroutine ตอนเริ่มเซิร์ฟเวอร์)
+func RunStatusNotificationScheduler(db *gorm.DB, mailer Mailer, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := NotifyStatusChanges(db, mailer, time.Now()); err != nil {
+			log.Printf("status notification scheduler: %v", err)
+		} else if n > 0 {
+			log.Printf("status notification scheduler: notified %d status change(s)", n)
+		}
+		<-ticker.C
+	}
+}
+
+// preloadStatusHistory preload ประวัติสถานะเรียงตามเวลา พร้อมชื่อผู้เปลี่ยน
+func preloadStatusHistory(db *gorm.DB) *gorm.DB {
+	return db.Preload("StatusHistory", func(tx *gorm.DB) *gorm.DB { return tx.Order("created_at, id") }).
+		Preload("StatusHistory.Actor", func(tx *gorm.DB) *gorm.DB { return tx.Select("id", "sut_id", "first_name", "last_name") })
+}
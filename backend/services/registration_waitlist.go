@@ -0,0 +1,205 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+const (
+	// WaitlistOfferWindow เวลาที่ทีมจากคิวสำรองมีเพื่อยืนยันที่นั่ง ก่อนที่นั่งจะส่งต่อให้ทีมถัดไป
+	WaitlistOfferWindow = 48 * time.Hour
+	// WaitlistSchedulerInterval ความถี่ที่ตรวจที่นั่งที่หมดเวลายืนยัน
+	WaitlistSchedulerInterval = time.Minute
+)
+
+var (
+	ErrNotOffered   = errors.New("ทีมนี้ไม่ได้อยู่ในสถานะรอยืนยันที่นั่ง")
+	ErrOfferExpired = errors.New("หมดเวลายืนยันที่นั่งแล้ว")
+)
+
+// waitlistOrder ลำดับคิวสำรอง: สมัครก่อนได้ก่อน
+const waitlistOrder = "registration_date ASC, id ASC"
+
+// NextWaitlistOffers เลือกทีมจากคิวสำรอง (เรียงตามลำดับคิวแล้ว) ที่รับเข้าได้ภายในที่ว่างปัจจุบัน
+// ทีมที่ใหญ่เกินที่นั่งที่เหลือจะถูกข้ามไปทีมถัดไปที่รับได้ โดยยังคงลำดับในคิวไว้
+func NextWaitlistOffers(post entity.Post, usage CapacityUsage, teamSizes []int) []int {
+	var offers []int
+	for i, size := range teamSizes {
+		if err := CheckCapacity(post, usage, 1, size); err != nil {
+			if errors.Is(err, ErrTeamsFull) {
+				break
+			}
+			continue
+		}
+		offers = append(offers, i)
+		usage.Teams++
+		usage.Seats += int64(size)
+	}
+	return offers
+}
+
+// promoteWaitlist ให้ที่นั่งแก่ทีมในคิวสำรองของโพสต์จนกว่าที่นั่งจะเต็ม คืนทีมที่ได้ที่นั่ง
+// ต้องเรียกภายใน transaction เพราะล็อกแถวของโพสต์
+func promoteWaitlist(tx *gorm.DB, postID uint, now time.Time) ([]entity.Registration, error) {
+	post, err := lockPost(tx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	var waiting []entity.Registration
+	if err := tx.Where("post_id = ? AND status = ?", postID, entity.RegistrationStatusWaitlisted).
+		Order(waitlistOrder).
+		Find(&waiting).Error; err != nil {
+		return nil, err
+	}
+	if len(waiting) == 0 {
+		return nil, nil
+	}
+
+	usage, err := capacityUsage(tx, []uint{postID})
+	if err != nil {
+		return nil, err
+	}
+	sizes := make([]int, len(waiting))
+	for i := range waiting {
+		sizes[i] = int(tx.Model(&waiting[i]).Association("Users").Count())
+	}
+
+	deadline := now.Add(WaitlistOfferWindow)
+	var promoted []entity.Registration
+	for _, i := range NextWaitlistOffers(*post, usage[postID], sizes) {
+		registration := waiting[i]
+		if err := tx.Model(&registration).Updates(map[string]interface{}{
+			"status":           entity.RegistrationStatusOffered,
+			"offer_expires_at": deadline,
+		}).Error; err != nil {
+			return nil, err
+		}
+		if err := recordStatusChange(tx, registration.ID, entity.RegistrationStatusWaitlisted, entity.RegistrationStatusOffered, "", nil); err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, registration)
+	}
+	return promoted, nil
+}
+
+// PromoteWaitlist ให้ที่นั่งที่ว่างแก่คิวสำรองของโพสต์ เรียกหลังมีที่นั่งคืน (ปฏิเสธ ลบ ถอนตัว ลดสมาชิก หรือเพิ่มโควตา)
+func PromoteWaitlist(db *gorm.DB, postID uint, now time.Time) {
+	var promoted []entity.Registration
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		promoted, err = promoteWaitlist(tx, postID, now)
+		return err
+	})
+	if err != nil {
+		log.Printf("promote waitlist of post %d failed: %v", postID, err)
+		return
+	}
+	for _, registration := range promoted {
+		log.Printf("waitlist: registration %d of post %d offered a seat until %s", registration.ID, postID, now.Add(WaitlistOfferWindow).Format(time.RFC3339))
+	}
+}
+
+// ExpireWaitlistOffers ปิดที่นั่งที่ไม่ได้ยืนยันภายในกำหนด แล้วส่งต่อให้ทีมถัดไปในคิว คืนจำนวนที่หมดเวลา
+func ExpireWaitlistOffers(db *gorm.DB, now time.Time) (int, error) {
+	var offers []entity.Registration
+	if err := db.Select("id", "post_id").
+		Where("status = ? AND offer_expires_at < ?", entity.RegistrationStatusOffered, now).
+		Find(&offers).Error; err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, offer := range offers {
+		// เงื่อนไขสถานะเดิมกันการเขียนทับถ้าทีมยืนยันไประหว่างนี้
+		changed := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&entity.Registration{}).
+				Where("id = ? AND status = ?", offer.ID, entity.RegistrationStatusOffered).
+				Updates(map[string]interface{}{"status": entity.RegistrationStatusExpired, "offer_expires_at": nil})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			changed = true
+			if err := cancelPendingInvitations(tx, offer.ID); err != nil {
+				return err
+			}
+			return recordStatusChange(tx, offer.ID, entity.RegistrationStatusOffered, entity.RegistrationStatusExpired, "ไม่ยืนยันที่นั่งภายในกำหนด", nil)
+		})
+		if err != nil {
+			return expired, err
+		}
+		if !changed {
+			continue
+		}
+		expired++
+		if offer.PostID != nil {
+			PromoteWaitlist(db, *offer.PostID, now)
+		}
+	}
+	return expired, nil
+}
+
+// RunWaitlistScheduler ตรวจที่นั่งที่หมดเวลายืนยันเป็นระยะ (เรียกด้วย goroutine ตอนเริ่มเซิร์ฟเวอร์)
+func RunWaitlistScheduler(db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := ExpireWaitlistOffers(db, time.Now()); err != nil {
+			log.Printf("waitlist scheduler: %v", err)
+		} else if n > 0 {
+			log.Printf("waitlist scheduler: expired %d offer(s)", n)
+		}
+		<-ticker.C
+	}
+}
+
+// ConfirmOffer ทีมยืนยันที่นั่งที่ได้จากคิวสำรอง สถานะกลับเป็น pending เพื่อรอผู้จัดอนุมัติตามปกติ
+func (s *RegistrationService) ConfirmOffer(id string, actorID uint, now time.Time) (*entity.Registration, error) {
+	var registration entity.Registration
+	if err := s.db.First(&registration, id).Error; err != nil {
+		return nil, errors.New("registration not found")
+	}
+	if registration.Status != entity.RegistrationStatusOffered {
+		return nil, ErrNotOffered
+	}
+	if registration.OfferExpiresAt != nil && now.After(*registration.OfferExpiresAt) {
+		return nil, ErrOfferExpired
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Registration{}).
+			Where("id = ? AND status = ?", registration.ID, entity.RegistrationStatusOffered).
+			Updates(map[string]interface{}{"status": entity.RegistrationStatusPending, "offer_expires_at": nil})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOfferExpired
+		}
+		return recordStatusChange(tx, registration.ID, entity.RegistrationStatusOffered, entity.RegistrationStatusPending, "", actorRef(actorID))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRegistrationByID(id)
+}
+
+// withWaitlistPosition เติมลำดับคิวของทีมที่รออยู่ (เริ่มที่ 1)
+func (s *RegistrationService) withWaitlistPosition(registrations ...*entity.Registration) {
+	for _, registration := range registrations {
+		if registration.Status != entity.RegistrationStatusWaitlisted || registration.PostID == nil {
+			continue
+		}
+		var ahead int64
+		s.db.Model(&entity.Registration{}).
+			Where("post_id = ? AND status = ?", *registration.PostID, entity.RegistrationStatusWaitlisted).
+			Where("registration_date < ? OR (registration_date = ? AND id < ?)", registration.RegistrationDate, registration.RegistrationDate, registration.ID).
+			Count(&ahead)
+		registration.WaitlistPosition = int(ahead) + 1
+	}
+}
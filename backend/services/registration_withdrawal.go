@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+// PointTypeLateWithdrawal ประเภทรายการในสมุดคะแนนเมื่อถูกหักเพราะถอนการสมัครช้า
+const PointTypeLateWithdrawal = "late_withdrawal"
+
+var (
+	ErrCannotWithdraw        = errors.New("ทีมนี้ถอนการสมัครไม่ได้ในสถานะปัจจุบัน")
+	ErrRegistrationWithdrawn = errors.New("ทีมนี้ถอนการสมัครแล้ว")
+	ErrActivityEnded         = errors.New("กิจกรรมสิ้นสุดแล้ว ถอนการสมัครไม่ได้")
+	ErrWithdrawalReason      = errors.New("กรุณาระบุเหตุผลการถอนการสมัคร")
+)
+
+// withdrawableStatuses สถานะที่ถอนการสมัครได้
+var withdrawableStatuses = []string{
+	entity.RegistrationStatusPending,
+	entity.RegistrationStatusApproved,
+	entity.RegistrationStatusWaitlisted,
+	entity.RegistrationStatusOffered,
+}
+
+// IsLateWithdrawal ถอนการสมัครภายใน WithdrawalCutoffHours ชั่วโมงก่อนปิดรับสมัคร (Stop) หรือหลังปิดรับสมัครแล้ว
+// ไม่ตั้งกำหนดไม่นับว่าช้า ช่วงรับสมัครอยู่ภายในช่วงจัดกิจกรรมเสมอ (ดู ValidatePost) จึงนับจาก StartDate ไม่ได้
+func IsLateWithdrawal(post entity.Post, now time.Time) bool {
+	if post.WithdrawalCutoffHours == 0 {
+		return false
+	}
+	cutoff := post.Stop.Add(-time.Duration(post.WithdrawalCutoffHours) * time.Hour)
+	return !now.Before(cutoff)
+}
+
+// WithdrawalPenalty คะแนนที่หักจากสมาชิกแต่ละคน เฉพาะทีมที่ถือที่นั่งอยู่และถอนหลังกำหนด
+// ทีมในคิวสำรองไม่ได้กันที่นั่งของใคร จึงไม่ถูกหัก
+func WithdrawalPenalty(post entity.Post, status string, now time.Time) uint {
+	if !isActiveRegistration(status) || !IsLateWithdrawal(post, now) {
+		return 0
+	}
+	return post.LateWithdrawalPenalty
+}
+
+// Withdraw ทีมถอนการสมัคร เก็บทีมไว้ในสถานะ withdrawn พร้อมเหตุผล ยกเลิกคำเชิญที่ค้าง
+// หักคะแนนสมาชิกผ่านสมุดคะแนนเมื่อถอนช้า แล้วส่งที่นั่งที่ว่างต่อให้คิวสำรอง
+func (s *RegistrationService) Withdraw(id, reason string, actorID uint, ip string, now time.Time) (*entity.Registration, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrWithdrawalReason
+	}
+
+	var before entity.Registration
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Users").First(&before, id).Error; err != nil {
+			return errors.New("registration not found")
+		}
+		if before.Status == entity.RegistrationStatusWithdrawn {
+			return ErrRegistrationWithdrawn
+		}
+		if !slices.Contains(withdrawableStatuses, before.Status) {
+			return ErrCannotWithdraw
+		}
+
+		var penalty uint
+		if before.PostID != nil {
+			post, err := lockPost(tx, *before.PostID)
+			if err != nil {
+				return err
+			}
+			if !post.StopDate.IsZero() && !now.Before(post.StopDate) {
+				return ErrActivityEnded
+			}
+			penalty = WithdrawalPenalty(*post, before.Status, now)
+		}
+
+		if err := tx.Model(&entity.Registration{}).Where("id = ?", before.ID).Updates(map[string]interface{}{
+			"status":             entity.RegistrationStatusWithdrawn,
+			"withdrawal_reason":  reason,
+			"withdrawn_at":       now,
+			"withdrawal_penalty": penalty,
+			"offer_expires_at":   nil,
+		}).Error; err != nil {
+			return err
+		}
+		if err := recordStatusChange(tx, before.ID, before.Status, entity.RegistrationStatusWithdrawn, reason, actorRef(actorID)); err != nil {
+			return err
+		}
+		if err := cancelPendingInvitations(tx, before.ID); err != nil {
+			return err
+		}
+
+		if penalty == 0 {
+			return nil
+		}
+		points := NewPointService(tx)
+		for _, user := range before.Users {
+			if err := points.CreatePointRecord(&entity.PointRecord{
+				UserID:         user.ID,
+				Points:         -int(penalty),
+				Type:           PointTypeLateWithdrawal,
+				RegistrationID: &before.ID,
+			}); err != nil {
+				return err
+			}
+			if err := points.AddPointsToUser(user.ID, -int(penalty)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if isActiveRegistration(before.Status) && before.PostID != nil {
+		PromoteWaitlist(s.db, *before.PostID, now)
+	}
+
+	// เทียบเฉพาะฟิลด์ของทีม ไม่รวมข้อมูลที่ preload
+	var after entity.Registration
+	s.db.First(&after, before.ID)
+	before.Users = nil
+	RecordChange(s.db, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionRegistrationWithdrawn,
+		TargetType: "registration",
+		TargetID:   strconv.Itoa(int(before.ID)),
+		IPAddress:  ip,
+		Detail:     reason,
+	}, before, after)
+	return s.GetRegistrationByID(id)
+}
+
+// WithdrawalReport รายงานทีมที่ถอนการสมัครของกิจกรรม ล่าสุดก่อน
+func (s *RegistrationService) WithdrawalReport(postID uint) (dto.WithdrawalReport, error) {
+	var registrations []entity.Registration
+	if err := s.db.Preload("Users").
+		Where("post_id = ? AND status = ?", postID, entity.RegistrationStatusWithdrawn).
+		Order("withdrawn_at DESC, id DESC").
+		Find(&registrations).Error; err != nil {
+		return dto.WithdrawalReport{}, err
+	}
+	return dto.NewWithdrawalReport(postID, registrations), nil
+}
@@ -116,8 +116,29 @@ func (s *ResultsService) ListResults() ([]entity.Result, error) {
 	return results, nil
 }
 
-func (s *ResultsService) UpdateResult(result *entity.Result) error {
-	return s.DB.Save(result).Error
+// UpdateResult บันทึกผลรางวัลที่แก้ไข พร้อมค่าก่อน-หลังและเหตุผลลง audit log
+func (s *ResultsService) UpdateResult(result *entity.Result, actorID uint, ip string) error {
+	var before entity.Result
+	if err := s.DB.First(&before, result.ID).Error; err != nil {
+		return err
+	}
+	if err := s.DB.Save(result).Error; err != nil {
+		return err
+	}
+
+	reason := ""
+	if result.EditReason != nil {
+		reason = *result.EditReason
+	}
+	RecordChange(s.DB, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionResultUpdated,
+		TargetType: "result",
+		TargetID:   strconv.Itoa(int(result.ID)),
+		IPAddress:  ip,
+		Detail:     reason,
+	}, before, result)
+	return nil
 }
 
 // GetAllResultsWithDetails - ดึง results ทั้งหมดพร้อม preload Award และ Registration
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultSearchLimit = 5
+	MaxSearchLimit     = 20
+	// MaxSearchQueryLength จำนวนตัวอักษรสูงสุดของคำค้น
+	MaxSearchQueryLength = 100
+)
+
+const (
+	SearchTypePosts      = "posts"
+	SearchTypeUsers      = "users"
+	SearchTypePortfolios = "portfolios"
+)
+
+var SearchTypes = []string{SearchTypePosts, SearchTypeUsers, SearchTypePortfolios}
+
+var (
+	ErrSearchQueryRequired = errors.New("กรุณาระบุคำค้นหา")
+	ErrInvalidSearchType   = errors.New("ประเภทการค้นหาไม่ถูกต้อง (posts, users, portfolios)")
+)
+
+// SearchViewer ผู้ค้นหา ใช้กำหนดว่าเห็นผลลัพธ์ใดได้บ้าง
+type SearchViewer struct {
+	UserID           uint
+	ManageActivities bool
+	ReviewPortfolios bool
+}
+
+// searchSource ตารางที่ค้นหาได้ title/subtitle เป็น SQL expression ที่แสดงในผลลัพธ์
+// columns ใช้ค้นแบบ substring เมื่อ full-text ไม่เจอ (เช่นภาษาไทยที่ตัดคำไม่ได้)
+type searchSource struct {
+	table    string
+	title    string
+	subtitle string
+	columns  []string
+	scope    func(tx *gorm.DB, viewer SearchViewer) *gorm.DB
+}
+
+var searchSources = map[string]searchSource{
+	SearchTypePosts: {
+		table:    "posts",
+		title:    "posts.title",
+		subtitle: "posts.organizer",
+		columns:  []string{"posts.title", "posts.organizer"},
+		scope: func(tx *gorm.DB, viewer SearchViewer) *gorm.DB {
+			if viewer.ManageActivities {
+				return tx
+			}
+			return tx.Where("(posts.status_id IN ? OR posts.user_id = ?)", entity.PublishedPostStatuses, viewer.UserID)
+		},
+	},
+	SearchTypeUsers: {
+		table:    "users",
+		title:    "users.first_name || ' ' || users.last_name",
+		subtitle: "users.sut_id",
+		columns:  []string{"users.sut_id", "users.first_name", "users.last_name"},
+		scope: func(tx *gorm.DB, viewer SearchViewer) *gorm.DB {
+			return tx.Where("users.anonymized_at IS NULL AND users.deactivated_at IS NULL")
+		},
+	},
+	SearchTypePortfolios: {
+		table:    "portfolios",
+		title:    "portfolios.title",
+		subtitle: "portfolios.port_type",
+		columns:  []string{"portfolios.title"},
+		scope: func(tx *gorm.DB, viewer SearchViewer) *gorm.DB {
+			if viewer.ReviewPortfolios {
+				return tx
+			}
+			return tx.Where("(portfolios.user_id = ? OR portfolios.portfolio_status_id IN (SELECT id FROM portfolio_statuses WHERE status_name = ?))",
+				viewer.UserID, "Approved")
+		},
+	},
+}
+
+// NormalizeSearchQuery ตัดช่องว่างส่วนเกินและจำกัดความยาวคำค้น
+func NormalizeSearchQuery(q string) (string, error) {
+	q = strings.Join(strings.Fields(q), " ")
+	if q == "" {
+		return "", ErrSearchQueryRequired
+	}
+	if runes := []rune(q); len(runes) > MaxSearchQueryLength {
+		q = strings.TrimSpace(string(runes[:MaxSearchQueryLength]))
+	}
+	return q, nil
+}
+
+// ParseSearchTypes แปลง type=posts,users เป็นรายการหมวด (ค่าว่างคือทุกหมวด)
+func ParseSearchTypes(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return SearchTypes, nil
+	}
+	var types []string
+	for _, t := range strings.Split(value, ",") {
+		t = strings.TrimSpace(t)
+		if _, ok := searchSources[t]; !ok {
+			return nil, ErrInvalidSearchType
+		}
+		types = append(types, t)
+	}
+	return uniqueStrings(types), nil
+}
+
+// SearchTSQuery สร้าง tsquery แบบ prefix จากคำค้น เช่น "go hack" → "go:* & hack:*"
+// ตัดอักขระที่ไม่ใช่ตัวอักษร/ตัวเลขออก (เก็บสระและวรรณยุกต์ไทยไว้) คืนค่าว่างถ้าไม่เหลือคำ
+func SearchTSQuery(q string) string {
+	var terms []string
+	for _, word := range strings.Fields(q) {
+		term := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, word)
+		if term != "" {
+			terms = append(terms, term+":*")
+		}
+	}
+	return strings.Join(terms, " & ")
+}
+
+// EscapeLike escape อักขระพิเศษของ LIKE ให้คำค้นถูกตีความตามตัวอักษร
+func EscapeLike(q string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(q)
+}
+
+type SearchService struct {
+	db *gorm.DB
+}
+
+func NewSearchService(db *gorm.DB) *SearchService {
+	return &SearchService{db: db}
+}
+
+// Search ค้นหาโพสต์ ผู้ใช้ และผลงานพร้อมกัน ผลลัพธ์เรียงตามคะแนนภายในหมวด
+// และหมวดที่มีผลลัพธ์ตรงที่สุดขึ้นก่อน
+func (s *SearchService) Search(query dto.SearchQuery, viewer SearchViewer) (*dto.SearchResponse, error) {
+	q, err := NormalizeSearchQuery(query.Q)
+	if err != nil {
+		return nil, err
+	}
+	types, err := ParseSearchTypes(query.Type)
+	if err != nil {
+		return nil, err
+	}
+	limit := query.Limit
+	if limit < 1 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	groups := make([]dto.SearchGroup, 0, len(types))
+	for _, t := range types {
+		group, err := s.searchSource(t, searchSources[t], q, limit, viewer)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return topRank(groups[i]) > topRank(groups[j])
+	})
+	return &dto.SearchResponse{Query: q, Groups: groups}, nil
+}
+
+func topRank(group dto.SearchGroup) float64 {
+	if len(group.Items) == 0 {
+		return -1
+	}
+	return group.Items[0].Rank
+}
+
+func (s *SearchService) searchSource(kind string, src searchSource, q string, limit int, viewer SearchViewer) (dto.SearchGroup, error) {
+	tsquery := SearchTSQuery(q)
+	contains := "%" + EscapeLike(q) + "%"
+	prefix := EscapeLike(q) + "%"
+
+	// เงื่อนไข: full-text ตรง หรือพบเป็น substring ในคอลัมน์สั้น
+	var matches, prefixes, substrings []string
+	var matchArgs, prefixArgs, substringArgs []any
+	if tsquery != "" {
+		matches = append(matches, src.table+".search_vector @@ to_tsquery('simple', ?)")
+		matchArgs = append(matchArgs, tsquery)
+	}
+	for _, column := range src.columns {
+		matches = append(matches, column+" ILIKE ?")
+		matchArgs = append(matchArgs, contains)
+		prefixes = append(prefixes, column+" ILIKE ?")
+		prefixArgs = append(prefixArgs, prefix)
+		substrings = append(substrings, column+" ILIKE ?")
+		substringArgs = append(substringArgs, contains)
+	}
+	match := "(" + strings.Join(matches, " OR ") + ")"
+
+	filtered := func() *gorm.DB {
+		tx := s.db.Table(src.table).Where(src.table + ".deleted_at IS NULL")
+		return src.scope(tx, viewer).Where(match, matchArgs...)
+	}
+
+	group := dto.SearchGroup{Type: kind, Items: []dto.SearchHit{}}
+	if err := filtered().Count(&group.Total).Error; err != nil {
+		return group, err
+	}
+	if group.Total == 0 {
+		return group, nil
+	}
+
+	// คะแนน: ts_rank ของ full-text + โบนัสเมื่อคำค้นเป็นคำขึ้นต้น (0.5) หรืออยู่กลางข้อความ (0.2)
+	rank := "0"
+	var rankArgs []any
+	if tsquery != "" {
+		rank = "ts_rank(" + src.table + ".search_vector, to_tsquery('simple', ?))"
+		rankArgs = append(rankArgs, tsquery)
+	}
+	rank += " + CASE WHEN " + strings.Join(prefixes, " OR ") + " THEN 0.5 WHEN " + strings.Join(substrings, " OR ") + " THEN 0.2 ELSE 0 END"
+	rankArgs = append(append(rankArgs, prefixArgs...), substringArgs...)
+
+	selectSQL := src.table + ".id AS id, " + src.title + " AS title, " + src.subtitle + " AS subtitle, " + rank + " AS rank"
+	err := filtered().
+		Select(selectSQL, rankArgs...).
+		Order("rank DESC, " + src.table + ".id DESC").
+		Limit(limit).
+		Scan(&group.Items).Error
+	return group, err
+}
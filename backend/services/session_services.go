@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidRefreshToken = errors.New("refresh token ไม่ถูกต้องหรือหมดอายุ กรุณาเข้าสู่ระบบใหม่")
+
+// revokedSessions เก็บ session ที่ถูกเพิกถอนไว้ในหน่วยความจำจนกว่า access token ของ session นั้นจะหมดอายุ
+// AuthMiddleware จึงตรวจสอบได้ทุก request โดยไม่ต้อง query DB
+var revokedSessions = struct {
+	sync.RWMutex
+	until map[string]time.Time
+}{until: make(map[string]time.Time)}
+
+func markSessionsRevoked(sessionIDs ...string) {
+	until := time.Now().Add(config.AccessTokenTTL)
+	revokedSessions.Lock()
+	defer revokedSessions.Unlock()
+	for _, sid := range sessionIDs {
+		revokedSessions.until[sid] = until
+	}
+	for sid, exp := range revokedSessions.until {
+		if time.Now().After(exp) {
+			delete(revokedSessions.until, sid)
+		}
+	}
+}
+
+// IsSessionRevoked ใช้ใน AuthMiddleware เพื่อปฏิเสธ access token ของ session ที่ logout ไปแล้ว
+func IsSessionRevoked(sessionID string) bool {
+	revokedSessions.RLock()
+	defer revokedSessions.RUnlock()
+	exp, ok := revokedSessions.until[sessionID]
+	return ok && time.Now().Before(exp)
+}
+
+// LoadRevokedSessions โหลด session ที่เพิ่งถูกเพิกถอนจาก DB ตอนเริ่มเซิร์ฟเวอร์
+// เพื่อไม่ให้ access token ที่ยังไม่หมดอายุกลับมาใช้ได้หลัง restart
+func LoadRevokedSessions(db *gorm.DB) {
+	var tokens []entity.RefreshToken
+	if err := db.Select("session_id", "revoked_at").
+		Where("session_id IN (?)", db.Model(&entity.RefreshToken{}).
+			Select("session_id").
+			Where("revoked_at > ?", time.Now().Add(-config.AccessTokenTTL))).
+		Find(&tokens).Error; err != nil {
+		log.Printf("Error loading revoked sessions: %v", err)
+		return
+	}
+	RestoreRevokedSessions(tokens, time.Now())
+}
+
+// RestoreRevokedSessions ทำเครื่องหมาย session ที่ถูกเพิกถอนภายในอายุ access token จาก refresh token ของ session นั้น
+// session ที่ยังมี refresh token ที่ไม่ถูกเพิกถอนเหลืออยู่คือ session ที่ถูกหมุน token ตามปกติ ไม่ได้ logout
+func RestoreRevokedSessions(tokens []entity.RefreshToken, now time.Time) {
+	since := now.Add(-config.AccessTokenTTL)
+	recent := map[string]bool{}
+	live := map[string]bool{}
+	for _, t := range tokens {
+		switch {
+		case t.RevokedAt == nil:
+			live[t.SessionID] = true
+		case t.RevokedAt.After(since):
+			recent[t.SessionID] = true
+		}
+	}
+
+	var sessionIDs []string
+	for sid := range recent {
+		if !live[sid] {
+			sessionIDs = append(sessionIDs, sid)
+		}
+	}
+	markSessionsRevoked(sessionIDs...)
+}
+
+type SessionService struct {
+	db *gorm.DB
+}
+
+func NewSessionService(db *gorm.DB) *SessionService {
+	return &SessionService{db: db}
+}
+
+// CreateSession เริ่ม session ใหม่ (หนึ่งอุปกรณ์) และออก access token + refresh token
+func (s *SessionService) CreateSession(user *entity.User, userAgent, ip string) (*dto.TokenPair, error) {
+	return s.issueTokens(s.db, user, uuid.New().String(), userAgent, ip)
+}
+
+func (s *SessionService) issueTokens(tx *gorm.DB, user *entity.User, sessionID, userAgent, ip string) (*dto.TokenPair, error) {
+	refreshToken, err := config.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	record := entity.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: config.HashToken(refreshToken),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(config.RefreshTokenTTL),
+		UserAgent: userAgent,
+		IPAddress: ip,
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return nil, err
+	}
+
+	roleName := ""
+	if user.Role != nil {
+		roleName = user.Role.Name
+	}
+	accessToken, err := config.GenerateJWT(user.ID, user.SutId, roleName, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(config.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// Refresh หมุน refresh token: token เดิมถูกเพิกถอนและออก token ใหม่ใน session เดิม
+// ถ้ามีการใช้ token ที่ถูกหมุนไปแล้วซ้ำ ถือว่า token ถูกขโมยและเพิกถอนทั้ง session
+func (s *SessionService) Refresh(refreshToken, userAgent, ip string) (*dto.TokenPair, error) {
+	var pair *dto.TokenPair
+	var reusedSessionID string
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var record entity.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", config.HashToken(refreshToken)).
+			First(&record).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		if record.RevokedAt != nil {
+			reusedSessionID = record.SessionID
+			return ErrInvalidRefreshToken
+		}
+		if time.Now().After(record.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		var user entity.User
+		if err := tx.Preload("Role").First(&user, record.UserID).Error; err != nil || user.DeactivatedAt != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		now := time.Now()
+		if err := tx.Model(&record).Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+
+		issued, err := s.issueTokens(tx, &user, record.SessionID, userAgent, ip)
+		if err != nil {
+			return err
+		}
+		pair = issued
+		return nil
+	})
+
+	if reusedSessionID != "" {
+		log.Printf("Refresh token reuse detected, revoking session %s", reusedSessionID)
+		if revokeErr := s.RevokeSession(reusedSessionID); revokeErr != nil {
+			log.Printf("Error revoking session %s: %v", reusedSessionID, revokeErr)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Logout เพิกถอน session ของ refresh token ที่ส่งมา (ออกจากระบบเฉพาะอุปกรณ์นี้)
+func (s *SessionService) Logout(refreshToken string) error {
+	var record entity.RefreshToken
+	if err := s.db.Where("token_hash = ?", config.HashToken(refreshToken)).First(&record).Error; err != nil {
+		return ErrInvalidRefreshToken
+	}
+	return s.RevokeSession(record.SessionID)
+}
+
+// RevokeSession เพิกถอน refresh token ทั้งหมดของ session และปฏิเสธ access token ที่ยังไม่หมดอายุ
+func (s *SessionService) RevokeSession(sessionID string) error {
+	if err := s.db.Model(&entity.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+	markSessionsRevoked(sessionID)
+	return nil
+}
+
+// RevokeAllSessions ออกจากระบบทุกอุปกรณ์ของผู้ใช้
+func (s *SessionService) RevokeAllSessions(userID uint) error {
+	var sessionIDs []string
+	if err := s.db.Model(&entity.RefreshToken{}).
+		Where("user_id = ? AND (revoked_at IS NULL OR revoked_at > ?)", userID, time.Now().Add(-config.AccessTokenTTL)).
+		Distinct().
+		Pluck("session_id", &sessionIDs).Error; err != nil {
+		return err
+	}
+
+	if err := s.db.Model(&entity.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+	markSessionsRevoked(sessionIDs...)
+	return nil
+}
@@ -0,0 +1,273 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// InvitationTTL อายุคำเชิญเข้าทีม
+	InvitationTTL = 72 * time.Hour
+	// InvitationSchedulerInterval ความถี่ที่ตรวจคำเชิญที่หมดอายุ
+	InvitationSchedulerInterval = time.Minute
+)
+
+var (
+	ErrInvitationNotFound = errors.New("ไม่พบคำเชิญ")
+	ErrAlreadyInvited     = errors.New("ผู้ใช้นี้มีคำเชิญเข้าทีมที่รอตอบอยู่แล้ว")
+	ErrInvitationClosed   = errors.New("คำเชิญนี้ถูกตอบหรือยกเลิกไปแล้ว")
+	ErrInvitationExpired  = errors.New("คำเชิญนี้หมดอายุแล้ว")
+)
+
+// pendingInvitations คำเชิญที่ยังรอตอบและไม่หมดอายุของทีม
+func pendingInvitations(tx *gorm.DB, registrationID uint, now time.Time) *gorm.DB {
+	return tx.Model(&entity.TeamInvitation{}).
+		Where("registration_id = ? AND status = ? AND expires_at > ?", registrationID, entity.InvitationStatusPending, now)
+}
+
+// cancelPendingInvitations ยกเลิกคำเชิญที่รอตอบของทีม ใช้เมื่อทีมถูกปิด (ปฏิเสธ หมดเวลายืนยัน ถอนการสมัคร)
+func cancelPendingInvitations(tx *gorm.DB, registrationID uint) error {
+	return tx.Model(&entity.TeamInvitation{}).
+		Where("registration_id = ? AND status = ?", registrationID, entity.InvitationStatusPending).
+		Update("status", entity.InvitationStatusCancelled).Error
+}
+
+// checkInvitable ตรวจว่าเชิญผู้ใช้เข้าทีมได้: มีสิทธิ์สมัคร ยังไม่เป็นสมาชิก ไม่มีคำเชิญค้าง และทีมยังมีที่ว่าง
+// (สมาชิก + คำเชิญที่รอตอบ ต้องไม่เกินขนาดทีมสูงสุด)
+func checkInvitable(tx *gorm.DB, post *entity.Post, registration *entity.Registration, invitee *entity.User, now time.Time) error {
+	if err := CheckEligibility(*post, []*entity.User{invitee}); err != nil {
+		return err
+	}
+
+	var members int64
+	if err := tx.Table("user_registrations").
+		Where("registration_id = ? AND user_id = ?", registration.ID, invitee.ID).
+		Count(&members).Error; err != nil {
+		return err
+	}
+	if members > 0 {
+		return ErrAlreadyMember
+	}
+
+	var invited int64
+	if err := pendingInvitations(tx, registration.ID, now).Where("invitee_id = ?", invitee.ID).Count(&invited).Error; err != nil {
+		return err
+	}
+	if invited > 0 {
+		return ErrAlreadyInvited
+	}
+
+	var pending int64
+	if err := pendingInvitations(tx, registration.ID, now).Count(&pending).Error; err != nil {
+		return err
+	}
+	size := int(tx.Model(registration).Association("Users").Count()) + int(pending) + 1
+	if err := CheckTeamSize(*post, size); errors.Is(err, ErrTeamTooLarge) {
+		return err
+	}
+	return nil
+}
+
+// CheckInvitationOpen คำเชิญต้องยังรอตอบและยังไม่หมดอายุ ณ เวลา now
+func CheckInvitationOpen(invitation entity.TeamInvitation, now time.Time) error {
+	if invitation.Status != entity.InvitationStatusPending {
+		return ErrInvitationClosed
+	}
+	if !now.Before(invitation.ExpiresAt) {
+		return ErrInvitationExpired
+	}
+	return nil
+}
+
+func newInvitation(registrationID, inviterID, inviteeID uint, now time.Time) *entity.TeamInvitation {
+	return &entity.TeamInvitation{
+		RegistrationID: registrationID,
+		InviterID:      inviterID,
+		InviteeID:      inviteeID,
+		Status:         entity.InvitationStatusPending,
+		ExpiresAt:      now.Add(InvitationTTL),
+	}
+}
+
+// InviteMember สมาชิกในทีมเชิญผู้ใช้เข้าทีม ผู้ได้รับเชิญต้องตอบรับก่อนจึงจะนับเป็นสมาชิก
+func (s *RegistrationService) InviteMember(registrationID string, inviterID, inviteeID uint, now time.Time) (*entity.TeamInvitation, error) {
+	var invitation *entity.TeamInvitation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var registration entity.Registration
+		if err := tx.First(&registration, registrationID).Error; err != nil {
+			return errors.New("registration not found")
+		}
+		var invitee entity.User
+		if err := tx.Preload("Role").First(&invitee, inviteeID).Error; err != nil {
+			return errors.New("user not found")
+		}
+		if err := CheckTeamOpen(registration.Status); err != nil {
+			return err
+		}
+		if registration.PostID == nil {
+			return ErrPostNotFound
+		}
+		post, err := lockPost(tx, *registration.PostID)
+		if err != nil {
+			return err
+		}
+		if err := checkInvitable(tx, post, &registration, &invitee, now); err != nil {
+			return err
+		}
+		warnings, err := checkMembership(tx, post, []uint{invitee.ID}, registration.ID)
+		if err != nil {
+			return err
+		}
+
+		invitation = newInvitation(registration.ID, inviterID, invitee.ID, now)
+		invitation.Warnings = warnings
+		return tx.Create(invitation).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// ListTeamInvitations คำเชิญทั้งหมดของทีม ใหม่สุดก่อน
+func (s *RegistrationService) ListTeamInvitations(registrationID string) ([]entity.TeamInvitation, error) {
+	invitations := []entity.TeamInvitation{}
+	if err := s.db.Preload("Invitee").Preload("Inviter").
+		Where("registration_id = ?", registrationID).
+		Order("created_at DESC, id DESC").
+		Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// ListMyInvitations คำเชิญที่รอผู้ใช้ตอบ พร้อมข้อมูลทีมและกิจกรรม
+func (s *RegistrationService) ListMyInvitations(userID uint, now time.Time) ([]entity.TeamInvitation, error) {
+	invitations := []entity.TeamInvitation{}
+	if err := s.db.Preload("Registration").Preload("Registration.Post").Preload("Inviter").
+		Where("invitee_id = ? AND status = ? AND expires_at > ?", userID, entity.InvitationStatusPending, now).
+		Order("expires_at").
+		Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// RespondInvitation ผู้ได้รับเชิญตอบรับหรือปฏิเสธคำเชิญ
+// การตอบรับตรวจสิทธิ์สมัคร ขนาดทีม และที่นั่งของกิจกรรมอีกครั้ง ณ ตอนตอบ
+func (s *RegistrationService) RespondInvitation(invitationID string, userID uint, accept bool, now time.Time) (*entity.TeamInvitation, error) {
+	var invitation entity.TeamInvitation
+	expired := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invitation, invitationID).Error; err != nil || invitation.InviteeID != userID {
+			return ErrInvitationNotFound
+		}
+		if err := CheckInvitationOpen(invitation, now); err != nil {
+			if !errors.Is(err, ErrInvitationExpired) {
+				return err
+			}
+			expired = true
+			return tx.Model(&invitation).Update("status", entity.InvitationStatusExpired).Error
+		}
+
+		if accept {
+			warnings, err := s.joinTeam(tx, &invitation)
+			if err != nil {
+				return err
+			}
+			invitation.Warnings = warnings
+		}
+
+		status := entity.InvitationStatusDeclined
+		if accept {
+			status = entity.InvitationStatusAccepted
+		}
+		invitation.Status = status
+		invitation.RespondedAt = &now
+		return tx.Model(&invitation).Updates(map[string]interface{}{"status": status, "responded_at": now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, ErrInvitationExpired
+	}
+	return &invitation, nil
+}
+
+// joinTeam เพิ่มผู้ได้รับเชิญเข้าทีม (เรียกภายใน transaction ของ RespondInvitation) คืนคำเตือนกิจกรรมที่เวลาซ้อน
+// ทีมต้องยังอยู่ในการพิจารณา ล็อกแถวของทีมไว้กันสถานะเปลี่ยนระหว่างตอบรับ
+func (s *RegistrationService) joinTeam(tx *gorm.DB, invitation *entity.TeamInvitation) ([]string, error) {
+	var registration entity.Registration
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&registration, invitation.RegistrationID).Error; err != nil {
+		return nil, errors.New("registration not found")
+	}
+	if err := CheckTeamOpen(registration.Status); err != nil {
+		return nil, err
+	}
+	var user entity.User
+	if err := tx.Preload("Role").First(&user, invitation.InviteeID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	var warnings []string
+	if registration.PostID != nil {
+		post, err := lockPost(tx, *registration.PostID)
+		if err != nil {
+			return nil, err
+		}
+		if err := CheckEligibility(*post, []*entity.User{&user}); err != nil {
+			return nil, err
+		}
+		if err := checkMemberChange(tx, post, &registration, 1); err != nil {
+			return nil, err
+		}
+		if warnings, err = checkMembership(tx, post, []uint{user.ID}, registration.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Model(&registration).Association("Users").Append(&user); err != nil {
+		return nil, err
+	}
+	return warnings, ensureLeader(tx, registration.ID)
+}
+
+// CancelInvitation สมาชิกในทีมยกเลิกคำเชิญที่ยังรอตอบ
+func (s *RegistrationService) CancelInvitation(registrationID, invitationID string) error {
+	result := s.db.Model(&entity.TeamInvitation{}).
+		Where("id = ? AND registration_id = ? AND status = ?", invitationID, registrationID, entity.InvitationStatusPending).
+		Update("status", entity.InvitationStatusCancelled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvitationNotFound
+	}
+	return nil
+}
+
+// ExpireTeamInvitations เปลี่ยนคำเชิญที่เลยกำหนดเป็น expired คืนจำนวนที่เปลี่ยน
+func ExpireTeamInvitations(db *gorm.DB, now time.Time) (int64, error) {
+	result := db.Model(&entity.TeamInvitation{}).
+		Where("status = ? AND expires_at <= ?", entity.InvitationStatusPending, now).
+		Update("status", entity.InvitationStatusExpired)
+	return result.RowsAffected, result.Error
+}
+
+// RunInvitationScheduler ตรวจคำเชิญที่หมดอายุทุก interval (เรียกเป็น goroutine ตอนเริ่มเซิร์ฟเวอร์)
+func RunInvitationScheduler(db *gorm.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := ExpireTeamInvitations(db, time.Now()); err != nil {
+			log.Printf("invitation scheduler: %v", err)
+		} else if n > 0 {
+			log.Printf("invitation scheduler: expired %d invitation(s)", n)
+		}
+		<-ticker.C
+	}
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+var ErrNotTeamMember = errors.New("ผู้ใช้นี้ไม่ได้เป็นสมาชิกของทีม")
+
+// memberships สมาชิกของทีมตามลำดับที่เข้าทีม
+func memberships(tx *gorm.DB, registrationID uint) ([]entity.UserRegistration, error) {
+	var members []entity.UserRegistration
+	err := tx.Where("registration_id = ?", registrationID).Order("created_at, user_id").Find(&members).Error
+	return members, err
+}
+
+// PickSuccessor เลือกหัวหน้าทีมคนใหม่เมื่อหัวหน้าออกจากทีม: สมาชิกที่เข้าทีมก่อนสุด (เท่ากันใช้ user_id น้อยกว่า)
+// คืน false เมื่อไม่เหลือสมาชิก
+func PickSuccessor(members []entity.UserRegistration, leavingID uint) (uint, bool) {
+	members = slices.DeleteFunc(slices.Clone(members), func(m entity.UserRegistration) bool { return m.UserID == leavingID })
+	if len(members) == 0 {
+		return 0, false
+	}
+	next := slices.MinFunc(members, func(a, b entity.UserRegistration) int {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+		return int(a.UserID) - int(b.UserID)
+	})
+	return next.UserID, true
+}
+
+// setLeader ให้ผู้ใช้เป็นหัวหน้าทีม สมาชิกคนอื่นเป็น member
+func setLeader(tx *gorm.DB, registrationID, userID uint) error {
+	if err := tx.Model(&entity.UserRegistration{}).
+		Where("registration_id = ? AND user_id <> ?", registrationID, userID).
+		Update("role", entity.TeamRoleMember).Error; err != nil {
+		return err
+	}
+	result := tx.Model(&entity.UserRegistration{}).
+		Where("registration_id = ? AND user_id = ?", registrationID, userID).
+		Update("role", entity.TeamRoleLeader)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotTeamMember
+	}
+	return nil
+}
+
+// ensureLeader ตั้งหัวหน้าทีมคนใหม่ให้ทีมที่ไม่มีหัวหน้า (หัวหน้าออกจากทีมหรือทีมเพิ่งมีสมาชิกคนแรก)
+func ensureLeader(tx *gorm.DB, registrationID uint) error {
+	members, err := memberships(tx, registrationID)
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(members, func(m entity.UserRegistration) bool { return m.Role == entity.TeamRoleLeader }) {
+		return nil
+	}
+	successor, ok := PickSuccessor(members, 0)
+	if !ok {
+		return nil
+	}
+	return setLeader(tx, registrationID, successor)
+}
+
+// IsLeader ตรวจสอบว่าผู้ใช้เป็นหัวหน้าทีมของการลงทะเบียนนี้
+func (s *RegistrationService) IsLeader(registrationID string, userID uint) bool {
+	var count int64
+	s.db.Model(&entity.UserRegistration{}).
+		Where("registration_id = ? AND user_id = ? AND role = ?", registrationID, userID, entity.TeamRoleLeader).
+		Count(&count)
+	return count > 0
+}
+
+// TransferLeadership โอนตำแหน่งหัวหน้าทีมให้สมาชิกคนอื่นในทีม
+func (s *RegistrationService) TransferLeadership(registrationID string, userID uint) (*entity.Registration, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var registration entity.Registration
+		if err := tx.First(&registration, registrationID).Error; err != nil {
+			return errors.New("registration not found")
+		}
+		return setLeader(tx, registration.ID, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRegistrationByID(registrationID)
+}
+
+// withLeader เติม leader_id ของแต่ละทีม
+func (s *RegistrationService) withLeader(registrations ...*entity.Registration) {
+	if len(registrations) == 0 {
+		return
+	}
+	ids := make([]uint, len(registrations))
+	for i, registration := range registrations {
+		ids[i] = registration.ID
+	}
+	var leaders []entity.UserRegistration
+	s.db.Where("registration_id IN ? AND role = ?", ids, entity.TeamRoleLeader).Find(&leaders)
+	for _, leader := range leaders {
+		for _, registration := range registrations {
+			if registration.ID == leader.RegistrationID {
+				registration.LeaderID = &leader.UserID
+			}
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"gorm.io/gorm"
+)
+
+// ImpersonationTTL อายุ token สวมสิทธิ์ เท่ากับ access token ปกติจึงเพิกถอนผ่าน session denylist ได้
+const ImpersonationTTL = config.AccessTokenTTL
+
+var (
+	ErrUserNotFound           = errors.New("ไม่พบผู้ใช้")
+	ErrRoleNotFound           = errors.New("ไม่พบบทบาทที่ระบุ")
+	ErrRoleExists             = errors.New("มีบทบาทนี้อยู่แล้ว")
+	ErrInvalidRoleName        = errors.New("ชื่อบทบาทต้องเป็นภาษาอังกฤษตัวพิมพ์เล็ก ตัวเลข หรือ _ เท่านั้น")
+	ErrCannotModifySelf       = errors.New("ไม่สามารถเปลี่ยนบทบาทหรือระงับบัญชีของตัวเองได้")
+	ErrCannotImpersonateAdmin = errors.New("ไม่สามารถสวมสิทธิ์บัญชีผู้ดูแลระบบหรือบัญชีที่ถูกระงับได้")
+	ErrAccountDeactivated     = errors.New("บัญชีนี้ถูกระงับการใช้งาน กรุณาติดต่อผู้ดูแลระบบ")
+	ErrPasswordResetRequired  = errors.New("ผู้ดูแลระบบกำหนดให้ตั้งรหัสผ่านใหม่ กรุณารีเซ็ตรหัสผ่านจากลิงก์ในอีเมล")
+	ErrImpersonatedCredential = errors.New("ไม่สามารถจัดการ API token หรือการยืนยันตัวตนสองขั้นตอนระหว่างสวมสิทธิ์ผู้ใช้ได้")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+
+// deactivatedUsers ผู้ใช้ที่ถูกระงับ เก็บไว้ในหน่วยความจำให้ AuthMiddleware ตรวจได้โดยไม่ต้อง query DB
+// (รวมถึง token สวมสิทธิ์ที่ไม่ได้ผูกกับ refresh token)
+var deactivatedUsers = struct {
+	sync.RWMutex
+	ids map[uint]bool
+}{ids: make(map[uint]bool)}
+
+// SetUserDeactivated อัปเดตสถานะระงับบัญชีในหน่วยความจำ
+func SetUserDeactivated(userID uint, deactivated bool) {
+	deactivatedUsers.Lock()
+	defer deactivatedUsers.Unlock()
+	if deactivated {
+		deactivatedUsers.ids[userID] = true
+	} else {
+		delete(deactivatedUsers.ids, userID)
+	}
+}
+
+// IsUserDeactivated ใช้ใน AuthMiddleware เพื่อปฏิเสธ token ของบัญชีที่ถูกระงับ
+func IsUserDeactivated(userID uint) bool {
+	deactivatedUsers.RLock()
+	defer deactivatedUsers.RUnlock()
+	return deactivatedUsers.ids[userID]
+}
+
+// LoadDeactivatedUsers โหลดบัญชีที่ถูกระงับจาก DB ตอนเริ่มเซิร์ฟเวอร์
+func LoadDeactivatedUsers(db *gorm.DB) {
+	var ids []uint
+	if err := db.Model(&entity.User{}).Where("deactivated_at IS NOT NULL").Pluck("id", &ids).Error; err != nil {
+		log.Printf("Error loading deactivated users: %v", err)
+		return
+	}
+	for _, id := range ids {
+		SetUserDeactivated(id, true)
+	}
+}
+
+// checkLoginAllowed ตรวจสถานะบัญชีหลังยืนยันตัวตนสำเร็จ (ทั้งรหัสผ่านและ SSO)
+func checkLoginAllowed(user *entity.User) error {
+	if user.DeactivatedAt != nil {
+		return ErrAccountDeactivated
+	}
+	if user.PasswordResetRequired {
+		return ErrPasswordResetRequired
+	}
+	return nil
+}
+
+// UserAdminService งานจัดการบัญชีของผู้ดูแลระบบ ทุกการเปลี่ยนแปลงถูกบันทึกใน audit log
+type UserAdminService struct {
+	db       *gorm.DB
+	sessions *SessionService
+	accounts *AccountService
+}
+
+func NewUserAdminService(db *gorm.DB, sessions *SessionService, accounts *AccountService) *UserAdminService {
+	return &UserAdminService{db: db, sessions: sessions, accounts: accounts}
+}
+
+func (s *UserAdminService) findUser(userID uint) (*entity.User, error) {
+	var user entity.User
+	if err := s.db.Preload("Role").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *UserAdminService) ListRoles() ([]entity.Role, error) {
+	var roles []entity.Role
+	if err := s.db.Order("id").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (s *UserAdminService) CreateRole(name string, actorID uint, ip string) (*entity.Role, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !roleNamePattern.MatchString(name) {
+		return nil, ErrInvalidRoleName
+	}
+
+	var count int64
+	if err := s.db.Model(&entity.Role{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrRoleExists
+	}
+
+	role := entity.Role{Name: name}
+	if err := s.db.Create(&role).Error; err != nil {
+		return nil, err
+	}
+
+	RecordAudit(s.db, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionRoleCreated,
+		TargetType: "role",
+		TargetID:   strconv.Itoa(int(role.ID)),
+		IPAddress:  ip,
+		Detail:     name,
+	})
+	return &role, nil
+}
+
+// ChangeRole เปลี่ยนบทบาทผู้ใช้ และเพิกถอน session เดิมเพื่อให้ token ใหม่มี role ที่ถูกต้อง
+func (s *UserAdminService) ChangeRole(targetID, roleID, actorID uint, ip string) (*entity.User, error) {
+	if targetID == actorID {
+		return nil, ErrCannotModifySelf
+	}
+	user, err := s.findUser(targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var role entity.Role
+	if err := s.db.First(&role, roleID).Error; err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	oldRole := ""
+	if user.Role != nil {
+		oldRole = user.Role.Name
+	}
+	if err := s.db.Model(user).Update("role_id", role.ID).Error; err != nil {
+		return nil, err
+	}
+	user.Role = &role
+
+	if err := s.sessions.RevokeAllSessions(user.ID); err != nil {
+		log.Printf("revoke sessions of user %d after role change failed: %v", user.ID, err)
+	}
+
+	RecordAudit(s.db, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionRoleChanged,
+		TargetType: "user",
+		TargetID:   strconv.Itoa(int(user.ID)),
+		IPAddress:  ip,
+		Detail:     fmt.Sprintf("%s -> %s", oldRole, role.Name),
+	})
+	return user, nil
+}
+
+// Deactivate ระงับบัญชีและออกจากระบบทุกอุปกรณ์
+func (s *UserAdminService) Deactivate(targetID uint, reason string, actorID uint, ip string) error {
+	if targetID == actorID {
+		return ErrCannotModifySelf
+	}
+	user, err := s.findUser(targetID)
+	if err != nil {
+		return err
+	}
+	if user.DeactivatedAt == nil {
+		if err := s.db.Model(user).Update("deactivated_at", time.Now()).Error; err != nil {
+			return err
+		}
+	}
+	SetUserDeactivated(user.ID, true)
+
+	if err := s.sessions.RevokeAllSessions(user.ID); err != nil {
+		log.Printf("revoke sessions of user %d after deactivation failed: %v", user.ID, err)
+	}
+
+	RecordAudit(s.db, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionUserDeactivated,
+		TargetType: "user",
+		TargetID:   strconv.Itoa(int(user.ID)),
+		IPAddress:  ip,
+		Detail:     reason,
+	})
+	return nil
+}
+
+func (s *UserAdminService) Reactivate(targetID, actorID uint, ip string) error {
+	user, err := s.findUser(targetID)
+	if err != nil {
+		return err
+	}
+	if user.AnonymizedAt != nil {
+		return ErrAlreadyAnonymized
+	}
+	if err := s.db.Model(user).Update("deactivated_at", nil).Error; err != nil {
+		return err
+	}
+	SetUserDeactivated(user.ID, false)
+
+	RecordAudit(s.db, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionUserReactivated,
+		TargetType: "user",
+		TargetID:   strconv.Itoa(int(user.ID)),
+		IPAddress:  ip,
+	})
+	return nil
+}
+
+// ForcePasswordReset บังคับให้ตั้งรหัสผ่านใหม่: ออกจากระบบทุกอุปกรณ์และส่งลิงก์รีเซ็ตไปที่อีเมล
+// เข้าสู่ระบบไม่ได้จนกว่าจะรีเซ็ตรหัสผ่านสำเร็จ
+func (s *UserAdminService) ForcePasswordReset(targetID, actorID uint, ip string) error {
+	user, err := s.findUser(targetID)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Model(user).Update("password_reset_required", true).Error; err != nil {
+		return err
+	}
+
+	if err := s.sessions.RevokeAllSessions(user.ID); err != nil {
+		log.Printf("revoke sessions of user %d after forced reset failed: %v", user.ID, err)
+	}
+	if err := RevokeAllAPITokens(s.db, user.ID); err != nil {
+		log.Printf("revoke api tokens of user %d after forced reset failed: %v", user.ID, err)
+	}
+
+	RecordAudit(s.db, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionPasswordResetForced,
+		TargetType: "user",
+		TargetID:   strconv.Itoa(int(user.ID)),
+		IPAddress:  ip,
+	})
+	return s.accounts.sendPasswordReset(user)
+}
+
+// Impersonate ออก access token อายุสั้นของผู้ใช้ให้ admin ใช้ตรวจสอบปัญหา
+// token ไม่มี refresh token และระบุ admin ผู้ออกไว้ใน claim "imp"
+func (s *UserAdminService) Impersonate(targetID, actorID uint, ip string) (*dto.ImpersonationResponse, error) {
+	user, err := s.findUser(targetID)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == actorID || user.DeactivatedAt != nil || user.Role == nil || RoleHasPermission(user.Role.Name, entity.PermUsersManage) {
+		return nil, ErrCannotImpersonateAdmin
+	}
+
+	sessionID := "imp-" + uuid.New().String()
+	token, err := config.GenerateImpersonationJWT(user.ID, user.SutId, user.Role.Name, sessionID, actorID, ImpersonationTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	RecordAudit(s.db, entity.AuditLog{
+		ActorID:    &actorID,
+		Action:     entity.AuditActionImpersonated,
+		TargetType: "user",
+		TargetID:   strconv.Itoa(int(user.ID)),
+		IPAddress:  ip,
+		Detail:     "session=" + sessionID,
+	})
+
+	return &dto.ImpersonationResponse{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresIn: int(ImpersonationTTL.Seconds()),
+		SessionID: sessionID,
+		UserID:    user.ID,
+		SutId:     user.SutId,
+		Role:      user.Role.Name,
+	}, nil
+}
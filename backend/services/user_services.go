@@ -325,12 +325,12 @@ func (s *UserService) GetUsersBySutIds(sutIds []string) ([]*entity.User, []strin
 
 func (s *UserService) SearchUsers(query string) ([]*entity.User, error) {
 	var users []*entity.User
+	pattern := "%" + EscapeLike(query) + "%"
 	err := s.db.
 		Preload("Faculty").
 		Preload("Major").
 		Preload("Role").
-		Where("first_name LIKE ? OR last_name LIKE ? OR sut_id LIKE ?",
-			"%"+query+"%", "%"+query+"%", "%"+query+"%").
+		Where("first_name ILIKE ? OR last_name ILIKE ? OR sut_id ILIKE ?", pattern, pattern, pattern).
 		Limit(20).
 		Find(&users).Error
 
@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+)
+
+func TestCheckAccountToken(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Now()
+
+	t.Run("unused token before expiry is valid", func(t *testing.T) {
+		token := &entity.AccountToken{Purpose: entity.TokenPurposePasswordReset, ExpiresAt: now.Add(time.Minute)}
+		g.Expect(services.CheckAccountToken(token, entity.TokenPurposePasswordReset, now)).To(BeNil())
+	})
+
+	t.Run("used token is rejected", func(t *testing.T) {
+		usedAt := now.Add(-time.Second)
+		token := &entity.AccountToken{Purpose: entity.TokenPurposePasswordReset, ExpiresAt: now.Add(time.Minute), UsedAt: &usedAt}
+		g.Expect(services.CheckAccountToken(token, entity.TokenPurposePasswordReset, now)).To(Equal(services.ErrInvalidAccountToken))
+	})
+
+	t.Run("expired token is rejected", func(t *testing.T) {
+		token := &entity.AccountToken{Purpose: entity.TokenPurposeEmailVerification, ExpiresAt: now}
+		g.Expect(services.CheckAccountToken(token, entity.TokenPurposeEmailVerification, now)).To(Equal(services.ErrInvalidAccountToken))
+	})
+
+	t.Run("token for another purpose is rejected", func(t *testing.T) {
+		token := &entity.AccountToken{Purpose: entity.TokenPurposeEmailVerification, ExpiresAt: now.Add(time.Hour)}
+		g.Expect(services.CheckAccountToken(token, entity.TokenPurposePasswordReset, now)).To(Equal(services.ErrInvalidAccountToken))
+	})
+}
+
+func TestLogMailer(t *testing.T) {
+	g := NewGomegaWithT(t)
+	dir := t.TempDir()
+
+	mailer := &services.LogMailer{Dir: dir}
+	err := mailer.Send("b6614690@g.sut.ac.th", "ยืนยันอีเมล", "https://example.com/verify?token=abc")
+	g.Expect(err).To(BeNil())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	g.Expect(err).To(BeNil())
+	g.Expect(files).To(HaveLen(1))
+
+	content, err := os.ReadFile(files[0])
+	g.Expect(err).To(BeNil())
+	g.Expect(string(content)).To(ContainSubstring("To: b6614690@g.sut.ac.th"))
+	g.Expect(string(content)).To(ContainSubstring("token=abc"))
+}
+
+func TestLogMailerHeaders(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("subject is encoded and cannot inject headers", func(t *testing.T) {
+		dir := t.TempDir()
+		mailer := &services.LogMailer{Dir: dir}
+		g.Expect(mailer.Send("b6614690@g.sut.ac.th", "ทีม A\r\nBcc: attacker@example.com", "body")).To(BeNil())
+
+		files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+		g.Expect(files).To(HaveLen(1))
+		content, err := os.ReadFile(files[0])
+		g.Expect(err).To(BeNil())
+		g.Expect(string(content)).To(ContainSubstring("Subject: =?utf-8?q?"))
+		g.Expect(string(content)).NotTo(ContainSubstring("\r\nBcc:"))
+	})
+
+	t.Run("recipient with line break is rejected", func(t *testing.T) {
+		mailer := &services.LogMailer{Dir: t.TempDir()}
+		err := mailer.Send("b6614690@g.sut.ac.th\r\nBcc: attacker@example.com", "subject", "body")
+		g.Expect(err).To(Equal(services.ErrInvalidMailAddress))
+	})
+}
@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+func TestAPITokenScopes(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("token prefix", func(t *testing.T) {
+		g.Expect(services.IsAPIToken("ecp_abc")).To(BeTrue())
+		g.Expect(services.IsAPIToken("eyJhbGciOiJIUzI1NiJ9.x.y")).To(BeFalse())
+	})
+
+	t.Run("has scope", func(t *testing.T) {
+		token := entity.APIToken{Scopes: "registrations:read results:read"}
+		g.Expect(token.HasScope(entity.ScopeRegistrationsRead)).To(BeTrue())
+		g.Expect(token.HasScope(entity.ScopeResultsWrite)).To(BeFalse())
+	})
+
+	t.Run("scope requires permission of the creator", func(t *testing.T) {
+		g.Expect(services.ValidateAPITokenScopes(entity.RoleStudent, []string{entity.ScopeRegistrationsRead})).To(Succeed())
+		g.Expect(services.ValidateAPITokenScopes(entity.RoleStudent, []string{entity.ScopeResultsWrite})).NotTo(Succeed())
+		g.Expect(services.ValidateAPITokenScopes(entity.RoleAdmin, []string{entity.ScopeResultsWrite})).To(Succeed())
+	})
+
+	t.Run("unknown or empty scopes are rejected", func(t *testing.T) {
+		g.Expect(services.ValidateAPITokenScopes(entity.RoleAdmin, []string{"users:delete"})).NotTo(Succeed())
+		g.Expect(services.ValidateAPITokenScopes(entity.RoleAdmin, nil)).NotTo(Succeed())
+	})
+}
+
+func TestAPITokenRoutes(t *testing.T) {
+	g := NewGomegaWithT(t)
+	r := setupRouter()
+
+	registered := map[string]bool{}
+	for _, route := range r.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+
+	t.Run("every scoped endpoint exists", func(t *testing.T) {
+		for _, key := range middleware.APITokenRoutes() {
+			g.Expect(registered).To(HaveKey(key))
+		}
+	})
+
+	t.Run("token management is not reachable with an API token", func(t *testing.T) {
+		_, ok := middleware.APITokenScope("POST", "/api/api-tokens")
+		g.Expect(ok).To(BeFalse())
+	})
+
+	t.Run("endpoint without scope rejects API token", func(t *testing.T) {
+		g.Expect(doRequest(r, "GET", "/api/users", "ecp_not-a-real-token")).To(Equal(http.StatusForbidden))
+		g.Expect(doRequest(r, "POST", "/api/auth/logout-all", "ecp_not-a-real-token")).To(Equal(http.StatusForbidden))
+	})
+
+	t.Run("API token in query string is rejected", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/results?token=ecp_not-a-real-token", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		g.Expect(w.Code).To(Equal(http.StatusUnauthorized))
+	})
+}
@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+)
+
+func TestAuditDiff(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("only changed fields are kept", func(t *testing.T) {
+		before := entity.Registration{TeamName: "ทีม A", Status: "pending"}
+		after := before
+		after.Status = "rejected"
+		after.RejectionReason = "เอกสารไม่ครบ"
+		after.UpdatedAt = time.Now()
+
+		b, a := services.AuditDiff(before, after)
+		g.Expect(b).To(MatchJSON(`{"status":"pending","rejection_reason":""}`))
+		g.Expect(a).To(MatchJSON(`{"status":"rejected","rejection_reason":"เอกสารไม่ครบ"}`))
+	})
+
+	t.Run("no change gives empty diff", func(t *testing.T) {
+		result := entity.Result{AwardID: 1, Detail: "ชนะเลิศ"}
+		b, a := services.AuditDiff(result, result)
+		g.Expect(b).To(BeEmpty())
+		g.Expect(a).To(BeEmpty())
+	})
+
+	t.Run("delete keeps the whole record as before", func(t *testing.T) {
+		b, a := services.AuditDiff(entity.Reward{RewardName: "แก้วน้ำ", Stock: 3}, nil)
+		g.Expect(b).To(ContainSubstring(`"reward_name":"แก้วน้ำ"`))
+		g.Expect(a).To(BeEmpty())
+	})
+}
+
+func TestAuditLogIsAppendOnly(t *testing.T) {
+	g := NewGomegaWithT(t)
+	log := entity.AuditLog{}
+	g.Expect(log.BeforeUpdate(nil)).To(MatchError(entity.ErrAuditLogImmutable))
+	g.Expect(log.BeforeDelete(nil)).To(MatchError(entity.ErrAuditLogImmutable))
+}
+
+func TestWriteAuditCSV(t *testing.T) {
+	g := NewGomegaWithT(t)
+	actorID := uint(7)
+	logs := []dto.AuditLogEntry{{
+		AuditLog: entity.AuditLog{
+			ID:         1,
+			CreatedAt:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+			ActorID:    &actorID,
+			Action:     entity.AuditActionResultUpdated,
+			TargetType: "result",
+			TargetID:   "9",
+			IPAddress:  "10.0.0.1",
+			Detail:     "=HYPERLINK(\"http://evil\")",
+			Before:     `{"award_id":1}`,
+			After:      `{"award_id":2}`,
+		},
+		ActorSutID: "A0000000",
+	}}
+
+	var buf bytes.Buffer
+	g.Expect(services.WriteAuditCSV(&buf, logs)).To(Succeed())
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(rows).To(HaveLen(2))
+	g.Expect(rows[0][0]).To(Equal("id"))
+	g.Expect(rows[1]).To(Equal([]string{
+		"1", "2025-01-02T03:04:05Z", "7", "A0000000", "result.updated", "result", "9", "10.0.0.1",
+		"'=HYPERLINK(\"http://evil\")", `{"award_id":1}`, `{"award_id":2}`,
+	}))
+}
@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	jwt "github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/entity"
+)
+
+func writePrivateKey(t *testing.T, dir, kid string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyRing(t *testing.T) {
+	g := NewGomegaWithT(t)
+	defer config.SetKeyRing(nil)
+
+	dir := t.TempDir()
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	writePrivateKey(t, dir, "2026-09", rsaKey)
+	writePrivateKey(t, dir, "2026-10", edKey)
+
+	t.Run("latest key signs when no active kid is set", func(t *testing.T) {
+		kr, err := config.NewKeyRing(dir, "", "")
+		g.Expect(err).To(BeNil())
+		g.Expect(kr.ActiveKeyID()).To(Equal("2026-10"))
+
+		config.SetKeyRing(kr)
+		token, err := config.GenerateJWT(2, "B6614690", entity.RoleStudent, "s1")
+		g.Expect(err).To(BeNil())
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &config.Claims{})
+		g.Expect(err).To(BeNil())
+		g.Expect(parsed.Header["kid"]).To(Equal("2026-10"))
+		g.Expect(parsed.Method.Alg()).To(Equal("EdDSA"))
+	})
+
+	t.Run("tokens signed before rotation stay valid", func(t *testing.T) {
+		before, err := config.NewKeyRing(dir, "2026-09", "")
+		g.Expect(err).To(BeNil())
+		config.SetKeyRing(before)
+		token, err := config.GenerateJWT(2, "B6614690", entity.RoleStudent, "s1")
+		g.Expect(err).To(BeNil())
+
+		after, err := config.NewKeyRing(dir, "2026-10", "")
+		g.Expect(err).To(BeNil())
+		config.SetKeyRing(after)
+		claims, err := config.ValidateJWT(token)
+		g.Expect(err).To(BeNil())
+		g.Expect(claims.SessionID).To(Equal("s1"))
+	})
+
+	t.Run("legacy HS256 tokens without kid are accepted", func(t *testing.T) {
+		kr, err := config.NewKeyRing(dir, "2026-10", "legacy-secret")
+		g.Expect(err).To(BeNil())
+		config.SetKeyRing(kr)
+
+		claims := &config.Claims{
+			UserID:           2,
+			SessionID:        "s1",
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+		}
+		legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("legacy-secret"))
+		g.Expect(err).To(BeNil())
+
+		_, err = config.ValidateJWT(legacy)
+		g.Expect(err).To(BeNil())
+	})
+
+	t.Run("algorithm must match the key", func(t *testing.T) {
+		kr, _ := config.NewKeyRing(dir, "2026-10", "")
+		config.SetKeyRing(kr)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, &config.Claims{UserID: 2})
+		token.Header["kid"] = "2026-10"
+		forged, _ := token.SignedString([]byte("anything"))
+
+		_, err := config.ValidateJWT(forged)
+		g.Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("unknown active kid is an error", func(t *testing.T) {
+		_, err := config.NewKeyRing(dir, "missing", "")
+		g.Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("JWKS publishes public keys only", func(t *testing.T) {
+		kr, _ := config.NewKeyRing(dir, "2026-10", "legacy-secret")
+		keys := kr.JWKS()["keys"].([]map[string]string)
+		g.Expect(keys).To(HaveLen(2))
+		g.Expect(keys[0]["kid"]).To(Equal("2026-09"))
+		g.Expect(keys[0]["kty"]).To(Equal("RSA"))
+		g.Expect(keys[0]["e"]).To(Equal("AQAB"))
+		g.Expect(keys[1]["kty"]).To(Equal("OKP"))
+		g.Expect(keys[1]["alg"]).To(Equal("EdDSA"))
+	})
+}
@@ -0,0 +1,74 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+)
+
+func TestLoginPolicy(t *testing.T) {
+	g := NewGomegaWithT(t)
+	policy := services.AccountLoginPolicy
+	now := time.Now()
+
+	t.Run("no delay before DelayAfter failures", func(t *testing.T) {
+		throttle := &entity.LoginThrottle{}
+		for i := 0; i < policy.DelayAfter-1; i++ {
+			g.Expect(policy.Fail(throttle, now)).To(BeFalse())
+		}
+		wait, locked := policy.Wait(throttle, now)
+		g.Expect(wait).To(BeZero())
+		g.Expect(locked).To(BeFalse())
+	})
+
+	t.Run("delay grows with each failure", func(t *testing.T) {
+		throttle := &entity.LoginThrottle{}
+		for i := 0; i < policy.DelayAfter; i++ {
+			policy.Fail(throttle, now)
+		}
+		first, locked := policy.Wait(throttle, now)
+		g.Expect(first).To(Equal(policy.BaseDelay))
+		g.Expect(locked).To(BeFalse())
+
+		policy.Fail(throttle, now)
+		second, _ := policy.Wait(throttle, now)
+		g.Expect(second).To(Equal(2 * policy.BaseDelay))
+
+		later, _ := policy.Wait(throttle, now.Add(second))
+		g.Expect(later).To(BeZero())
+	})
+
+	t.Run("locks after LockAfter failures and unlocks after LockDuration", func(t *testing.T) {
+		throttle := &entity.LoginThrottle{}
+		var locked bool
+		for i := 0; i < policy.LockAfter; i++ {
+			locked = policy.Fail(throttle, now)
+		}
+		g.Expect(locked).To(BeTrue())
+
+		wait, isLocked := policy.Wait(throttle, now.Add(time.Minute))
+		g.Expect(isLocked).To(BeTrue())
+		g.Expect(wait).To(Equal(policy.LockDuration - time.Minute))
+
+		wait, isLocked = policy.Wait(throttle, now.Add(policy.LockDuration))
+		g.Expect(isLocked).To(BeFalse())
+		g.Expect(wait).To(BeZero())
+	})
+
+	t.Run("counter resets after a quiet period", func(t *testing.T) {
+		throttle := &entity.LoginThrottle{}
+		for i := 0; i < policy.LockAfter-1; i++ {
+			policy.Fail(throttle, now)
+		}
+		g.Expect(policy.Fail(throttle, now.Add(policy.ResetAfter+time.Second))).To(BeFalse())
+		g.Expect(throttle.FailedCount).To(Equal(1))
+	})
+
+	t.Run("throttled error reports lock", func(t *testing.T) {
+		err := &services.LoginThrottledError{Wait: 15 * time.Minute, Locked: true}
+		g.Expect(err.Error()).To(ContainSubstring("ล็อก"))
+	})
+}
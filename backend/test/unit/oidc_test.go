@@ -0,0 +1,180 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/mockidp"
+	"github.com/sut68/team21/services"
+	"golang.org/x/oauth2"
+)
+
+const testRedirectURL = "http://localhost/api/auth/oidc/callback"
+
+func startMockIdP(t *testing.T) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+
+	mock, err := mockidp.New(server.URL+"/mock-idp", "engiconnect-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.Register(engine.Group("/mock-idp"))
+	return server
+}
+
+// authorizeCode ทำขั้นตอนที่เบราว์เซอร์ทำ: เปิดหน้า authorize แล้วรับ code จาก redirect
+func authorizeCode(t *testing.T, issuer, verifier, nonce, loginHint string) string {
+	params := url.Values{}
+	params.Set("client_id", "engiconnect-test")
+	params.Set("redirect_uri", testRedirectURL)
+	params.Set("response_type", "code")
+	params.Set("state", "test-state")
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", oauth2.S256ChallengeFromVerifier(verifier))
+	params.Set("code_challenge_method", "S256")
+	params.Set("login_hint", loginHint)
+	params.Set("given_name", "พิพัฒน์")
+	params.Set("family_name", "อินสวรรค์")
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Get(issuer + "/authorize?" + params.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d", res.StatusCode)
+	}
+
+	location, _ := url.Parse(res.Header.Get("Location"))
+	return location.Query().Get("code")
+}
+
+func TestOIDCWithMockIdP(t *testing.T) {
+	g := NewGomegaWithT(t)
+	server := startMockIdP(t)
+	issuer := server.URL + "/mock-idp"
+
+	newService := func() *services.OIDCService {
+		return services.NewOIDCService(nil, nil, services.OIDCConfig{
+			Issuer:      issuer,
+			ClientID:    "engiconnect-test",
+			RedirectURL: testRedirectURL,
+			SutIdClaim:  "email",
+		})
+	}
+
+	t.Run("code exchange maps email to SutId", func(t *testing.T) {
+		verifier := oauth2.GenerateVerifier()
+		code := authorizeCode(t, issuer, verifier, "nonce-1", "b6614690")
+
+		identity, err := newService().Exchange(context.Background(), code, verifier, "nonce-1")
+		g.Expect(err).To(BeNil())
+		g.Expect(identity.SutId).To(Equal("B6614690"))
+		g.Expect(identity.Email).To(Equal("b6614690@g.sut.ac.th"))
+		g.Expect(identity.Issuer).To(Equal(issuer))
+		g.Expect(identity.Subject).To(Equal("mock|b6614690"))
+		g.Expect(identity.FirstName).To(Equal("พิพัฒน์"))
+	})
+
+	t.Run("wrong PKCE verifier is rejected", func(t *testing.T) {
+		code := authorizeCode(t, issuer, oauth2.GenerateVerifier(), "nonce-2", "B6614690")
+
+		_, err := newService().Exchange(context.Background(), code, oauth2.GenerateVerifier(), "nonce-2")
+		g.Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("nonce mismatch is rejected", func(t *testing.T) {
+		verifier := oauth2.GenerateVerifier()
+		code := authorizeCode(t, issuer, verifier, "nonce-3", "B6614690")
+
+		_, err := newService().Exchange(context.Background(), code, verifier, "other-nonce")
+		g.Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("code can be used only once", func(t *testing.T) {
+		verifier := oauth2.GenerateVerifier()
+		code := authorizeCode(t, issuer, verifier, "nonce-4", "B6614690")
+		svc := newService()
+
+		_, err := svc.Exchange(context.Background(), code, verifier, "nonce-4")
+		g.Expect(err).To(BeNil())
+		_, err = svc.Exchange(context.Background(), code, verifier, "nonce-4")
+		g.Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("email outside the university domain is rejected", func(t *testing.T) {
+		verifier := oauth2.GenerateVerifier()
+		code := authorizeCode(t, issuer, verifier, "nonce-6", "b6614690")
+
+		svc := services.NewOIDCService(nil, nil, services.OIDCConfig{
+			Issuer:      issuer,
+			ClientID:    "engiconnect-test",
+			RedirectURL: testRedirectURL,
+			SutIdClaim:  "email",
+			EmailDomain: "sut.example.ac.th",
+		})
+		_, err := svc.Exchange(context.Background(), code, verifier, "nonce-6")
+		g.Expect(err).NotTo(BeNil())
+	})
+
+	t.Run("account without a student id is rejected", func(t *testing.T) {
+		verifier := oauth2.GenerateVerifier()
+		code := authorizeCode(t, issuer, verifier, "nonce-5", "staff01")
+
+		_, err := newService().Exchange(context.Background(), code, verifier, "nonce-5")
+		g.Expect(err).NotTo(BeNil())
+	})
+}
+
+func TestSutIdFromClaim(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sutId, err := services.SutIdFromClaim("b6614690@g.sut.ac.th")
+	g.Expect(err).To(BeNil())
+	g.Expect(sutId).To(Equal("B6614690"))
+
+	sutId, err = services.SutIdFromClaim("M6512345")
+	g.Expect(err).To(BeNil())
+	g.Expect(sutId).To(Equal("M6512345"))
+
+	_, err = services.SutIdFromClaim("somchai@sut.ac.th")
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestSutIdFromEmail(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	sutId, err := services.SutIdFromEmail("B6614690@G.SUT.AC.TH", services.DefaultOIDCEmailDomain)
+	g.Expect(err).To(BeNil())
+	g.Expect(sutId).To(Equal("B6614690"))
+
+	_, err = services.SutIdFromEmail("b6614690@gmail.com", services.DefaultOIDCEmailDomain)
+	g.Expect(err).NotTo(BeNil())
+
+	_, err = services.SutIdFromEmail("b6614690@evil.g.sut.ac.th", services.DefaultOIDCEmailDomain)
+	g.Expect(err).NotTo(BeNil())
+
+	_, err = services.SutIdFromEmail("B6614690", services.DefaultOIDCEmailDomain)
+	g.Expect(err).NotTo(BeNil())
+}
+
+func TestYearFromSutId(t *testing.T) {
+	g := NewGomegaWithT(t)
+	october2026 := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	march2027 := time.Date(2027, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	g.Expect(services.YearFromSutId("B6614690", october2026)).To(Equal(uint(4)))
+	g.Expect(services.YearFromSutId("B6914690", october2026)).To(Equal(uint(1)))
+	g.Expect(services.YearFromSutId("B6914690", march2027)).To(Equal(uint(1)))
+	g.Expect(services.YearFromSutId("B7014690", october2026)).To(Equal(uint(1)))
+}
@@ -0,0 +1,306 @@
+package unit
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/routes"
+	"github.com/sut68/team21/services"
+)
+
+type protectedRoute struct {
+	method string
+	path   string
+}
+
+// routes ที่ต้องเข้าสู่ระบบ (ตรงกับตารางสิทธิ์ใน permissions.md)
+var authenticatedRoutes = []protectedRoute{
+	{"POST", "/api/auth/logout-all"},
+	{"GET", "/api/auth/lockouts"},
+	{"POST", "/api/auth/unlock/B6614690"},
+	{"GET", "/api/auth/2fa/status"},
+	{"POST", "/api/auth/2fa/setup"},
+	{"POST", "/api/auth/2fa/enable"},
+	{"POST", "/api/auth/2fa/disable"},
+	{"POST", "/api/auth/2fa/recovery-codes"},
+	{"GET", "/api/api-tokens"},
+	{"POST", "/api/api-tokens"},
+	{"DELETE", "/api/api-tokens/1"},
+	{"GET", "/api/audit-logs"},
+	{"GET", "/api/audit-logs/export"},
+	{"GET", "/api/search"},
+	{"GET", "/api/users"},
+	{"GET", "/api/profiles/me"},
+	{"PUT", "/api/profiles/me"},
+	{"DELETE", "/api/profiles/me"},
+	{"GET", "/api/profiles/me/export"},
+	{"GET", "/api/profiles/B6614690"},
+	{"GET", "/api/users/search"},
+	{"GET", "/api/users/sut-id/B6614690"},
+	{"POST", "/api/users/by-sut-ids"},
+	{"GET", "/api/roles"},
+	{"POST", "/api/roles"},
+	{"PUT", "/api/roles/1/permissions"},
+	{"GET", "/api/permissions"},
+	{"PUT", "/api/users/1/role"},
+	{"POST", "/api/users/1/deactivate"},
+	{"POST", "/api/users/1/reactivate"},
+	{"POST", "/api/users/1/force-password-reset"},
+	{"POST", "/api/users/1/impersonate"},
+	{"POST", "/api/users/1/anonymize"},
+	{"POST", "/api/portfolios"},
+	{"GET", "/api/portfolios"},
+	{"GET", "/api/portfolios/1"},
+	{"PATCH", "/api/portfolios/1"},
+	{"DELETE", "/api/portfolios/1"},
+	{"GET", "/api/users/1/portfolios"},
+	{"POST", "/api/post"},
+	{"GET", "/api/post"},
+	{"GET", "/api/post/my"},
+	{"GET", "/api/post/detailed"},
+	{"GET", "/api/post/student"},
+	{"GET", "/api/post/1"},
+	{"PUT", "/api/post/1"},
+	{"DELETE", "/api/post/1"},
+	{"GET", "/api/post/review-queue"},
+	{"POST", "/api/post/1/submit"},
+	{"POST", "/api/post/1/approve"},
+	{"POST", "/api/post/1/reject"},
+	{"GET", "/api/post/1/reviews"},
+	{"GET", "/api/post/1/form"},
+	{"PUT", "/api/post/1/form"},
+	{"POST", "/api/certificate"},
+	{"GET", "/api/certificate"},
+	{"GET", "/api/certificate/my"},
+	{"GET", "/api/certificate/1"},
+	{"PUT", "/api/certificate/1"},
+	{"GET", "/api/certificate/post/1"},
+	{"DELETE", "/api/certificate/1"},
+	{"GET", "/api/chat/history/1"},
+	{"GET", "/api/chat/ws/lobby/1"},
+	{"POST", "/api/chat/upload"},
+	{"POST", "/api/chat/upload/file"},
+	{"DELETE", "/api/chat/message/1"},
+	{"GET", "/api/points/total/1"},
+	{"POST", "/api/points/checkin/1"},
+	{"GET", "/api/points/membership/1"},
+	{"GET", "/api/points/records/1"},
+	{"POST", "/api/points/rewards"},
+	{"GET", "/api/points/rewards"},
+	{"PUT", "/api/points/rewards/1"},
+	{"GET", "/api/points/pending_posts"},
+	{"GET", "/api/points/posts_with_points"},
+	{"PUT", "/api/points/post_point/1"},
+	{"POST", "/api/points/reward_redeem"},
+	{"GET", "/api/points/redeemed/1"},
+	{"DELETE", "/api/points/rewards/1"},
+	{"POST", "/api/points/distribute/1"},
+	{"GET", "/api/points/distributed/1"},
+	{"GET", "/api/results"},
+	{"GET", "/api/results/post/1"},
+	{"POST", "/api/results"},
+	{"PUT", "/api/results/1"},
+	{"POST", "/api/registration"},
+	{"GET", "/api/registration/my"},
+	{"GET", "/api/registration/invitations"},
+	{"POST", "/api/registration/invitations/1/accept"},
+	{"POST", "/api/registration/invitations/1/decline"},
+	{"GET", "/api/registration/1/invitations"},
+	{"POST", "/api/registration/1/invitations"},
+	{"DELETE", "/api/registration/1/invitations/1"},
+	{"POST", "/api/registration/1/leader"},
+	{"GET", "/api/registration/post/1"},
+	{"GET", "/api/registration/post/1/export"},
+	{"GET", "/api/registration/post/1/withdrawals"},
+	{"POST", "/api/registration/1/withdraw"},
+	{"POST", "/api/registration/upload"},
+	{"GET", "/api/registration/1"},
+	{"PATCH", "/api/registration/1"},
+	{"PUT", "/api/registration/1/status"},
+	{"POST", "/api/registration/1/confirm"},
+	{"DELETE", "/api/registration/1"},
+	{"POST", "/api/registration/1/users"},
+	{"DELETE", "/api/registration/1/users"},
+	{"GET", "/api/posts/1/registrations"},
+	{"POST", "/api/evaluation/topics"},
+	{"GET", "/api/evaluation/topics/1"},
+	{"PUT", "/api/evaluation/topics/1"},
+	{"DELETE", "/api/evaluation/topics/1"},
+	{"POST", "/api/evaluation/submit"},
+	{"GET", "/api/evaluation/activity"},
+	{"GET", "/api/evaluation/summary"},
+	{"GET", "/api/evaluation/results/1"},
+}
+
+// routes ที่เฉพาะ admin เท่านั้น
+var adminOnlyRoutes = []protectedRoute{
+	{"GET", "/api/auth/lockouts"},
+	{"POST", "/api/auth/unlock/B6614690"},
+	{"GET", "/api/users"},
+	{"GET", "/api/audit-logs"},
+	{"GET", "/api/audit-logs/export"},
+	{"GET", "/api/roles"},
+	{"POST", "/api/roles"},
+	{"PUT", "/api/roles/1/permissions"},
+	{"GET", "/api/permissions"},
+	{"PUT", "/api/users/1/role"},
+	{"POST", "/api/users/1/deactivate"},
+	{"POST", "/api/users/1/reactivate"},
+	{"POST", "/api/users/1/force-password-reset"},
+	{"POST", "/api/users/1/impersonate"},
+	{"POST", "/api/users/1/anonymize"},
+	{"GET", "/api/portfolios"},
+	{"GET", "/api/post/review-queue"},
+	{"POST", "/api/post/1/approve"},
+	{"POST", "/api/post/1/reject"},
+	{"POST", "/api/certificate"},
+	{"PUT", "/api/certificate/1"},
+	{"DELETE", "/api/certificate/1"},
+	{"POST", "/api/points/rewards"},
+	{"PUT", "/api/points/rewards/1"},
+	{"DELETE", "/api/points/rewards/1"},
+	{"GET", "/api/points/pending_posts"},
+	{"GET", "/api/points/posts_with_points"},
+	{"PUT", "/api/points/post_point/1"},
+	{"POST", "/api/points/distribute/1"},
+	{"GET", "/api/points/distributed/1"},
+	{"POST", "/api/results"},
+	{"PUT", "/api/results/1"},
+	{"PUT", "/api/registration/1/status"},
+	{"DELETE", "/api/registration/1"},
+	{"POST", "/api/evaluation/topics"},
+	{"PUT", "/api/evaluation/topics/1"},
+	{"DELETE", "/api/evaluation/topics/1"},
+	{"GET", "/api/evaluation/summary"},
+	{"GET", "/api/evaluation/results/1"},
+}
+
+// routes ที่นักศึกษาเข้าถึงได้เฉพาะข้อมูลของตัวเอง (:userId)
+var selfOnlyRoutes = []protectedRoute{
+	{"GET", "/api/points/total/%d"},
+	{"POST", "/api/points/checkin/%d"},
+	{"GET", "/api/points/membership/%d"},
+	{"GET", "/api/points/records/%d"},
+	{"GET", "/api/points/redeemed/%d"},
+}
+
+func setupRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	api := r.Group("/api")
+	routes.AuthRoutes(api)
+	routes.UserRoutes(api)
+	routes.MetadataRoutes(api)
+	routes.PortfolioRoutes(api)
+	routes.PostRoutes(api)
+	routes.CertificateRoutes(api)
+	routes.ChatRoutes(api, config.DB, services.NewChatHub())
+	routes.PointRoutes(api)
+	routes.ResultsRoutes(api, config.DB)
+	routes.RegistrationRoutes(api)
+	routes.EvaluationRoutes(api)
+	routes.APITokenRoutes(api)
+	routes.AuditRoutes(api)
+	routes.SearchRoutes(api)
+	return r
+}
+
+func doRequest(r *gin.Engine, method, path, token string) int {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	g := NewGomegaWithT(t)
+	r := setupRouter()
+
+	for _, route := range authenticatedRoutes {
+		code := doRequest(r, route.method, route.path, "")
+		g.Expect(code).To(Equal(http.StatusUnauthorized), "%s %s should require a token", route.method, route.path)
+	}
+}
+
+func TestAdminOnlyRoutesRejectStudent(t *testing.T) {
+	g := NewGomegaWithT(t)
+	r := setupRouter()
+
+	token, err := config.GenerateJWT(2, "B6614690", entity.RoleStudent, "test-session")
+	g.Expect(err).To(BeNil())
+
+	for _, route := range adminOnlyRoutes {
+		code := doRequest(r, route.method, route.path, token)
+		g.Expect(code).To(Equal(http.StatusForbidden), "%s %s should be admin only", route.method, route.path)
+	}
+}
+
+func TestSelfOnlyRoutesRejectOtherStudent(t *testing.T) {
+	g := NewGomegaWithT(t)
+	r := setupRouter()
+
+	token, err := config.GenerateJWT(2, "B6614690", entity.RoleStudent, "test-session")
+	g.Expect(err).To(BeNil())
+
+	for _, route := range selfOnlyRoutes {
+		path := fmt.Sprintf(route.path, 3)
+		code := doRequest(r, route.method, path, token)
+		g.Expect(code).To(Equal(http.StatusForbidden), "%s %s should reject another user's id", route.method, path)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	g := NewGomegaWithT(t)
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(role string) *gin.Engine {
+		r := gin.New()
+		r.GET("/admin", func(ctx *gin.Context) {
+			ctx.Set("user_id", uint(1))
+			ctx.Set("role", role)
+		}, middleware.RequireRole(entity.RoleAdmin), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+		r.GET("/points/:userId", func(ctx *gin.Context) {
+			ctx.Set("user_id", uint(1))
+			ctx.Set("role", role)
+		}, middleware.RequireSelfOrRole("userId", entity.RoleAdmin), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+		return r
+	}
+
+	t.Run("admin is allowed", func(t *testing.T) {
+		g.Expect(doRequest(newRouter(entity.RoleAdmin), "GET", "/admin", "")).To(Equal(http.StatusOK))
+	})
+
+	t.Run("student is forbidden", func(t *testing.T) {
+		g.Expect(doRequest(newRouter(entity.RoleStudent), "GET", "/admin", "")).To(Equal(http.StatusForbidden))
+	})
+
+	t.Run("missing role is forbidden", func(t *testing.T) {
+		g.Expect(doRequest(newRouter(""), "GET", "/admin", "")).To(Equal(http.StatusForbidden))
+	})
+
+	t.Run("student can access own resource", func(t *testing.T) {
+		g.Expect(doRequest(newRouter(entity.RoleStudent), "GET", "/points/1", "")).To(Equal(http.StatusOK))
+	})
+
+	t.Run("student cannot access other user's resource", func(t *testing.T) {
+		g.Expect(doRequest(newRouter(entity.RoleStudent), "GET", "/points/2", "")).To(Equal(http.StatusForbidden))
+	})
+
+	t.Run("admin can access other user's resource", func(t *testing.T) {
+		g.Expect(doRequest(newRouter(entity.RoleAdmin), "GET", "/points/2", "")).To(Equal(http.StatusOK))
+	})
+}
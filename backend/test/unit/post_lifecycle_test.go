@@ -0,0 +1,99 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+)
+
+func TestPostStatusTransitions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("review and schedule transitions are allowed", func(t *testing.T) {
+		g.Expect(entity.CanTransitionPostStatus(entity.PostStatusPending, entity.PostStatusApproved)).To(BeTrue())
+		g.Expect(entity.CanTransitionPostStatus(entity.PostStatusPending, entity.PostStatusRejected)).To(BeTrue())
+		g.Expect(entity.CanTransitionPostStatus(entity.PostStatusRejected, entity.PostStatusPending)).To(BeTrue())
+		g.Expect(entity.CanTransitionPostStatus(entity.PostStatusApproved, entity.PostStatusUpcoming)).To(BeTrue())
+		g.Expect(entity.CanTransitionPostStatus(entity.PostStatusUpcoming, entity.PostStatusActive)).To(BeTrue())
+		g.Expect(entity.CanTransitionPostStatus(entity.PostStatusActive, entity.PostStatusEnded)).To(BeTrue())
+		g.Expect(entity.CanTransitionPostStatus(entity.PostStatusActive, entity.PostStatusActive)).To(BeTrue())
+	})
+
+	t.Run("jumps and reversals are rejected", func(t *testing.T) {
+		g.Expect(entity.CanTransitionPostStatus(entity.PostStatusPending, entity.PostStatusEnded)).To(BeFalse())
+		g.Expect(entity.CanTransitionPostStatus(entity.PostStatusPending, entity.PostStatusActive)).To(BeFalse())
+		g.Expect(entity.CanTransitionPostStatus(entity.PostStatusRejected, entity.PostStatusApproved)).To(BeFalse())
+		g.Expect(entity.CanTransitionPostStatus(entity.PostStatusActive, entity.PostStatusUpcoming)).To(BeFalse())
+		g.Expect(entity.CanTransitionPostStatus(entity.PostStatusEnded, entity.PostStatusActive)).To(BeFalse())
+	})
+
+	t.Run("every status has a name and a transition entry", func(t *testing.T) {
+		for id := entity.PostStatusPending; id <= entity.PostStatusEnded; id++ {
+			g.Expect(entity.PostStatusNames).To(HaveKey(id))
+			g.Expect(entity.PostStatusTransitions).To(HaveKey(id))
+		}
+	})
+}
+
+func TestScheduledPostStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	post := entity.Post{
+		StartDate: now.Add(24 * time.Hour),
+		StopDate:  now.Add(48 * time.Hour),
+	}
+
+	t.Run("approved post becomes upcoming before it starts", func(t *testing.T) {
+		next, ok := services.ScheduledPostStatus(entity.PostStatusApproved, post, now)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(next).To(Equal(entity.PostStatusUpcoming))
+	})
+
+	t.Run("upcoming post becomes active when it starts", func(t *testing.T) {
+		next, ok := services.ScheduledPostStatus(entity.PostStatusUpcoming, post, now.Add(25*time.Hour))
+		g.Expect(ok).To(BeTrue())
+		g.Expect(next).To(Equal(entity.PostStatusActive))
+	})
+
+	t.Run("active post ends after the stop date", func(t *testing.T) {
+		next, ok := services.ScheduledPostStatus(entity.PostStatusActive, post, now.Add(49*time.Hour))
+		g.Expect(ok).To(BeTrue())
+		g.Expect(next).To(Equal(entity.PostStatusEnded))
+	})
+
+	t.Run("upcoming post that was missed goes straight to ended", func(t *testing.T) {
+		next, ok := services.ScheduledPostStatus(entity.PostStatusUpcoming, post, now.Add(72*time.Hour))
+		g.Expect(ok).To(BeTrue())
+		g.Expect(next).To(Equal(entity.PostStatusEnded))
+	})
+
+	t.Run("pending, rejected and ended posts are not moved", func(t *testing.T) {
+		for _, status := range []uint{entity.PostStatusPending, entity.PostStatusRejected, entity.PostStatusEnded} {
+			_, ok := services.ScheduledPostStatus(status, post, now.Add(72*time.Hour))
+			g.Expect(ok).To(BeFalse())
+		}
+	})
+
+	t.Run("active post is not moved back to upcoming", func(t *testing.T) {
+		_, ok := services.ScheduledPostStatus(entity.PostStatusActive, post, now)
+		g.Expect(ok).To(BeFalse())
+	})
+}
+
+func TestPublishedPostStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(entity.IsPublishedPostStatus(entity.PostStatusPending)).To(BeFalse())
+	g.Expect(entity.IsPublishedPostStatus(entity.PostStatusRejected)).To(BeFalse())
+	for _, status := range []uint{entity.PostStatusApproved, entity.PostStatusUpcoming, entity.PostStatusActive, entity.PostStatusEnded} {
+		g.Expect(entity.IsPublishedPostStatus(status)).To(BeTrue())
+	}
+
+	// รายการของนักศึกษาแสดงเฉพาะโพสต์ที่อนุมัติแล้ว
+	for _, status := range entity.StudentVisiblePostStatuses {
+		g.Expect(entity.IsPublishedPostStatus(status)).To(BeTrue())
+	}
+}
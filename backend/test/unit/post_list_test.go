@@ -0,0 +1,75 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+)
+
+func TestPostOrderClause(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("default is newest first", func(t *testing.T) {
+		order, err := services.PostOrderClause("")
+		g.Expect(err).To(BeNil())
+		g.Expect(order).To(Equal("posts.created_at DESC, posts.id DESC"))
+	})
+
+	t.Run("minus prefix sorts descending", func(t *testing.T) {
+		order, err := services.PostOrderClause("start_date")
+		g.Expect(err).To(BeNil())
+		g.Expect(order).To(Equal("posts.start_date ASC, posts.id ASC"))
+
+		order, err = services.PostOrderClause("-title")
+		g.Expect(err).To(BeNil())
+		g.Expect(order).To(Equal("posts.title DESC, posts.id DESC"))
+	})
+
+	t.Run("unknown or injected fields are rejected", func(t *testing.T) {
+		for _, sort := range []string{"password", "title; DROP TABLE posts", "--title"} {
+			_, err := services.PostOrderClause(sort)
+			g.Expect(err).To(Equal(services.ErrInvalidPostSort), sort)
+		}
+	})
+}
+
+func TestPostListItem(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	status := entity.PostStatusUpcoming
+	post := entity.Post{
+		Title:     "Hackathon",
+		StartDate: now.AddDate(0, 0, 5),
+		StopDate:  now.AddDate(0, 0, 6),
+		Start:     now.AddDate(0, 0, -1),
+		Stop:      now.AddDate(0, 0, 1),
+		StatusID:  &status,
+		Status:    &entity.PostStatus{StatusName: "Upcoming"},
+		User:      &entity.User{FirstName: "Somchai", Role: &entity.Role{Name: entity.RoleStudent}},
+	}
+
+	t.Run("registration is open inside the window of a published post", func(t *testing.T) {
+		g.Expect(services.IsRegistrationOpen(post, now)).To(BeTrue())
+		g.Expect(services.IsRegistrationOpen(post, now.AddDate(0, 0, 2))).To(BeFalse())
+		g.Expect(services.IsRegistrationOpen(post, now.AddDate(0, 0, -2))).To(BeFalse())
+
+		pending := entity.PostStatusPending
+		unpublished := post
+		unpublished.StatusID = &pending
+		g.Expect(services.IsRegistrationOpen(unpublished, now)).To(BeFalse())
+	})
+
+	t.Run("list item keeps only summaries of related rows", func(t *testing.T) {
+		item := services.PostListItemOf(post, now)
+		g.Expect(item.Title).To(Equal("Hackathon"))
+		g.Expect(item.RegistrationOpen).To(BeTrue())
+		g.Expect(item.Status.StatusName).To(Equal("Upcoming"))
+		g.Expect(item.User.FirstName).To(Equal("Somchai"))
+		g.Expect(item.User.Role.Name).To(Equal(entity.RoleStudent))
+		g.Expect(item.Location).To(BeNil())
+	})
+}
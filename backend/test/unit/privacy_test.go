@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+)
+
+func TestUserUploads(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	user := &entity.User{AvatarURL: "upload/profile/B6614690.png"}
+	messages := []entity.Messages{
+		{Body: "upload/chat/file/report.pdf", MessagesTypeID: 3},
+		{Body: "upload/chat/photo/cat.jpg", MessagesTypeID: 2},
+		{Body: "upload/not-a-file-just-text", MessagesTypeID: 1},
+		{Body: "https://example.com/external.jpg", MessagesTypeID: 2},
+		{Body: "upload/../config/.env", MessagesTypeID: 3},
+		{Body: "[DELETED]", MessagesTypeID: 2},
+	}
+
+	g.Expect(services.UserUploads(user, messages)).To(Equal([]string{
+		"upload/profile/B6614690.png",
+		"upload/chat/file/report.pdf",
+		"upload/chat/photo/cat.jpg",
+	}))
+
+	t.Run("external avatar is not exported", func(t *testing.T) {
+		g.Expect(services.UserUploads(&entity.User{AvatarURL: "https://cdn.example.com/a.png"}, nil)).To(BeEmpty())
+	})
+}
+
+func TestRegistrationUploads(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	fileField := &entity.RegistrationFormField{Type: entity.FormFieldFile}
+	textField := &entity.RegistrationFormField{Type: entity.FormFieldText}
+	answers := []*entity.RegistrationAnswer{
+		{Field: fileField, Value: "upload/registration/resume.pdf"},
+		{Field: textField, Value: "upload/registration/looks-like-a-path"},
+		{Field: fileField, Value: "https://drive.example.com/slides"},
+		{Field: fileField, Value: "upload/../config/.env"},
+		{Field: fileField, Value: ""},
+		{Value: "upload/registration/no-field.pdf"},
+	}
+
+	g.Expect(services.RegistrationUploads(answers)).To(Equal([]string{"upload/registration/resume.pdf"}))
+}
+
+func TestDeleteAccountWhileImpersonating(t *testing.T) {
+	g := NewGomegaWithT(t)
+	r := setupRouter()
+
+	token, err := config.GenerateImpersonationJWT(2, "B6614690", entity.RoleStudent, "imp-delete", 1, services.ImpersonationTTL)
+	g.Expect(err).To(BeNil())
+	g.Expect(doRequest(r, "DELETE", "/api/profiles/me", token)).To(Equal(http.StatusForbidden))
+}
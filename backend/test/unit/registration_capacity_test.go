@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+)
+
+func TestTeamSize(t *testing.T) {
+	g := NewGomegaWithT(t)
+	post := entity.Post{MinTeamSize: 2, MaxTeamSize: 4}
+
+	g.Expect(services.CheckTeamSize(post, 2)).To(BeNil())
+	g.Expect(services.CheckTeamSize(post, 4)).To(BeNil())
+	g.Expect(errors.Is(services.CheckTeamSize(post, 1), services.ErrTeamTooSmall)).To(BeTrue())
+	g.Expect(errors.Is(services.CheckTeamSize(post, 5), services.ErrTeamTooLarge)).To(BeTrue())
+
+	// 0 คือไม่จำกัด
+	g.Expect(services.CheckTeamSize(entity.Post{}, 50)).To(BeNil())
+}
+
+func TestRegistrationCapacity(t *testing.T) {
+	g := NewGomegaWithT(t)
+	post := entity.Post{MaxTeams: 3, MaxSeats: 10}
+
+	t.Run("remaining capacity is reported", func(t *testing.T) {
+		capacity := services.CapacityOf(post, services.CapacityUsage{Teams: 2, Seats: 7})
+		g.Expect(*capacity.RemainingTeams).To(Equal(int64(1)))
+		g.Expect(*capacity.RemainingSeats).To(Equal(int64(3)))
+
+		unlimited := services.CapacityOf(entity.Post{}, services.CapacityUsage{Teams: 2, Seats: 7})
+		g.Expect(unlimited.RemainingTeams).To(BeNil())
+		g.Expect(unlimited.RemainingSeats).To(BeNil())
+	})
+
+	t.Run("remaining never goes negative after limits are lowered", func(t *testing.T) {
+		capacity := services.CapacityOf(post, services.CapacityUsage{Teams: 5, Seats: 12})
+		g.Expect(*capacity.RemainingTeams).To(Equal(int64(0)))
+		g.Expect(*capacity.RemainingSeats).To(Equal(int64(0)))
+	})
+
+	t.Run("new teams and members must fit", func(t *testing.T) {
+		g.Expect(services.CheckCapacity(post, services.CapacityUsage{Teams: 2, Seats: 7}, 1, 3)).To(BeNil())
+		g.Expect(services.CheckCapacity(post, services.CapacityUsage{Teams: 3, Seats: 7}, 1, 1)).To(Equal(services.ErrTeamsFull))
+		g.Expect(errors.Is(services.CheckCapacity(post, services.CapacityUsage{Teams: 2, Seats: 7}, 1, 4), services.ErrSeatsFull)).To(BeTrue())
+		// เพิ่มสมาชิกในทีมเดิมไม่ใช้โควตาทีม
+		g.Expect(services.CheckCapacity(post, services.CapacityUsage{Teams: 3, Seats: 9}, 0, 1)).To(BeNil())
+	})
+}
+
+func TestPostTeamSizeLimits(t *testing.T) {
+	g := NewGomegaWithT(t)
+	userID, statusID, locationID := uint(1), uint(1), uint(1)
+	now := time.Now()
+	post := entity.Post{
+		Title:      "Hackathon",
+		Detail:     "Details",
+		Type:       "Competition",
+		Organizer:  "SUT",
+		StartDate:  now,
+		StopDate:   now.Add(48 * time.Hour),
+		Start:      now,
+		Stop:       now.Add(24 * time.Hour),
+		UserID:     &userID,
+		StatusID:   &statusID,
+		LocationID: &locationID,
+	}
+
+	post.MinTeamSize, post.MaxTeamSize = 3, 2
+	ok, err := services.ValidatePost(post)
+	g.Expect(ok).To(BeFalse())
+	g.Expect(err.Error()).To(ContainSubstring("minimum team size must not be greater than maximum team size"))
+
+	post.MinTeamSize, post.MaxTeamSize, post.MaxSeats = 3, 5, 2
+	ok, err = services.ValidatePost(post)
+	g.Expect(ok).To(BeFalse())
+	g.Expect(err.Error()).To(ContainSubstring("total seats must be at least the minimum team size"))
+
+	post.MaxSeats = 30
+	ok, err = services.ValidatePost(post)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(err).To(BeNil())
+}
+
+func TestNextWaitlistOffers(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("teams are offered in queue order until full", func(t *testing.T) {
+		post := entity.Post{MaxTeams: 5}
+		offers := services.NextWaitlistOffers(post, services.CapacityUsage{Teams: 3}, []int{2, 3, 1})
+		g.Expect(offers).To(Equal([]int{0, 1}))
+	})
+
+	t.Run("a team too large for the free seats is skipped for the next one that fits", func(t *testing.T) {
+		post := entity.Post{MaxSeats: 10}
+		offers := services.NextWaitlistOffers(post, services.CapacityUsage{Teams: 2, Seats: 7}, []int{4, 2, 1})
+		g.Expect(offers).To(Equal([]int{1, 2}))
+	})
+
+	t.Run("nothing is offered while the post is still full", func(t *testing.T) {
+		post := entity.Post{MaxTeams: 2, MaxSeats: 10}
+		g.Expect(services.NextWaitlistOffers(post, services.CapacityUsage{Teams: 2, Seats: 4}, []int{1})).To(BeEmpty())
+	})
+}
@@ -0,0 +1,60 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+)
+
+func TestSchedulesOverlap(t *testing.T) {
+	g := NewGomegaWithT(t)
+	day := func(d int) time.Time { return time.Date(2026, 5, d, 9, 0, 0, 0, time.UTC) }
+	hackathon := entity.Post{StartDate: day(10), StopDate: day(12)}
+
+	cases := map[string]struct {
+		other entity.Post
+		want  bool
+	}{
+		"same dates":          {entity.Post{StartDate: day(10), StopDate: day(12)}, true},
+		"starts inside":       {entity.Post{StartDate: day(11), StopDate: day(15)}, true},
+		"ends inside":         {entity.Post{StartDate: day(8), StopDate: day(11)}, true},
+		"contains":            {entity.Post{StartDate: day(1), StopDate: day(20)}, true},
+		"ends when it starts": {entity.Post{StartDate: day(8), StopDate: day(10)}, false},
+		"starts when it ends": {entity.Post{StartDate: day(12), StopDate: day(14)}, false},
+		"before":              {entity.Post{StartDate: day(1), StopDate: day(3)}, false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			g.Expect(services.SchedulesOverlap(hackathon, tc.other)).To(Equal(tc.want))
+			g.Expect(services.SchedulesOverlap(tc.other, hackathon)).To(Equal(tc.want))
+		})
+	}
+}
+
+func TestHoldsPlace(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("teams still in the running block another team in the same post", func(t *testing.T) {
+		for _, status := range []string{
+			entity.RegistrationStatusPending,
+			entity.RegistrationStatusApproved,
+			entity.RegistrationStatusOffered,
+			entity.RegistrationStatusWaitlisted,
+		} {
+			g.Expect(services.HoldsPlace(status)).To(BeTrue(), status)
+		}
+	})
+
+	t.Run("members may re-join after rejection, expiry or withdrawal", func(t *testing.T) {
+		for _, status := range []string{
+			entity.RegistrationStatusRejected,
+			entity.RegistrationStatusExpired,
+			entity.RegistrationStatusWithdrawn,
+		} {
+			g.Expect(services.HoldsPlace(status)).To(BeFalse(), status)
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+)
+
+func TestRegistrationWindow(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Now()
+	approved, pending := uint(entity.PostStatusApproved), uint(entity.PostStatusPending)
+	post := entity.Post{StatusID: &approved, Start: now.Add(-time.Hour), Stop: now.Add(time.Hour)}
+
+	g.Expect(services.CheckRegistrationWindow(post, now)).To(BeNil())
+	g.Expect(errors.Is(services.CheckRegistrationWindow(post, now.Add(-2*time.Hour)), services.ErrRegistrationNotOpen)).To(BeTrue())
+	g.Expect(services.CheckRegistrationWindow(post, now.Add(2*time.Hour))).To(Equal(services.ErrRegistrationClosed))
+
+	// โพสต์ที่ยังไม่อนุมัติรับสมัครไม่ได้แม้อยู่ในช่วงเวลา
+	post.StatusID = &pending
+	g.Expect(services.CheckRegistrationWindow(post, now)).To(Equal(services.ErrRegistrationNotOpen))
+}
+
+func TestRegistrationEligibility(t *testing.T) {
+	g := NewGomegaWithT(t)
+	post := entity.Post{
+		EligibleFacultyIDs: []uint{1},
+		EligibleYears:      []uint{3, 4},
+		EligibleRoles:      []string{entity.RoleStudent},
+	}
+	eligible := &entity.User{SutId: "B6500001", FirstName: "Somchai", FacultyID: 1, Year: 3, Role: &entity.Role{Name: entity.RoleStudent}}
+	wrongYear := &entity.User{SutId: "B6500002", FirstName: "Somsri", FacultyID: 1, Year: 1, Role: &entity.Role{Name: entity.RoleStudent}}
+
+	t.Run("no rules means everyone is eligible", func(t *testing.T) {
+		g.Expect(services.IneligibleReasons(entity.Post{}, *wrongYear)).To(BeEmpty())
+	})
+
+	t.Run("every rule is checked", func(t *testing.T) {
+		g.Expect(services.IneligibleReasons(post, *eligible)).To(BeEmpty())
+		g.Expect(services.IneligibleReasons(post, *wrongYear)).To(Equal([]string{"ชั้นปี"}))
+		g.Expect(services.IneligibleReasons(post, entity.User{FacultyID: 2, Year: 3})).To(Equal([]string{"คณะ", "บทบาท"}))
+	})
+
+	t.Run("error names the ineligible member", func(t *testing.T) {
+		g.Expect(services.CheckEligibility(post, []*entity.User{eligible})).To(BeNil())
+
+		err := services.CheckEligibility(post, []*entity.User{eligible, wrongYear})
+		g.Expect(errors.Is(err, services.ErrNotEligible)).To(BeTrue())
+		g.Expect(err.Error()).To(ContainSubstring("B6500002 Somsri"))
+		g.Expect(err.Error()).NotTo(ContainSubstring("B6500001"))
+	})
+}
@@ -0,0 +1,169 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+	"gorm.io/gorm"
+)
+
+func TestValidateFormFields(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("valid form is normalized", func(t *testing.T) {
+		fields, err := services.ValidateFormFields([]dto.RegistrationFormFieldInput{
+			{Label: "  ไซซ์เสื้อ ", Type: entity.FormFieldChoice, Required: true, Options: []string{"S", " M ", "L"}},
+			{Label: "GitHub", Type: entity.FormFieldText, Options: []string{"ignored"}},
+		})
+		g.Expect(err).To(BeNil())
+		g.Expect(fields[0].Label).To(Equal("ไซซ์เสื้อ"))
+		g.Expect(fields[0].Options).To(Equal([]string{"S", "M", "L"}))
+		g.Expect(fields[1].Options).To(BeNil())
+	})
+
+	invalid := map[string]dto.RegistrationFormFieldInput{
+		"empty label":       {Label: " ", Type: entity.FormFieldText},
+		"unknown type":      {Label: "Date", Type: "date"},
+		"choice no options": {Label: "Size", Type: entity.FormFieldChoice},
+		"duplicate options": {Label: "Food", Type: entity.FormFieldMultiChoice, Options: []string{"ฮาลาล", "ฮาลาล"}},
+	}
+	for name, input := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := services.ValidateFormFields([]dto.RegistrationFormFieldInput{input})
+			g.Expect(errors.Is(err, services.ErrInvalidFormField)).To(BeTrue())
+		})
+	}
+
+	t.Run("the same field cannot appear twice", func(t *testing.T) {
+		_, err := services.ValidateFormFields([]dto.RegistrationFormFieldInput{
+			{ID: 3, Label: "A", Type: entity.FormFieldText},
+			{ID: 3, Label: "B", Type: entity.FormFieldText},
+		})
+		g.Expect(errors.Is(err, services.ErrInvalidFormField)).To(BeTrue())
+	})
+}
+
+func formField(id uint, label, fieldType string, required bool, options ...string) entity.RegistrationFormField {
+	return entity.RegistrationFormField{Model: gorm.Model{ID: id}, Label: label, Type: fieldType, Required: required, Options: options}
+}
+
+func answer(fieldID uint, value any) dto.RegistrationAnswerInput {
+	raw, _ := json.Marshal(value)
+	return dto.RegistrationAnswerInput{FieldID: fieldID, Value: raw}
+}
+
+func TestValidateAnswers(t *testing.T) {
+	g := NewGomegaWithT(t)
+	fields := []entity.RegistrationFormField{
+		formField(1, "ไซซ์เสื้อ", entity.FormFieldChoice, true, "S", "M", "L"),
+		formField(2, "อาหารที่แพ้", entity.FormFieldMultiChoice, false, "นม", "ถั่ว", "กุ้ง"),
+		formField(3, "จำนวนปีที่เขียนโปรแกรม", entity.FormFieldNumber, false),
+		formField(4, "GitHub", entity.FormFieldText, false),
+		formField(5, "Proposal", entity.FormFieldFile, false),
+	}
+
+	t.Run("answers are stored by type", func(t *testing.T) {
+		answers, err := services.ValidateAnswers(fields, []dto.RegistrationAnswerInput{
+			answer(1, "M"),
+			answer(2, []string{"ถั่ว", "นม", "ถั่ว"}),
+			answer(3, 2.5),
+			answer(4, " github.com/team21 "),
+			answer(5, "upload/registration/a.pdf"),
+		})
+		g.Expect(err).To(BeNil())
+		values := map[uint]string{}
+		for _, a := range answers {
+			values[a.FieldID] = a.Value
+		}
+		g.Expect(values).To(Equal(map[uint]string{
+			1: "M",
+			2: `["ถั่ว","นม"]`,
+			3: "2.5",
+			4: "github.com/team21",
+			5: "upload/registration/a.pdf",
+		}))
+	})
+
+	t.Run("optional blanks are skipped but required ones are not", func(t *testing.T) {
+		answers, err := services.ValidateAnswers(fields, []dto.RegistrationAnswerInput{answer(1, "S"), answer(2, []string{}), answer(4, "")})
+		g.Expect(err).To(BeNil())
+		g.Expect(answers).To(HaveLen(1))
+
+		_, err = services.ValidateAnswers(fields, nil)
+		g.Expect(errors.Is(err, services.ErrInvalidAnswer)).To(BeTrue())
+		g.Expect(err.Error()).To(ContainSubstring("ไซซ์เสื้อ"))
+	})
+
+	invalid := map[string][]dto.RegistrationAnswerInput{
+		"unknown option":        {answer(1, "XL")},
+		"unknown multi option":  {answer(1, "S"), answer(2, []string{"ไข่"})},
+		"number as text":        {answer(1, "S"), answer(3, "two")},
+		"file outside uploads":  {answer(1, "S"), answer(5, "../../etc/passwd")},
+		"external file":         {answer(1, "S"), answer(5, "https://example.com/a.pdf")},
+		"field of another post": {answer(1, "S"), answer(99, "x")},
+		"duplicate answer":      {answer(1, "S"), answer(1, "M")},
+	}
+	for name, inputs := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := services.ValidateAnswers(fields, inputs)
+			g.Expect(errors.Is(err, services.ErrInvalidAnswer)).To(BeTrue())
+		})
+	}
+}
+
+func TestWriteRegistrationsCSV(t *testing.T) {
+	g := NewGomegaWithT(t)
+	fields := []entity.RegistrationFormField{
+		formField(1, "ไซซ์เสื้อ", entity.FormFieldChoice, true, "S", "M"),
+		formField(2, "อาหารที่แพ้", entity.FormFieldMultiChoice, false, "นม", "ถั่ว"),
+	}
+	registrations := []entity.Registration{{
+		Model:    gorm.Model{ID: 7},
+		TeamName: "=Team",
+		Status:   entity.RegistrationStatusApproved,
+		Users: []*entity.User{
+			{SutId: "B6500001", FirstName: "Somchai", LastName: "Jaidee"},
+			{SutId: "B6500002", FirstName: "Somsri"},
+		},
+		Answers: []*entity.RegistrationAnswer{
+			{FieldID: 1, Value: "M"},
+			{FieldID: 2, Value: `["นม","ถั่ว"]`},
+		},
+	}}
+
+	var buf bytes.Buffer
+	g.Expect(services.WriteRegistrationsCSV(&buf, fields, registrations)).To(Succeed())
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	g.Expect(lines).To(HaveLen(2))
+	g.Expect(lines[0]).To(HaveSuffix(",ไซซ์เสื้อ,อาหารที่แพ้"))
+	g.Expect(lines[1]).To(HavePrefix("7,'=Team,approved,"))
+	g.Expect(lines[1]).To(ContainSubstring("B6500001; B6500002,Somchai Jaidee; Somsri"))
+	g.Expect(lines[1]).To(HaveSuffix(",M,นม; ถั่ว"))
+}
+
+func TestCheckTeamOpen(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	for _, status := range []string{
+		entity.RegistrationStatusPending,
+		entity.RegistrationStatusApproved,
+		entity.RegistrationStatusOffered,
+		entity.RegistrationStatusWaitlisted,
+	} {
+		g.Expect(services.CheckTeamOpen(status)).To(Succeed(), status)
+	}
+	for _, status := range []string{
+		entity.RegistrationStatusRejected,
+		entity.RegistrationStatusExpired,
+		entity.RegistrationStatusWithdrawn,
+	} {
+		g.Expect(services.CheckTeamOpen(status)).To(MatchError(services.ErrTeamClosed), status)
+	}
+}
@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+	"gorm.io/gorm"
+)
+
+func TestStatusChangeMessage(t *testing.T) {
+	g := NewGomegaWithT(t)
+	registration := entity.Registration{
+		Model:    gorm.Model{ID: 7},
+		TeamName: "Robo Cats",
+		Post:     &entity.Post{Title: "Hackathon 2026"},
+	}
+
+	t.Run("rejection includes the previous status and reason", func(t *testing.T) {
+		subject, body := services.StatusChangeMessage(registration, entity.RegistrationStatusHistory{
+			FromStatus: entity.RegistrationStatusPending,
+			ToStatus:   entity.RegistrationStatusRejected,
+			Reason:     "สมาชิกไม่ครบ",
+		})
+		g.Expect(subject).To(Equal("ทีม Robo Cats: ไม่อนุมัติ"))
+		g.Expect(body).To(ContainSubstring("Hackathon 2026"))
+		g.Expect(body).To(ContainSubstring("จากเดิม \"รอการอนุมัติ\""))
+		g.Expect(body).To(ContainSubstring("เหตุผล: สมาชิกไม่ครบ"))
+		g.Expect(body).To(ContainSubstring("/student/registrations/7"))
+	})
+
+	t.Run("first status has no previous status", func(t *testing.T) {
+		_, body := services.StatusChangeMessage(registration, entity.RegistrationStatusHistory{
+			ToStatus: entity.RegistrationStatusWaitlisted,
+		})
+		g.Expect(body).To(ContainSubstring("อยู่ในคิวสำรอง"))
+		g.Expect(body).NotTo(ContainSubstring("จากเดิม"))
+		g.Expect(body).NotTo(ContainSubstring("เหตุผล"))
+	})
+
+	t.Run("offers include the confirmation deadline", func(t *testing.T) {
+		deadline := time.Date(2026, 6, 1, 9, 30, 0, 0, time.UTC)
+		offered := registration
+		offered.OfferExpiresAt = &deadline
+		_, body := services.StatusChangeMessage(offered, entity.RegistrationStatusHistory{
+			FromStatus: entity.RegistrationStatusWaitlisted,
+			ToStatus:   entity.RegistrationStatusOffered,
+		})
+		g.Expect(body).To(ContainSubstring("01/06/2026 09:30"))
+	})
+
+	t.Run("line breaks in the team name do not reach the subject", func(t *testing.T) {
+		injected := registration
+		injected.TeamName = "Robo\r\nBcc: attacker@example.com"
+		subject, _ := services.StatusChangeMessage(injected, entity.RegistrationStatusHistory{
+			ToStatus: entity.RegistrationStatusApproved,
+		})
+		g.Expect(subject).To(Equal("ทีม Robo Bcc: attacker@example.com: อนุมัติแล้ว"))
+		g.Expect(subject).NotTo(ContainSubstring("\n"))
+	})
+}
+
+func TestRegistrationStatusLabel(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(services.RegistrationStatusLabel(entity.RegistrationStatusWithdrawn)).To(Equal("ถอนการสมัครแล้ว"))
+	g.Expect(services.RegistrationStatusLabel("unknown")).To(Equal("unknown"))
+}
@@ -1,9 +1,15 @@
 package unit
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/gin-gonic/gin"
 	"github.com/onsi/gomega"
+	"github.com/sut68/team21/controller"
 	"github.com/sut68/team21/entity"
 )
 
@@ -99,3 +105,23 @@ func TestRegistration(t *testing.T) {
 		}
 	})
 }
+
+func TestCreateRegistrationRejectsUnknownStatus(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.POST("/registration", func(ctx *gin.Context) {
+		ctx.Set("user_id", uint(1))
+		ctx.Set("role", entity.RoleAdmin)
+	}, controller.NewRegistrationController(nil).CreateRegistration)
+
+	for _, status := range []string{"approve", "offered", "withdrawn", "expired"} {
+		body := `{"team_name":"Team Alpha","description":"desc","post_id":1,"status":"` + status + `"}`
+		req := httptest.NewRequest("POST", "/registration", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		g.Expect(w.Code).To(gomega.Equal(http.StatusBadRequest), "status %q should be rejected", status)
+	}
+}
@@ -0,0 +1,88 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/dto"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+	"gorm.io/gorm"
+)
+
+func TestWithdrawalPenalty(t *testing.T) {
+	g := NewGomegaWithT(t)
+	day := func(d int) time.Time { return time.Date(2026, 6, d, 9, 0, 0, 0, time.UTC) }
+	userID, statusID, locationID := uint(1), uint(1), uint(1)
+	// ช่วงรับสมัคร (Start–Stop) อยู่ภายในช่วงจัดกิจกรรม ตามที่ ValidatePost บังคับ
+	post := entity.Post{
+		Title:                 "Hackathon",
+		Detail:                "48-hour hackathon",
+		Type:                  "Activity",
+		Organizer:             "SUT Team",
+		StartDate:             day(1),
+		StopDate:              day(30),
+		Start:                 day(1),
+		Stop:                  day(20),
+		UserID:                &userID,
+		StatusID:              &statusID,
+		LocationID:            &locationID,
+		WithdrawalCutoffHours: 48,
+		LateWithdrawalPenalty: 30,
+	}
+	ok, err := services.ValidatePost(post)
+	g.Expect(err).To(BeNil())
+	g.Expect(ok).To(BeTrue())
+
+	t.Run("withdrawing while registration is open is not late", func(t *testing.T) {
+		now := day(5)
+		g.Expect(now.After(post.StartDate)).To(BeTrue())
+		g.Expect(services.IsLateWithdrawal(post, now)).To(BeFalse())
+		g.Expect(services.WithdrawalPenalty(post, entity.RegistrationStatusApproved, now)).To(BeZero())
+	})
+
+	t.Run("before the cutoff is not late", func(t *testing.T) {
+		now := post.Stop.Add(-49 * time.Hour)
+		g.Expect(services.IsLateWithdrawal(post, now)).To(BeFalse())
+	})
+
+	t.Run("from the cutoff on is late", func(t *testing.T) {
+		now := post.Stop.Add(-48 * time.Hour)
+		g.Expect(services.IsLateWithdrawal(post, now)).To(BeTrue())
+		g.Expect(services.WithdrawalPenalty(post, entity.RegistrationStatusApproved, now)).To(Equal(uint(30)))
+		g.Expect(services.WithdrawalPenalty(post, entity.RegistrationStatusPending, now)).To(Equal(uint(30)))
+	})
+
+	t.Run("after registration closes is late", func(t *testing.T) {
+		g.Expect(services.IsLateWithdrawal(post, day(25))).To(BeTrue())
+	})
+
+	t.Run("waitlisted teams are not penalized", func(t *testing.T) {
+		g.Expect(services.WithdrawalPenalty(post, entity.RegistrationStatusWaitlisted, day(25))).To(BeZero())
+	})
+
+	t.Run("no cutoff means no penalty", func(t *testing.T) {
+		open := post
+		open.WithdrawalCutoffHours = 0
+		g.Expect(services.IsLateWithdrawal(open, day(25))).To(BeFalse())
+		g.Expect(services.WithdrawalPenalty(open, entity.RegistrationStatusApproved, day(25))).To(BeZero())
+	})
+}
+
+func TestNewWithdrawalReport(t *testing.T) {
+	g := NewGomegaWithT(t)
+	withdrawnAt := time.Date(2026, 5, 30, 12, 0, 0, 0, time.UTC)
+	members := []*entity.User{{SutId: "B6500001"}, {SutId: "B6500002"}}
+
+	report := dto.NewWithdrawalReport(3, []entity.Registration{
+		{Model: gorm.Model{ID: 1}, TeamName: "Late", Users: members, WithdrawnAt: &withdrawnAt, WithdrawalReason: "ป่วย", WithdrawalPenalty: 30},
+		{Model: gorm.Model{ID: 2}, TeamName: "Early", Users: members[:1], WithdrawnAt: &withdrawnAt, WithdrawalReason: "ติดสอบ"},
+	})
+	g.Expect(report.PostID).To(Equal(uint(3)))
+	g.Expect(report.Total).To(Equal(2))
+	g.Expect(report.Late).To(Equal(1))
+	g.Expect(report.PenaltyPoints).To(Equal(60))
+	g.Expect(report.Withdrawals[0].Members).To(Equal([]string{"B6500001", "B6500002"}))
+	g.Expect(report.Withdrawals[1].Reason).To(Equal("ติดสอบ"))
+}
@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+func TestDefaultRolePermissions(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("admin has every permission", func(t *testing.T) {
+		for name := range entity.PermissionDescriptions {
+			g.Expect(services.RoleHasPermission(entity.RoleAdmin, name)).To(BeTrue(), name)
+		}
+	})
+
+	t.Run("organizer manages only own activities", func(t *testing.T) {
+		g.Expect(services.RoleHasPermission(entity.RoleOrganizer, entity.PermPostsCreate)).To(BeTrue())
+		g.Expect(services.RoleHasPermission(entity.RoleOrganizer, entity.PermRegistrationsApprove)).To(BeTrue())
+		g.Expect(services.RoleHasPermission(entity.RoleOrganizer, entity.PermCertificatesIssue)).To(BeTrue())
+		g.Expect(services.RoleHasPermission(entity.RoleOrganizer, entity.PermActivitiesManageAny)).To(BeFalse())
+		g.Expect(services.RoleHasPermission(entity.RoleOrganizer, entity.PermUsersManage)).To(BeFalse())
+	})
+
+	t.Run("student can only propose posts", func(t *testing.T) {
+		g.Expect(services.RoleHasPermission(entity.RoleStudent, entity.PermPostsCreate)).To(BeTrue())
+		g.Expect(services.RoleHasPermission(entity.RoleStudent, entity.PermRegistrationsApprove)).To(BeFalse())
+	})
+
+	t.Run("unknown role has no permission", func(t *testing.T) {
+		g.Expect(services.RoleHasPermission("guest", entity.PermPostsCreate)).To(BeFalse())
+	})
+
+	t.Run("every default permission is declared", func(t *testing.T) {
+		for _, names := range entity.DefaultRolePermissions {
+			for _, name := range names {
+				g.Expect(entity.PermissionDescriptions).To(HaveKey(name))
+			}
+		}
+	})
+}
+
+func TestRequirePermission(t *testing.T) {
+	g := NewGomegaWithT(t)
+	gin.SetMode(gin.TestMode)
+	defer services.SetRolePermissions(entity.DefaultRolePermissions)
+
+	r := gin.New()
+	r.GET("/approve", middleware.AuthMiddleware(), middleware.RequirePermission(entity.PermRegistrationsApprove), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	r.GET("/points/:userId", middleware.AuthMiddleware(), middleware.RequireSelfOrPermission("userId", entity.PermPointsManage), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	organizer, _ := config.GenerateJWT(5, "B6500005", entity.RoleOrganizer, "organizer-session")
+	student, _ := config.GenerateJWT(2, "B6614690", entity.RoleStudent, "student-session")
+	admin, _ := config.GenerateJWT(1, "B0000001", entity.RoleAdmin, "admin-session")
+
+	t.Run("organizer may approve registrations", func(t *testing.T) {
+		g.Expect(doRequest(r, "GET", "/approve", organizer)).To(Equal(http.StatusOK))
+	})
+
+	t.Run("student may not approve registrations", func(t *testing.T) {
+		g.Expect(doRequest(r, "GET", "/approve", student)).To(Equal(http.StatusForbidden))
+	})
+
+	t.Run("self or permission", func(t *testing.T) {
+		g.Expect(doRequest(r, "GET", "/points/5", organizer)).To(Equal(http.StatusOK))
+		g.Expect(doRequest(r, "GET", "/points/2", organizer)).To(Equal(http.StatusForbidden))
+		g.Expect(doRequest(r, "GET", "/points/2", admin)).To(Equal(http.StatusOK))
+	})
+
+	t.Run("changed mapping applies to issued tokens", func(t *testing.T) {
+		services.SetRolePermissions(map[string][]string{
+			entity.RoleStudent: {entity.PermPostsCreate, entity.PermRegistrationsApprove},
+		})
+		g.Expect(doRequest(r, "GET", "/approve", student)).To(Equal(http.StatusOK))
+		g.Expect(doRequest(r, "GET", "/approve", organizer)).To(Equal(http.StatusForbidden))
+	})
+}
+
+func TestOrganizerCannotUseAdminRoutes(t *testing.T) {
+	g := NewGomegaWithT(t)
+	r := setupRouter()
+
+	token, err := config.GenerateJWT(5, "B6500005", entity.RoleOrganizer, "organizer-session")
+	g.Expect(err).To(BeNil())
+
+	for _, route := range []protectedRoute{
+		{"GET", "/api/users"},
+		{"PUT", "/api/users/1/role"},
+		{"GET", "/api/portfolios"},
+		{"PUT", "/api/points/post_point/1"},
+		{"POST", "/api/results"},
+		{"POST", "/api/evaluation/topics"},
+	} {
+		code := doRequest(r, route.method, route.path, token)
+		g.Expect(code).To(Equal(http.StatusForbidden), "%s %s should be forbidden for organizer", route.method, route.path)
+	}
+}
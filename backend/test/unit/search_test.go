@@ -0,0 +1,58 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/services"
+)
+
+func TestSearchQueryParsing(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("query is trimmed and limited", func(t *testing.T) {
+		q, err := services.NormalizeSearchQuery("  hackathon   2025 ")
+		g.Expect(err).To(BeNil())
+		g.Expect(q).To(Equal("hackathon 2025"))
+
+		_, err = services.NormalizeSearchQuery("   ")
+		g.Expect(err).To(Equal(services.ErrSearchQueryRequired))
+
+		q, err = services.NormalizeSearchQuery(strings.Repeat("ก", 150))
+		g.Expect(err).To(BeNil())
+		g.Expect([]rune(q)).To(HaveLen(services.MaxSearchQueryLength))
+	})
+
+	t.Run("types default to every group", func(t *testing.T) {
+		types, err := services.ParseSearchTypes("")
+		g.Expect(err).To(BeNil())
+		g.Expect(types).To(Equal(services.SearchTypes))
+
+		types, err = services.ParseSearchTypes("users, posts,users")
+		g.Expect(err).To(BeNil())
+		g.Expect(types).To(Equal([]string{"users", "posts"}))
+
+		_, err = services.ParseSearchTypes("messages")
+		g.Expect(err).To(Equal(services.ErrInvalidSearchType))
+	})
+}
+
+func TestSearchTSQuery(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(services.SearchTSQuery("Go Hack")).To(Equal("go:* & hack:*"))
+	// operator ของ tsquery ถูกตัดทิ้ง
+	g.Expect(services.SearchTSQuery("a|b & !c")).To(Equal("ab:* & c:*"))
+	g.Expect(services.SearchTSQuery("!!! &")).To(Equal(""))
+	// สระและวรรณยุกต์ภาษาไทยเป็น combining mark ต้องไม่ถูกตัด
+	g.Expect(services.SearchTSQuery("ค่ายวิศวกรรม")).To(Equal("ค่ายวิศวกรรม:*"))
+}
+
+func TestEscapeLike(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	g.Expect(services.EscapeLike("100%_off")).To(Equal(`100\%\_off`))
+	g.Expect(services.EscapeLike(`a\b`)).To(Equal(`a\\b`))
+	g.Expect(services.EscapeLike("ค่าย")).To(Equal("ค่าย"))
+}
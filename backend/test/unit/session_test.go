@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+func TestSecureToken(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	t.Run("tokens are random", func(t *testing.T) {
+		a, err := config.GenerateSecureToken(32)
+		g.Expect(err).To(BeNil())
+		b, err := config.GenerateSecureToken(32)
+		g.Expect(err).To(BeNil())
+		g.Expect(a).NotTo(Equal(b))
+		g.Expect(len(a)).To(Equal(43))
+	})
+
+	t.Run("hash is stable and does not contain the token", func(t *testing.T) {
+		token, _ := config.GenerateSecureToken(32)
+		g.Expect(config.HashToken(token)).To(Equal(config.HashToken(token)))
+		g.Expect(config.HashToken(token)).NotTo(ContainSubstring(token))
+	})
+}
+
+func TestAccessTokenClaims(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	token, err := config.GenerateJWT(2, "B6614690", entity.RoleStudent, "session-1")
+	g.Expect(err).To(BeNil())
+
+	claims, err := config.ValidateJWT(token)
+	g.Expect(err).To(BeNil())
+	g.Expect(claims.SessionID).To(Equal("session-1"))
+	g.Expect(claims.ExpiresAt.Sub(claims.IssuedAt.Time)).To(Equal(config.AccessTokenTTL))
+}
+
+func TestAuthMiddlewareSession(t *testing.T) {
+	g := NewGomegaWithT(t)
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/me", middleware.AuthMiddleware(), func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.GetString("session_id"))
+	})
+
+	t.Run("token with active session is accepted", func(t *testing.T) {
+		token, _ := config.GenerateJWT(2, "B6614690", entity.RoleStudent, "active-session")
+		g.Expect(doRequest(r, "GET", "/me", token)).To(Equal(http.StatusOK))
+	})
+
+	t.Run("token via query parameter is accepted", func(t *testing.T) {
+		token, _ := config.GenerateJWT(2, "B6614690", entity.RoleStudent, "active-session")
+		g.Expect(doRequest(r, "GET", "/me?token="+token, "")).To(Equal(http.StatusOK))
+	})
+
+	t.Run("token without session is rejected", func(t *testing.T) {
+		token, _ := config.GenerateJWT(2, "B6614690", entity.RoleStudent, "")
+		g.Expect(doRequest(r, "GET", "/me", token)).To(Equal(http.StatusUnauthorized))
+	})
+
+	t.Run("unknown session is not revoked", func(t *testing.T) {
+		g.Expect(services.IsSessionRevoked("never-revoked")).To(BeFalse())
+	})
+}
+
+func TestRestoreRevokedSessions(t *testing.T) {
+	g := NewGomegaWithT(t)
+	gin.SetMode(gin.TestMode)
+	now := time.Now()
+	revoked := now.Add(-time.Minute)
+
+	r := gin.New()
+	r.GET("/me", middleware.AuthMiddleware(), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	// session ที่ refresh แล้ว: token เดิมถูกเพิกถอน token ใหม่ยังใช้ได้
+	// session ที่ logout แล้ว: token ทุกตัวถูกเพิกถอน
+	services.RestoreRevokedSessions([]entity.RefreshToken{
+		{SessionID: "restart-rotated", RevokedAt: &revoked},
+		{SessionID: "restart-rotated"},
+		{SessionID: "restart-logged-out", RevokedAt: &revoked},
+		{SessionID: "restart-logged-out", RevokedAt: &revoked},
+	}, now)
+
+	t.Run("rotated session stays authorised after restart", func(t *testing.T) {
+		g.Expect(services.IsSessionRevoked("restart-rotated")).To(BeFalse())
+		token, _ := config.GenerateJWT(2, "B6614690", entity.RoleStudent, "restart-rotated")
+		g.Expect(doRequest(r, "GET", "/me", token)).To(Equal(http.StatusOK))
+	})
+
+	t.Run("logged out session stays revoked after restart", func(t *testing.T) {
+		g.Expect(services.IsSessionRevoked("restart-logged-out")).To(BeTrue())
+		token, _ := config.GenerateJWT(2, "B6614690", entity.RoleStudent, "restart-logged-out")
+		g.Expect(doRequest(r, "GET", "/me", token)).To(Equal(http.StatusUnauthorized))
+	})
+}
@@ -0,0 +1,32 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+)
+
+func TestCheckInvitationOpen(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	invitation := entity.TeamInvitation{Status: entity.InvitationStatusPending, ExpiresAt: now.Add(services.InvitationTTL)}
+
+	t.Run("pending invitation before expiry is open", func(t *testing.T) {
+		g.Expect(services.CheckInvitationOpen(invitation, now)).To(Succeed())
+	})
+
+	t.Run("invitation expires at expires_at", func(t *testing.T) {
+		g.Expect(services.CheckInvitationOpen(invitation, invitation.ExpiresAt)).To(MatchError(services.ErrInvitationExpired))
+	})
+
+	for _, status := range []string{entity.InvitationStatusAccepted, entity.InvitationStatusDeclined, entity.InvitationStatusCancelled, entity.InvitationStatusExpired} {
+		t.Run(status+" invitation is closed", func(t *testing.T) {
+			closed := invitation
+			closed.Status = status
+			g.Expect(services.CheckInvitationOpen(closed, now)).To(MatchError(services.ErrInvitationClosed))
+		})
+	}
+}
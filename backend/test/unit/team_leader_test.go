@@ -0,0 +1,38 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+)
+
+func TestPickSuccessor(t *testing.T) {
+	g := NewGomegaWithT(t)
+	joined := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	members := []entity.UserRegistration{
+		{UserID: 1, Role: entity.TeamRoleLeader, CreatedAt: joined},
+		{UserID: 7, Role: entity.TeamRoleMember, CreatedAt: joined.Add(2 * time.Hour)},
+		{UserID: 5, Role: entity.TeamRoleMember, CreatedAt: joined.Add(time.Hour)},
+		{UserID: 3, Role: entity.TeamRoleMember, CreatedAt: joined.Add(time.Hour)},
+	}
+
+	t.Run("earliest remaining member succeeds the leader", func(t *testing.T) {
+		id, ok := services.PickSuccessor(members, 1)
+		g.Expect(ok).To(BeTrue())
+		g.Expect(id).To(Equal(uint(3)))
+	})
+
+	t.Run("input order is kept", func(t *testing.T) {
+		services.PickSuccessor(members, 1)
+		g.Expect(members[0].UserID).To(Equal(uint(1)))
+		g.Expect(members).To(HaveLen(4))
+	})
+
+	t.Run("no successor when the leader was the last member", func(t *testing.T) {
+		_, ok := services.PickSuccessor(members[:1], 1)
+		g.Expect(ok).To(BeFalse())
+	})
+}
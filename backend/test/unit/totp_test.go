@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/services"
+)
+
+// secret "12345678901234567890" จาก RFC 6238 Appendix B
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, want := range vectors {
+		code, err := config.TOTPCode(rfcSecret, config.TOTPStep(time.Unix(unix, 0)))
+		g.Expect(err).To(BeNil())
+		g.Expect(code).To(Equal(want))
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := time.Unix(1234567890, 0)
+	current := config.TOTPStep(now)
+
+	t.Run("accepts current and adjacent codes", func(t *testing.T) {
+		for _, step := range []int64{current - 1, current, current + 1} {
+			code, _ := config.TOTPCode(rfcSecret, step)
+			matched, ok := config.ValidateTOTP(rfcSecret, code, now, 0)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(matched).To(Equal(step))
+		}
+	})
+
+	t.Run("rejects codes outside the window", func(t *testing.T) {
+		code, _ := config.TOTPCode(rfcSecret, current-2)
+		_, ok := config.ValidateTOTP(rfcSecret, code, now, 0)
+		g.Expect(ok).To(BeFalse())
+	})
+
+	t.Run("rejects a code that was already used", func(t *testing.T) {
+		code, _ := config.TOTPCode(rfcSecret, current)
+		_, ok := config.ValidateTOTP(rfcSecret, code, now, current)
+		g.Expect(ok).To(BeFalse())
+	})
+
+	t.Run("rejects malformed codes", func(t *testing.T) {
+		_, ok := config.ValidateTOTP(rfcSecret, "12345", now, 0)
+		g.Expect(ok).To(BeFalse())
+	})
+}
+
+func TestTOTPEnrollment(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	secret, err := config.GenerateTOTPSecret()
+	g.Expect(err).To(BeNil())
+	g.Expect(secret).To(HaveLen(32))
+
+	uri := config.TOTPProvisioningURI(secret, "A0000000", "Engi Connect")
+	g.Expect(uri).To(HavePrefix("otpauth://totp/Engi%20Connect:A0000000?"))
+	g.Expect(uri).To(ContainSubstring("secret=" + secret))
+	g.Expect(uri).To(ContainSubstring("issuer=Engi+Connect"))
+
+	codes, err := services.GenerateRecoveryCodes(services.RecoveryCodeCount)
+	g.Expect(err).To(BeNil())
+	g.Expect(codes).To(HaveLen(services.RecoveryCodeCount))
+	seen := map[string]bool{}
+	for _, code := range codes {
+		g.Expect(code).To(MatchRegexp(`^[a-z2-7]{5}-[a-z2-7]{5}$`))
+		g.Expect(seen[code]).To(BeFalse())
+		seen[code] = true
+	}
+}
+
+func TestMFARequired(t *testing.T) {
+	g := NewGomegaWithT(t)
+
+	admin := &entity.User{Role: &entity.Role{Name: entity.RoleAdmin}}
+	student := &entity.User{Role: &entity.Role{Name: entity.RoleStudent}}
+	g.Expect(services.MFARequired(admin)).To(BeTrue())
+	g.Expect(services.MFARequired(student)).To(BeFalse())
+	g.Expect(services.MFARequired(&entity.User{Role: &entity.Role{Name: entity.RoleOrganizer}})).To(BeFalse())
+	g.Expect(strings.Contains(services.ErrMFARequiredForRole.Error(), "ผู้ดูแลระบบ")).To(BeTrue())
+}
@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/gomega"
+	"github.com/sut68/team21/config"
+	"github.com/sut68/team21/controller"
+	"github.com/sut68/team21/entity"
+	"github.com/sut68/team21/middleware"
+	"github.com/sut68/team21/services"
+)
+
+func TestAuthMiddlewareDeactivatedUser(t *testing.T) {
+	g := NewGomegaWithT(t)
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.GET("/me", middleware.AuthMiddleware(), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	token, err := config.GenerateJWT(41, "B6500041", entity.RoleStudent, "deactivated-session")
+	g.Expect(err).To(BeNil())
+	defer services.SetUserDeactivated(41, false)
+
+	t.Run("active user is accepted", func(t *testing.T) {
+		g.Expect(doRequest(r, "GET", "/me", token)).To(Equal(http.StatusOK))
+	})
+
+	t.Run("deactivated user is rejected", func(t *testing.T) {
+		services.SetUserDeactivated(41, true)
+		g.Expect(doRequest(r, "GET", "/me", token)).To(Equal(http.StatusForbidden))
+	})
+
+	t.Run("impersonation token of deactivated user is rejected", func(t *testing.T) {
+		imp, err := config.GenerateImpersonationJWT(41, "B6500041", entity.RoleStudent, "imp-test", 1, services.ImpersonationTTL)
+		g.Expect(err).To(BeNil())
+		g.Expect(doRequest(r, "GET", "/me", imp)).To(Equal(http.StatusForbidden))
+	})
+
+	t.Run("reactivated user is accepted again", func(t *testing.T) {
+		services.SetUserDeactivated(41, false)
+		g.Expect(doRequest(r, "GET", "/me", token)).To(Equal(http.StatusOK))
+	})
+}
+
+func TestImpersonationToken(t *testing.T) {
+	g := NewGomegaWithT(t)
+	gin.SetMode(gin.TestMode)
+
+	token, err := config.GenerateImpersonationJWT(2, "B6614690", entity.RoleStudent, "imp-session", 1, services.ImpersonationTTL)
+	g.Expect(err).To(BeNil())
+
+	t.Run("claims record the impersonating admin", func(t *testing.T) {
+		claims, err := config.ValidateJWT(token)
+		g.Expect(err).To(BeNil())
+		g.Expect(claims.UserID).To(Equal(uint(2)))
+		g.Expect(claims.ImpersonatorID).To(Equal(uint(1)))
+		g.Expect(claims.ExpiresAt.Sub(claims.IssuedAt.Time)).To(Equal(services.ImpersonationTTL))
+	})
+
+	t.Run("normal tokens have no impersonator", func(t *testing.T) {
+		normal, _ := config.GenerateJWT(2, "B6614690", entity.RoleStudent, "normal-session")
+		claims, err := config.ValidateJWT(normal)
+		g.Expect(err).To(BeNil())
+		g.Expect(claims.ImpersonatorID).To(BeZero())
+	})
+
+	t.Run("middleware exposes impersonator and keeps target role", func(t *testing.T) {
+		r := gin.New()
+		r.GET("/admin", middleware.AuthMiddleware(), middleware.RequireRole(entity.RoleAdmin), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+		r.GET("/me", middleware.AuthMiddleware(), func(ctx *gin.Context) {
+			if _, ok := ctx.Get("impersonator_id"); !ok {
+				ctx.Status(http.StatusInternalServerError)
+				return
+			}
+			ctx.Status(http.StatusOK)
+		})
+		g.Expect(doRequest(r, "GET", "/me", token)).To(Equal(http.StatusOK))
+		g.Expect(doRequest(r, "GET", "/admin", token)).To(Equal(http.StatusForbidden))
+	})
+}
+
+func TestImpersonationCannotChangeCredentials(t *testing.T) {
+	g := NewGomegaWithT(t)
+	gin.SetMode(gin.TestMode)
+
+	apiTokens := controller.NewAPITokenController(nil)
+	mfa := controller.NewMFAController(nil)
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("user_id", uint(2))
+		ctx.Set("impersonator_id", uint(1))
+	})
+	r.POST("/api-tokens", apiTokens.Create)
+	r.POST("/2fa/setup", mfa.Setup)
+	r.POST("/2fa/enable", mfa.Enable)
+	r.POST("/2fa/disable", mfa.Disable)
+	r.POST("/2fa/recovery-codes", mfa.RegenerateRecoveryCodes)
+
+	for _, path := range []string{"/api-tokens", "/2fa/setup", "/2fa/enable", "/2fa/disable", "/2fa/recovery-codes"} {
+		g.Expect(doRequest(r, "POST", path, "")).To(Equal(http.StatusForbidden), "POST %s should be blocked while impersonating", path)
+	}
+}
@@ -1,13 +1,14 @@
 import { createContext, useContext, useState, useEffect, useMemo, useCallback } from 'react';
 import type { ReactNode } from 'react';
-import { getAuthToken, getUserFromToken, setAuthToken, logout as authLogout } from '@/utils/auth';
+import { getAuthToken, getRefreshToken, getUserFromToken, setAuthToken, logout as authLogout } from '@/utils/auth';
+import { logout as logoutService } from '@/services/authService';
 import { getMyProfile } from '@/services/profileService';
 import type { User } from '@/interfaces/user';
 
 interface AuthContextType {
   user: User | null;
   isAuthenticated: boolean;
-  login: (token: string, tokenType?: string) => void;
+  login: (token: string, tokenType?: string, refreshToken?: string) => void;
   logout: () => void;
   loading: boolean;
   refreshProfile: () => Promise<void>;
@@ -47,8 +48,8 @@ export const AuthProvider = ({ children }: { children: ReactNode }) => {
     initAuth();
   }, [fetchUserProfile]);
 
-  const login = useCallback(async (token: string, tokenType: string = "Bearer") => {
-    setAuthToken(token, tokenType);
+  const login = useCallback(async (token: string, tokenType: string = "Bearer", refreshToken?: string) => {
+    setAuthToken(token, tokenType, refreshToken);
     const tokenData = getUserFromToken();
     
     // ดึงข้อมูล profile หลัง login
@@ -58,6 +59,10 @@ export const AuthProvider = ({ children }: { children: ReactNode }) => {
   }, [fetchUserProfile]);
 
   const logout = useCallback(() => {
+    const refreshToken = getRefreshToken();
+    if (refreshToken) {
+      logoutService(refreshToken);
+    }
     authLogout();
     setUser(null);
   }, []);
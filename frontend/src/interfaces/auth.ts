@@ -26,6 +26,8 @@ export interface LoginResponse {
   message: string;
   token_type: string;
   token: string;
+  refresh_token: string;
+  expires_in: number;
 }
 
 
@@ -20,6 +20,45 @@ export interface Post {
     location_id?: number;
     comment?: string;
     post_point?: number;
+    max_teams?: number;
+    min_team_size?: number;
+    max_team_size?: number;
+    max_seats?: number;
+    // เงื่อนไขผู้มีสิทธิ์สมัคร ค่าว่างคือไม่จำกัด
+    eligible_faculty_ids?: number[] | null;
+    eligible_major_ids?: number[] | null;
+    eligible_years?: number[] | null;
+    eligible_roles?: string[] | null;
+    // true = ปฏิเสธผู้สมัครที่มีกิจกรรมอื่นเวลาซ้อน, false = แค่เตือน
+    block_schedule_conflicts?: boolean;
+    // ถอนการสมัครภายใน withdrawal_cutoff_hours ชั่วโมงก่อนปิดรับสมัคร (หรือหลังปิด) ถูกหัก late_withdrawal_penalty คะแนน
+    withdrawal_cutoff_hours?: number;
+    late_withdrawal_penalty?: number;
+    capacity?: PostCapacity;
+    registration_count?: number;
+    registration_open?: boolean;
+}
+
+// remaining_* เป็น null เมื่อกิจกรรมไม่จำกัดจำนวน
+export interface PostCapacity {
+    teams: number;
+    seats: number;
+    remaining_teams: number | null;
+    remaining_seats: number | null;
+}
+
+export interface PostListQuery {
+    q?: string;
+    type?: string;
+    status_id?: number;
+    location_id?: number;
+    organizer?: string;
+    from?: string;
+    to?: string;
+    open?: boolean;
+    sort?: string;
+    page?: number;
+    limit?: number;
 }
 
 export interface CreatePostRequest {
@@ -36,6 +75,20 @@ export interface CreatePostRequest {
     user_id: number;
     status_id: number;
     location_id?: number;
+    max_teams?: number;
+    min_team_size?: number;
+    max_team_size?: number;
+    max_seats?: number;
+    // เงื่อนไขผู้มีสิทธิ์สมัคร ค่าว่างคือไม่จำกัด
+    eligible_faculty_ids?: number[] | null;
+    eligible_major_ids?: number[] | null;
+    eligible_years?: number[] | null;
+    eligible_roles?: string[] | null;
+    // true = ปฏิเสธผู้สมัครที่มีกิจกรรมอื่นเวลาซ้อน, false = แค่เตือน
+    block_schedule_conflicts?: boolean;
+    // ถอนการสมัครภายใน withdrawal_cutoff_hours ชั่วโมงก่อนปิดรับสมัคร (หรือหลังปิด) ถูกหัก late_withdrawal_penalty คะแนน
+    withdrawal_cutoff_hours?: number;
+    late_withdrawal_penalty?: number;
 }
 
 export interface UpdatePostRequest {
@@ -55,6 +108,20 @@ export interface UpdatePostRequest {
     location_id?: number;
     comment?: string;
     post_point?: number;
+    max_teams?: number;
+    min_team_size?: number;
+    max_team_size?: number;
+    max_seats?: number;
+    // เงื่อนไขผู้มีสิทธิ์สมัคร ค่าว่างคือไม่จำกัด
+    eligible_faculty_ids?: number[] | null;
+    eligible_major_ids?: number[] | null;
+    eligible_years?: number[] | null;
+    eligible_roles?: string[] | null;
+    // true = ปฏิเสธผู้สมัครที่มีกิจกรรมอื่นเวลาซ้อน, false = แค่เตือน
+    block_schedule_conflicts?: boolean;
+    // ถอนการสมัครภายใน withdrawal_cutoff_hours ชั่วโมงก่อนปิดรับสมัคร (หรือหลังปิด) ถูกหัก late_withdrawal_penalty คะแนน
+    withdrawal_cutoff_hours?: number;
+    late_withdrawal_penalty?: number;
 }
 
 export interface DeletePostRequest {
@@ -6,7 +6,15 @@ export type UserWithID = User & {
   ID?: number;
 };
 
-export type RegistrationStatus = "pending" | "approved" | "rejected" | "completed";
+export type RegistrationStatus =
+  | "pending"
+  | "approved"
+  | "rejected"
+  | "completed"
+  | "waitlisted"
+  | "offered"
+  | "expired"
+  | "withdrawn";
 
 export interface RegistrationInterface {
   
@@ -17,6 +25,16 @@ export interface RegistrationInterface {
   description: string;
   status: RegistrationStatus;
   registration_date: string; 
+  offer_expires_at?: string | null;
+  withdrawal_reason?: string;
+  withdrawn_at?: string | null;
+  // คะแนนที่หักจากสมาชิกแต่ละคนเพราะถอนหลังกำหนด
+  withdrawal_penalty?: number;
+  waitlist_position?: number;
+  // หัวหน้าทีม แก้ไขทีม จัดการสมาชิก และถอนการสมัครได้
+  leader_id?: number | null;
+  // คำเตือนตอนสมัคร เช่น สมาชิกมีกิจกรรมอื่นที่เวลาซ้อน
+  warnings?: string[];
 
   post_id: number;
 
@@ -26,6 +44,12 @@ export interface RegistrationInterface {
   
   users?: UserWithID[];
 
+  // คำตอบแบบฟอร์มสมัคร (เห็นเฉพาะสมาชิกทีมและผู้จัดกิจกรรม)
+  answers?: RegistrationAnswer[];
+
+  // ประวัติการเปลี่ยนสถานะ (มีเฉพาะ GET /registration/:id)
+  status_history?: RegistrationStatusHistory[];
+
   CreatedAt?: string;
   UpdatedAt?: string;
   DeletedAt?: string | null;
@@ -36,11 +60,29 @@ export interface RegistrationInterface {
 }
 
 
+// ประวัติการเปลี่ยนสถานะของทีม actor เป็น null เมื่อระบบเปลี่ยนเอง
+export interface RegistrationStatusHistory {
+  id: number;
+  created_at: string;
+  registration_id: number;
+  from_status: RegistrationStatus | "";
+  to_status: RegistrationStatus;
+  reason: string;
+  actor_id: number | null;
+  actor?: {
+    ID: number;
+    sut_id: string;
+    first_name: string;
+    last_name: string;
+  };
+}
+
 export interface CreateRegistrationRequest {
   team_name: string;
   description: string;
   post_id: number;
   user_ids: number[];  
+  answers?: RegistrationAnswerInput[];
 
   
   status?: RegistrationStatus; 
@@ -51,6 +93,54 @@ export interface UpdateRegistrationRequest {
   team_name?: string;
   description?: string;
   status?: RegistrationStatus;
+  answers?: RegistrationAnswerInput[];
+}
+
+export type FormFieldType = "text" | "number" | "choice" | "multi_choice" | "file";
+
+// คำถามในแบบฟอร์มสมัครของกิจกรรม options ใช้กับ choice/multi_choice
+export interface RegistrationFormField {
+  ID?: number;
+  id?: number;
+  post_id?: number;
+  label: string;
+  type: FormFieldType;
+  required: boolean;
+  options?: string[] | null;
+  position?: number;
+}
+
+// value ที่บันทึก: multi_choice เป็น JSON array, file เป็น path ใน upload/registration/
+export interface RegistrationAnswer {
+  ID?: number;
+  registration_id: number;
+  field_id: number;
+  field?: RegistrationFormField;
+  value: string;
+}
+
+// number ส่งเป็นตัวเลข multi_choice ส่งเป็น array ที่เหลือส่งเป็น string
+export interface RegistrationAnswerInput {
+  field_id: number;
+  value: string | number | string[] | null;
+}
+
+export type InvitationStatus = "pending" | "accepted" | "declined" | "expired" | "cancelled";
+
+// คำเชิญเข้าทีม ผู้ได้รับเชิญเป็นสมาชิกเมื่อตอบรับแล้วเท่านั้น
+export interface TeamInvitation {
+  ID: number;
+  registration_id: number;
+  registration?: RegistrationInterface;
+  invitee_id: number;
+  invitee?: UserWithID;
+  inviter_id: number;
+  inviter?: UserWithID;
+  status: InvitationStatus;
+  expires_at: string;
+  responded_at?: string | null;
+  warnings?: string[];
+  CreatedAt?: string;
 }
 
 export interface UpdateRegistrationStatusRequest {
@@ -82,4 +172,20 @@ export interface RegistrationListResponse {
 export interface AddUserPayload {
   user_id?: number;
   sut_id?: string;
-}
\ No newline at end of file
+}
+
+// รายงานการถอนการสมัครของกิจกรรมสำหรับผู้จัด
+export interface WithdrawalReport {
+  post_id: number;
+  total: number;
+  late: number;
+  penalty_points: number;
+  withdrawals: {
+    registration_id: number;
+    team_name: string;
+    members: string[];
+    withdrawn_at: string;
+    reason: string;
+    penalty: number;
+  }[];
+}
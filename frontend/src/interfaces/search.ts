@@ -0,0 +1,19 @@
+export type SearchType = "posts" | "users" | "portfolios";
+
+export interface SearchHit {
+    id: number;
+    title: string;
+    subtitle: string;
+    rank: number;
+}
+
+export interface SearchGroup {
+    type: SearchType;
+    total: number;
+    items: SearchHit[];
+}
+
+export interface SearchResponse {
+    query: string;
+    groups: SearchGroup[];
+}
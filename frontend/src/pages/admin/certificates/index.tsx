@@ -2,7 +2,7 @@ import React, { useState, useEffect } from 'react';
 import { Layout, message } from 'antd';
 
 // --- Services & Interfaces ---
-import { GetAllPosts } from '../../../services/postServices';
+import { GetAllPostPages } from '../../../services/postServices';
 import type { Post } from "../../../interfaces/post";
 import {
     GetMyCertificate,
@@ -55,7 +55,7 @@ const CertificateApp: React.FC = () => {
     const fetchPosts = async () => {
         setLoading(true);
         try {
-            const res = await GetAllPosts();
+            const res = await GetAllPostPages();
             if (res && res.status === 200) {
                 let rawPosts: Post[] = [];
                 if (Array.isArray(res.data)) rawPosts = res.data;
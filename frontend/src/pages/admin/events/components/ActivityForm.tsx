@@ -8,16 +8,21 @@ import {
   Space,
   Form,
   Input,
+  InputNumber,
   Upload,
   Select,
   DatePicker,
   ConfigProvider,
+  Checkbox,
 } from "antd";
 import { ArrowLeftOutlined, UploadOutlined } from "@ant-design/icons";
 import type { UploadFile } from "antd/es/upload/interface";
 import type { LocationInterface } from "../../../../interfaces/Location";
+import type { Faculty } from "../../../../interfaces/faculty";
+import type { Major } from "../../../../interfaces/major";
 import type { Post } from "../../../../interfaces/post";
 import enUS from "antd/es/date-picker/locale/en_US";
+import { RegistrationFormBuilder } from "./RegistrationFormBuilder";
 
 const { Title } = Typography;
 const { TextArea } = Input;
@@ -36,6 +41,8 @@ interface ActivityFormProps {
   editingPost: Post | null;
   fileList: UploadFile[];
   locations: LocationInterface[];
+  faculties: Faculty[];
+  majors: Major[];
   onFileChange: ({ fileList }: { fileList: UploadFile[] }) => void;
   onFormSubmit: (values: any) => void;
   onClose: () => void;
@@ -47,6 +54,8 @@ export const ActivityForm: React.FC<ActivityFormProps> = ({
   editingPost,
   fileList,
   locations,
+  faculties,
+  majors,
   onFileChange,
   onFormSubmit,
   onClose,
@@ -347,6 +356,116 @@ export const ActivityForm: React.FC<ActivityFormProps> = ({
               />
             </Form.Item>
 
+            <Row gutter={24}>
+              <Col span={6}>
+                <Form.Item name="max_teams" label="จำนวนทีมสูงสุด" tooltip="เว้นว่างหากไม่จำกัด">
+                  <InputNumber min={1} size="large" style={{ width: "100%", borderRadius: "8px" }} placeholder="ไม่จำกัด" />
+                </Form.Item>
+              </Col>
+              <Col span={6}>
+                <Form.Item name="min_team_size" label="สมาชิกต่อทีมขั้นต่ำ">
+                  <InputNumber min={1} size="large" style={{ width: "100%", borderRadius: "8px" }} placeholder="ไม่กำหนด" />
+                </Form.Item>
+              </Col>
+              <Col span={6}>
+                <Form.Item
+                  name="max_team_size"
+                  label="สมาชิกต่อทีมสูงสุด"
+                  dependencies={["min_team_size"]}
+                  rules={[
+                    ({ getFieldValue }) => ({
+                      validator(_, value) {
+                        const min = getFieldValue("min_team_size");
+                        if (!value || !min || value >= min) return Promise.resolve();
+                        return Promise.reject(new Error("ต้องไม่น้อยกว่าจำนวนขั้นต่ำ"));
+                      },
+                    }),
+                  ]}
+                >
+                  <InputNumber min={1} size="large" style={{ width: "100%", borderRadius: "8px" }} placeholder="ไม่จำกัด" />
+                </Form.Item>
+              </Col>
+              <Col span={6}>
+                <Form.Item name="max_seats" label="ที่นั่งทั้งหมด" tooltip="จำนวนผู้เข้าร่วมรวมทุกทีม เว้นว่างหากไม่จำกัด">
+                  <InputNumber min={1} size="large" style={{ width: "100%", borderRadius: "8px" }} placeholder="ไม่จำกัด" />
+                </Form.Item>
+              </Col>
+            </Row>
+
+            <Row gutter={24}>
+              <Col span={6}>
+                <Form.Item name="eligible_faculty_ids" label="คณะที่สมัครได้" tooltip="เว้นว่างหากไม่จำกัด">
+                  <Select
+                    mode="multiple"
+                    size="large"
+                    allowClear
+                    placeholder="ทุกคณะ"
+                    optionFilterProp="label"
+                    options={faculties.map((f) => ({ value: f.ID, label: f.name }))}
+                  />
+                </Form.Item>
+              </Col>
+              <Col span={6}>
+                <Form.Item name="eligible_major_ids" label="สาขาที่สมัครได้" tooltip="เว้นว่างหากไม่จำกัด">
+                  <Select
+                    mode="multiple"
+                    size="large"
+                    allowClear
+                    placeholder="ทุกสาขา"
+                    optionFilterProp="label"
+                    options={majors.map((m) => ({ value: m.ID, label: m.name }))}
+                  />
+                </Form.Item>
+              </Col>
+              <Col span={6}>
+                <Form.Item name="eligible_years" label="ชั้นปีที่สมัครได้" tooltip="เว้นว่างหากไม่จำกัด">
+                  <Select
+                    mode="multiple"
+                    size="large"
+                    allowClear
+                    placeholder="ทุกชั้นปี"
+                    options={[1, 2, 3, 4, 5, 6, 7, 8].map((y) => ({ value: y, label: `ปี ${y}` }))}
+                  />
+                </Form.Item>
+              </Col>
+              <Col span={6}>
+                <Form.Item name="eligible_roles" label="บทบาทที่สมัครได้" tooltip="เว้นว่างหากไม่จำกัด">
+                  <Select
+                    mode="multiple"
+                    size="large"
+                    allowClear
+                    placeholder="ทุกบทบาท"
+                    options={[
+                      { value: "student", label: "นักศึกษา" },
+                      { value: "organizer", label: "ผู้จัดกิจกรรม" },
+                      { value: "admin", label: "ผู้ดูแลระบบ" },
+                    ]}
+                  />
+                </Form.Item>
+              </Col>
+            </Row>
+
+            <Form.Item
+              name="block_schedule_conflicts"
+              valuePropName="checked"
+              tooltip="ถ้าไม่เลือก ผู้สมัครที่มีกิจกรรมอื่นในช่วงวันจัดงานเดียวกันจะได้รับคำเตือนเท่านั้น"
+            >
+              <Checkbox>ไม่รับสมัครผู้ที่ลงทะเบียนกิจกรรมอื่นที่จัดในเวลาซ้อนกัน</Checkbox>
+            </Form.Item>
+
+            <Row gutter={24}>
+              <Col span={6}>
+                <Form.Item name="withdrawal_cutoff_hours" label="ถอนช้าภายใน (ชั่วโมงก่อนปิดรับสมัคร)" tooltip="ถอนการสมัครในช่วงนี้หรือหลังปิดรับสมัครถูกหักคะแนน เว้นว่างหากไม่หักคะแนน">
+                  <InputNumber min={1} size="large" style={{ width: "100%", borderRadius: "8px" }} placeholder="ไม่กำหนด" />
+                </Form.Item>
+              </Col>
+              <Col span={6}>
+                <Form.Item name="late_withdrawal_penalty" label="คะแนนที่หักเมื่อถอนช้า" tooltip="หักจากสมาชิกทุกคนของทีมที่ได้ที่นั่งแล้ว">
+                  <InputNumber min={1} size="large" style={{ width: "100%", borderRadius: "8px" }} placeholder="ไม่หัก" />
+                </Form.Item>
+              </Col>
+            </Row>
+
             <Form.Item label="อัปโหลดรูปภาพ">
               <Upload
                 listType="picture-card"
@@ -393,6 +512,7 @@ export const ActivityForm: React.FC<ActivityFormProps> = ({
           </Form>
         </ConfigProvider>
       </Card>
+      {isEdit && editingPost?.ID && <RegistrationFormBuilder postId={editingPost.ID} />}
     </Layout>
   );
 };
@@ -0,0 +1,119 @@
+import { useEffect, useState } from "react";
+import { Button, Card, Checkbox, Col, Input, Row, Select, Space, Typography, message } from "antd";
+import { DeleteOutlined, PlusOutlined } from "@ant-design/icons";
+import type { FormFieldType, RegistrationFormField } from "../../../../interfaces/registration";
+import { GetRegistrationForm, SaveRegistrationForm } from "../../../../services/registrationService";
+
+const { Title, Text } = Typography;
+
+const fieldTypeOptions: { value: FormFieldType; label: string }[] = [
+  { value: "text", label: "ข้อความ" },
+  { value: "number", label: "ตัวเลข" },
+  { value: "choice", label: "เลือกหนึ่งข้อ" },
+  { value: "multi_choice", label: "เลือกได้หลายข้อ" },
+  { value: "file", label: "แนบไฟล์" },
+];
+
+const hasOptions = (type: FormFieldType) => type === "choice" || type === "multi_choice";
+
+interface RegistrationFormBuilderProps {
+  postId: number;
+}
+
+// RegistrationFormBuilder กำหนดคำถามเพิ่มเติมที่ผู้สมัครต้องตอบ (ไซซ์เสื้อ อาหารที่แพ้ ลิงก์ผลงาน ฯลฯ)
+export const RegistrationFormBuilder: React.FC<RegistrationFormBuilderProps> = ({ postId }) => {
+  const [fields, setFields] = useState<RegistrationFormField[]>([]);
+  const [saving, setSaving] = useState(false);
+
+  useEffect(() => {
+    GetRegistrationForm(postId).then((res) => {
+      if (res?.status === 200) setFields(res.data?.data ?? []);
+    });
+  }, [postId]);
+
+  const updateField = (index: number, patch: Partial<RegistrationFormField>) => {
+    setFields((prev) => prev.map((f, i) => (i === index ? { ...f, ...patch } : f)));
+  };
+
+  const removeField = (index: number) => {
+    setFields((prev) => prev.filter((_, i) => i !== index));
+  };
+
+  const addField = () => {
+    setFields((prev) => [...prev, { label: "", type: "text", required: false, options: [] }]);
+  };
+
+  const handleSave = async () => {
+    setSaving(true);
+    const res = await SaveRegistrationForm(postId, fields);
+    setSaving(false);
+    if (res?.status === 200) {
+      setFields(res.data?.data ?? []);
+      message.success("บันทึกแบบฟอร์มสมัครสำเร็จ");
+    } else {
+      message.error(res?.data?.error || "บันทึกแบบฟอร์มสมัครไม่สำเร็จ");
+    }
+  };
+
+  return (
+    <Card style={{ borderRadius: "16px", marginTop: "24px" }} bodyStyle={{ padding: "32px" }}>
+      <Title level={4} style={{ marginBottom: 4 }}>
+        แบบฟอร์มสมัคร
+      </Title>
+      <Text type="secondary">คำถามเพิ่มเติมที่ทีมต้องตอบตอนสมัคร การลบคำถามไม่ลบคำตอบที่ส่งมาแล้ว</Text>
+
+      <Space direction="vertical" style={{ width: "100%", marginTop: "24px" }} size="middle">
+        {fields.map((field, index) => (
+          <Row key={field.ID ?? `new-${index}`} gutter={12} align="middle">
+            <Col span={8}>
+              <Input
+                placeholder="คำถาม"
+                value={field.label}
+                onChange={(e) => updateField(index, { label: e.target.value })}
+              />
+            </Col>
+            <Col span={5}>
+              <Select
+                style={{ width: "100%" }}
+                value={field.type}
+                options={fieldTypeOptions}
+                onChange={(type) => updateField(index, { type })}
+              />
+            </Col>
+            <Col span={7}>
+              {hasOptions(field.type) && (
+                <Select
+                  mode="tags"
+                  style={{ width: "100%" }}
+                  placeholder="พิมพ์ตัวเลือกแล้วกด Enter"
+                  value={field.options ?? []}
+                  onChange={(options) => updateField(index, { options })}
+                />
+              )}
+            </Col>
+            <Col span={3}>
+              <Checkbox
+                checked={field.required}
+                onChange={(e) => updateField(index, { required: e.target.checked })}
+              >
+                บังคับตอบ
+              </Checkbox>
+            </Col>
+            <Col span={1}>
+              <Button type="text" danger icon={<DeleteOutlined />} onClick={() => removeField(index)} />
+            </Col>
+          </Row>
+        ))}
+
+        <Space>
+          <Button icon={<PlusOutlined />} onClick={addField}>
+            เพิ่มคำถาม
+          </Button>
+          <Button type="primary" loading={saving} onClick={handleSave} style={{ background: "#000" }}>
+            บันทึกแบบฟอร์ม
+          </Button>
+        </Space>
+      </Space>
+    </Card>
+  );
+};
@@ -30,13 +30,17 @@ import type { UploadFile } from "antd/es/upload/interface";
 // Services
 import {
   CreatePost,
-  GetAllPosts,
+  GetAllPostPages,
   UpdatePost,
   DeletePost,
+  ApprovePost,
+  RejectPost,
   convertFileToBase64,
 } from "../../../services/postServices";
-import { getLocations } from "../../../services/metadataService";
+import { getFaculties, getLocations, getMajors } from "../../../services/metadataService";
 import type { LocationInterface } from "../../../interfaces/Location";
+import type { Faculty } from "../../../interfaces/faculty";
+import type { Major } from "../../../interfaces/major";
 import type {
   Post,
   CreatePostRequest,
@@ -77,6 +81,8 @@ const ActivityManager: React.FC = () => {
 
   // Locations
   const [locations, setLocations] = useState<LocationInterface[]>([]);
+  const [faculties, setFaculties] = useState<Faculty[]>([]);
+  const [majors, setMajors] = useState<Major[]>([]);
 
   // --- Modals ---
   const [isRejectModalOpen, setIsRejectModalOpen] = useState<boolean>(false);
@@ -92,7 +98,7 @@ const ActivityManager: React.FC = () => {
   const fetchPosts = async () => {
     setLoading(true);
     try {
-      const res = await GetAllPosts();
+      const res = await GetAllPostPages();
       if (res?.status === 200) {
         let postsData: Post[] = [];
         if (Array.isArray(res.data)) postsData = res.data;
@@ -133,6 +139,9 @@ const ActivityManager: React.FC = () => {
       if (Array.isArray(data)) {
         setLocations(data);
       }
+      const [facultyData, majorData] = await Promise.all([getFaculties(), getMajors()]);
+      if (Array.isArray(facultyData)) setFaculties(facultyData);
+      if (Array.isArray(majorData)) setMajors(majorData);
     } catch (error) {
       console.error("Failed to fetch locations:", error);
     }
@@ -201,6 +210,17 @@ const ActivityManager: React.FC = () => {
         type: values.type,
         picture: base64Image || "",
         location_id: values.location_id,
+        max_teams: values.max_teams || 0,
+        min_team_size: values.min_team_size || 0,
+        max_team_size: values.max_team_size || 0,
+        max_seats: values.max_seats || 0,
+        eligible_faculty_ids: values.eligible_faculty_ids || [],
+        eligible_major_ids: values.eligible_major_ids || [],
+        eligible_years: values.eligible_years || [],
+        eligible_roles: values.eligible_roles || [],
+        block_schedule_conflicts: !!values.block_schedule_conflicts,
+        withdrawal_cutoff_hours: values.withdrawal_cutoff_hours || 0,
+        late_withdrawal_penalty: values.late_withdrawal_penalty || 0,
       };
 
       let res;
@@ -265,33 +285,18 @@ const ActivityManager: React.FC = () => {
     action: "approve" | "reject",
     comment?: string
   ) => {
-    const newStatusId = action === "approve" ? 2 : 3;
-    const payload: UpdatePostRequest = {
-      ID: post.ID,
-      title: post.title,
-      detail: post.detail,
-      start_date: post.start_date,
-      stop_date: post.stop_date,
-      start: post.start,
-      stop: post.stop,
-      organizer: post.organizer || "ไม่ระบุ",
-      type: post.type,
-      picture: post.picture,
-      location_id: post.location_id,
-      status_id: newStatusId,
-      user_id: post.user_id,
-      comment: action === "reject" ? comment || "" : "",
-    };
-
     try {
-      const res = await UpdatePost(post.ID, payload);
+      const res =
+        action === "approve"
+          ? await ApprovePost(post.ID, comment)
+          : await RejectPost(post.ID, comment || "");
       if (res?.status === 200) {
         message.success(
           action === "approve" ? "อนุมัติเรียบร้อย" : "ไม่อนุมัติเรียบร้อย"
         );
         await fetchPosts();
       } else {
-        message.error("เกิดข้อผิดพลาด");
+        message.error(res?.data?.error || "เกิดข้อผิดพลาด");
       }
     } catch {
       message.error("เชื่อมต่อไม่ได้");
@@ -330,6 +335,17 @@ const ActivityManager: React.FC = () => {
       organizer: (post as any).organizer,
       type: (post as any).type,
       location_id: (post as any).location_id,
+      max_teams: post.max_teams || undefined,
+      min_team_size: post.min_team_size || undefined,
+      max_team_size: post.max_team_size || undefined,
+      max_seats: post.max_seats || undefined,
+      eligible_faculty_ids: post.eligible_faculty_ids || [],
+      eligible_major_ids: post.eligible_major_ids || [],
+      eligible_years: post.eligible_years || [],
+      eligible_roles: post.eligible_roles || [],
+      block_schedule_conflicts: !!post.block_schedule_conflicts,
+      withdrawal_cutoff_hours: post.withdrawal_cutoff_hours || undefined,
+      late_withdrawal_penalty: post.late_withdrawal_penalty || undefined,
     });
 
     if ((post as any).picture) {
@@ -662,6 +678,8 @@ const ActivityManager: React.FC = () => {
         editingPost={editingPost}
         fileList={fileList}
         locations={locations}
+        faculties={faculties}
+        majors={majors}
         onFileChange={onFileChange}
         onFormSubmit={handleFormSubmit}
         onClose={handleCloseCreate}
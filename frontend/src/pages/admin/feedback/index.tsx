@@ -8,7 +8,7 @@ import {
 } from "@/components/ui/select";
 import { Tabs, TabsContent, TabsList, TabsTrigger } from "@/components/ui/tabs";
 import { ClipboardList, Pencil, BarChart3 } from "lucide-react";
-import { GetAllPosts } from "@/services/postServices";
+import { GetAllPostPages } from "@/services/postServices";
 import {
   GetTopicsByPost,
   GetEvaluationResults,
@@ -36,7 +36,7 @@ export default function AdminFeedbackPage() {
   const [loadingResults, setLoadingResults] = useState(false);
 
   const fetchPosts = async () => {
-    const res = await GetAllPosts();
+    const res = await GetAllPostPages();
     if (res?.status === 200) setPosts(res.data?.data || []);
   };
 
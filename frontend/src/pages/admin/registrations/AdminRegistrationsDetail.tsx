@@ -29,12 +29,14 @@ import {
   AlertTriangle,
   FileText,
   Activity,
+  History,
 } from "lucide-react";
 import {
   GetRegistrationById,
   UpdateRegistrationStatus,
 } from "@/services/registrationService";
 import { GetPostById } from "@/services/postServices";
+import type { RegistrationAnswer, RegistrationStatusHistory } from "@/interfaces/registration";
 import { cn } from "@/lib/utils";
 import { toast } from "react-toastify";
 
@@ -60,6 +62,30 @@ const statusConfig: Record<
     color: "text-red-700",
     bgColor: "bg-red-50",
   },
+  waitlisted: {
+    label: "คิวสำรอง",
+    icon: Clock3,
+    color: "text-slate-700",
+    bgColor: "bg-slate-50",
+  },
+  offered: {
+    label: "รอยืนยันที่นั่ง",
+    icon: Clock3,
+    color: "text-blue-700",
+    bgColor: "bg-blue-50",
+  },
+  expired: {
+    label: "หมดเวลายืนยัน",
+    icon: XCircle,
+    color: "text-gray-700",
+    bgColor: "bg-gray-50",
+  },
+  withdrawn: {
+    label: "ถอนการสมัคร",
+    icon: XCircle,
+    color: "text-gray-700",
+    bgColor: "bg-gray-50",
+  },
 };
 
 export default function AdminRegistrationDetail() {
@@ -230,6 +256,19 @@ export default function AdminRegistrationDetail() {
   const teamName = toText(reg.team_name ?? reg.TeamName, "-");
   const desc = toText(reg.description ?? reg.Description, "");
   const users: any[] = Array.isArray(reg.users ?? reg.Users) ? reg.users ?? reg.Users : [];
+  const answers: RegistrationAnswer[] = Array.isArray(reg.answers) ? reg.answers : [];
+
+  // multi_choice เก็บเป็น JSON array ส่วน file เป็น path ของไฟล์ที่อัปโหลด
+  const answerText = (a: RegistrationAnswer) => {
+    if (a.field?.type === "multi_choice") {
+      try {
+        return (JSON.parse(a.value) as string[]).join(", ");
+      } catch {
+        return a.value;
+      }
+    }
+    return a.value;
+  };
 
   const rawStatus = toText(reg?.status ?? reg?.Status, "pending");
   const status = rawStatus.trim().toLowerCase();
@@ -238,6 +277,9 @@ export default function AdminRegistrationDetail() {
 
   const canEdit = status === "pending";
   const rejectionReasonFromReg = toText(reg.rejection_reason ?? reg.RejectionReason, "");
+  const withdrawalReason = toText(reg.withdrawal_reason, "");
+  const withdrawalPenalty = Number(reg.withdrawal_penalty ?? 0);
+  const statusHistory: RegistrationStatusHistory[] = reg.status_history ?? [];
 
   const postTitle = toText(post?.title ?? post?.Title, "ชื่อกิจกรรม");
   const startDate = post?.start_date ?? post?.StartDate;
@@ -377,6 +419,27 @@ export default function AdminRegistrationDetail() {
               </div>
             )}
 
+            {status === "withdrawn" && withdrawalReason && (
+              <div className="mt-4 p-4 bg-white rounded-lg border-2 border-gray-300 shadow-sm">
+                <div className="flex items-start gap-2">
+                  <AlertTriangle className="size-5 text-gray-600 flex-shrink-0 mt-0.5" />
+                  <div className="flex-1 min-w-0">
+                    <p className="text-xs font-bold text-gray-900 mb-1">
+                      เหตุผลที่ถอนการสมัคร:
+                    </p>
+                    <p className="text-sm text-gray-800 leading-relaxed">
+                      {withdrawalReason}
+                    </p>
+                    {withdrawalPenalty > 0 && (
+                      <p className="text-xs text-red-700 mt-2">
+                        ถอนหลังกำหนด สมาชิกแต่ละคนถูกหัก {withdrawalPenalty} คะแนน
+                      </p>
+                    )}
+                  </div>
+                </div>
+              </div>
+            )}
+
             {/* แสดงข้อความเมื่อไม่สามารถเปลี่ยนได้ */}
 
           </div>
@@ -413,6 +476,52 @@ export default function AdminRegistrationDetail() {
           </CardContent>
         </Card>
 
+        {/* Status History Card */}
+        {statusHistory.length > 0 && (
+          <Card>
+            <CardHeader>
+              <CardTitle className="flex items-center gap-2">
+                <History className="size-5 text-blue-900" />
+                ประวัติสถานะ
+              </CardTitle>
+            </CardHeader>
+
+            <CardContent>
+              <ol className="space-y-3">
+                {statusHistory.map((change) => (
+                  <li
+                    key={change.id}
+                    className="p-4 rounded-lg bg-slate-50 border border-slate-200"
+                  >
+                    <div className="flex flex-wrap items-center justify-between gap-2">
+                      <div className="font-semibold text-sm">
+                        {change.from_status
+                          ? `${statusConfig[change.from_status]?.label ?? change.from_status} → `
+                          : ""}
+                        {statusConfig[change.to_status]?.label ?? change.to_status}
+                      </div>
+                      <div className="text-xs text-slate-500">
+                        {new Date(change.created_at).toLocaleString("th-TH")}
+                      </div>
+                    </div>
+                    <div className="text-xs text-slate-600 mt-1">
+                      โดย{" "}
+                      {change.actor
+                        ? `${change.actor.first_name} ${change.actor.last_name}`
+                        : "ระบบ"}
+                    </div>
+                    {!!change.reason && (
+                      <div className="text-sm text-slate-700 mt-2">
+                        เหตุผล: {change.reason}
+                      </div>
+                    )}
+                  </li>
+                ))}
+              </ol>
+            </CardContent>
+          </Card>
+        )}
+
         {/* Team Members Card */}
         <Card>
           <CardHeader>
@@ -502,6 +611,39 @@ export default function AdminRegistrationDetail() {
           </CardContent>
         </Card>
 
+        {/* Registration Form Answers */}
+        {answers.length > 0 && (
+          <Card className="border-slate-200">
+            <CardHeader>
+              <CardTitle className="flex items-center gap-2">
+                <FileText className="size-5" />
+                คำตอบแบบฟอร์มสมัคร
+              </CardTitle>
+            </CardHeader>
+            <CardContent className="space-y-3 text-sm">
+              {answers.map((a) => (
+                <div key={a.field_id} className="grid grid-cols-3 gap-4">
+                  <div className="font-medium text-slate-700">{a.field?.label ?? `คำถาม #${a.field_id}`}</div>
+                  <div className="col-span-2 text-slate-900 break-words">
+                    {a.field?.type === "file" ? (
+                      <a
+                        href={`${import.meta.env.VITE_API_URL ?? ""}/${a.value}`}
+                        target="_blank"
+                        rel="noreferrer"
+                        className="text-indigo-600 underline"
+                      >
+                        เปิดไฟล์
+                      </a>
+                    ) : (
+                      answerText(a)
+                    )}
+                  </div>
+                </div>
+              ))}
+            </CardContent>
+          </Card>
+        )}
+
         {/* Admin Actions */}
         {canEdit && (
           <Card className="border-slate-200 bg-slate-50/30">
@@ -26,13 +26,15 @@ import {
   FileText,
   AlertCircle,
   Trash2, 
+  Download,
 } from "lucide-react";
  
-import { GetAllPosts } from "@/services/postServices"; 
+import { GetAllPostPages } from "@/services/postServices"; 
 import {
   GetRegistrationsByPostId,
   UpdateRegistrationStatus,
   DeleteRegistration, 
+  ExportRegistrations,
 } from "@/services/registrationService";
 import { cn } from "@/lib/utils";
 import { toast } from "react-toastify";
@@ -56,6 +58,30 @@ const statusConfig = {
     icon: XCircle,
     badgeColor: "bg-red-500",
   },
+  waitlisted: {
+    label: "คิวสำรอง",
+    color: "bg-slate-100 text-slate-800 border-slate-200",
+    icon: Clock,
+    badgeColor: "bg-slate-500",
+  },
+  offered: {
+    label: "รอยืนยันที่นั่ง",
+    color: "bg-blue-100 text-blue-800 border-blue-200",
+    icon: Clock,
+    badgeColor: "bg-blue-500",
+  },
+  expired: {
+    label: "หมดเวลายืนยัน",
+    color: "bg-gray-100 text-gray-700 border-gray-200",
+    icon: XCircle,
+    badgeColor: "bg-gray-500",
+  },
+  withdrawn: {
+    label: "ถอนการสมัคร",
+    color: "bg-gray-100 text-gray-700 border-gray-200",
+    icon: XCircle,
+    badgeColor: "bg-gray-500",
+  },
 };
 
 type StatusFilter = "all" | "pending" | "approved" | "rejected";
@@ -85,7 +111,7 @@ export default function AdminRegistrationsPage() {
   const [isDeleting, setIsDeleting] = React.useState(false);
 
   const loadPosts = async () => {
-    const res = await GetAllPosts();
+    const res = await GetAllPostPages();
     if (res?.status === 200) {
       const list = Array.isArray(res.data) ? res.data : res.data?.data ?? [];
       setPosts(list);
@@ -198,6 +224,22 @@ export default function AdminRegistrationsPage() {
   };
 
   
+  // รายชื่อทีมพร้อมคำตอบแบบฟอร์มสมัคร
+  const handleExport = async () => {
+    if (!postId) return;
+    const blob = await ExportRegistrations(postId);
+    if (!blob) {
+      toast.error("ส่งออกรายชื่อผู้สมัครไม่สำเร็จ");
+      return;
+    }
+    const url = URL.createObjectURL(blob);
+    const link = document.createElement("a");
+    link.href = url;
+    link.download = `registrations-post-${postId}.csv`;
+    link.click();
+    URL.revokeObjectURL(url);
+  };
+
   const filtered = React.useMemo(() => {
     const query = q.trim().toLowerCase();
 
@@ -356,9 +398,17 @@ export default function AdminRegistrationsPage() {
                 <Users className="size-5 text-slate-900" />
                 <CardTitle>ทีมที่สมัคร</CardTitle>
               </div>
-              {filtered.length > 0 && (
-                <Badge variant="secondary">{filtered.length} ทีม</Badge>
-              )}
+              <div className="flex items-center gap-2">
+                {filtered.length > 0 && (
+                  <Badge variant="secondary">{filtered.length} ทีม</Badge>
+                )}
+                {postId && (
+                  <Button variant="outline" size="sm" onClick={handleExport}>
+                    <Download className="size-4 mr-1" />
+                    ส่งออก CSV
+                  </Button>
+                )}
+              </div>
             </div>
           </CardHeader>
 
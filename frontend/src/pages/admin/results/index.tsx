@@ -2,7 +2,7 @@ import { useState, useEffect } from 'react';
 import { toast } from 'react-toastify';
 import { Search, Eye, Edit, Award, Clock, CheckCircle, AlertCircle, Calendar, Users, Trophy, AlertTriangle } from 'lucide-react';
 import { createResult, updateResult, deleteResult } from '@/services/resultsService';
-import { GetAllPosts } from '@/services/postServices';
+import { GetDetailedPosts } from '@/services/postServices';
 import { GetRegistrationsByPostId } from '@/services/registrationService';
 import { checkPointsDistributed, distributePoints } from '@/services/pointsService';
 import { getImageUrl } from '@/utils/imageUtils';
@@ -208,7 +208,7 @@ export default function AdminResultsPage() {
       setLoading(true);
       setError(null);
       try {
-        const res = await GetAllPosts();
+        const res = await GetDetailedPosts();
         if (res?.status === 200 && res.data?.data) {
           const posts = res.data.data;
 
@@ -540,7 +540,7 @@ export default function AdminResultsPage() {
         toast.success('บันทึกสำเร็จ!');
 
         // Refresh ข้อมูลกิจกรรมเพื่อแสดงสถานะใหม่
-        const res = await GetAllPosts();
+        const res = await GetDetailedPosts();
         if (res?.status === 200 && res.data?.data) {
           const posts = res.data.data;
           const transformedActivities: Activity[] = posts.map((post: any) => {
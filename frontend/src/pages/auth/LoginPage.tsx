@@ -6,7 +6,14 @@ import { Input } from "@/components/ui/input";
 import { Label } from "@/components/ui/label";
 import { BrandLogo } from "@/components/ui/brand-logo";
 import { useAuth } from "@/context/AuthContext";
-import { login as loginService } from "@/services/authService";
+import {
+  login as loginService,
+  getOidcConfig,
+  getOidcLoginUrl,
+  verify2fa,
+  setup2faChallenge,
+  enable2faChallenge,
+} from "@/services/authService";
 import { toast } from "react-toastify";
 import { Link, useNavigate, useLocation } from "react-router-dom";
 import { useForm } from "react-hook-form";
@@ -34,6 +41,19 @@ interface LoginModalProps {
 export function LoginModal({ open, onOpenChange }: LoginModalProps) {
   const [showPassword, setShowPassword] = useState(false);
   const [loading, setLoading] = useState(false);
+  const [ssoEnabled, setSsoEnabled] = useState(false);
+  // ขั้นที่สอง (2FA) หลังรหัสผ่านถูกต้อง
+  const [challenge, setChallenge] = useState<{
+    token: string;
+    setupRequired: boolean;
+  } | null>(null);
+  const [setupInfo, setSetupInfo] = useState<{
+    qr_code: string;
+    secret: string;
+  } | null>(null);
+  const [mfaCode, setMfaCode] = useState("");
+  const [recoveryCodes, setRecoveryCodes] = useState<string[] | null>(null);
+  const [pendingLogin, setPendingLogin] = useState<any>(null);
   const { login } = useAuth();
   const navigate = useNavigate();
   const location = useLocation();
@@ -62,6 +82,101 @@ export function LoginModal({ open, onOpenChange }: LoginModalProps) {
     }
   }, [location.state, setValue]);
 
+  // กลับมาจากลิงก์ยืนยันอีเมล (/login?verified=1)
+  useEffect(() => {
+    const verified = new URLSearchParams(location.search).get("verified");
+    if (verified === "1") {
+      toast.success("ยืนยันอีเมลสำเร็จ กรุณาเข้าสู่ระบบ");
+    } else if (verified === "0") {
+      toast.error("ลิงก์ยืนยันอีเมลไม่ถูกต้องหรือหมดอายุแล้ว");
+    }
+    const ssoError = new URLSearchParams(location.search).get("sso_error");
+    if (ssoError) {
+      toast.error(ssoError);
+    }
+  }, [location.search]);
+
+  // SSO ของผู้ที่ต้องใช้ 2FA จะกลับมาที่ /login#challenge_token=...
+  useEffect(() => {
+    const params = new URLSearchParams(location.hash.slice(1));
+    const token = params.get("challenge_token");
+    if (token) {
+      window.history.replaceState(null, "", location.pathname);
+      startChallenge(token, params.get("mfa_setup_required") === "true");
+    }
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [location.hash]);
+
+  useEffect(() => {
+    if (!open) return;
+    getOidcConfig().then((res) => setSsoEnabled(Boolean(res?.enabled)));
+  }, [open]);
+
+  const resetMfa = () => {
+    setChallenge(null);
+    setSetupInfo(null);
+    setMfaCode("");
+    setRecoveryCodes(null);
+    setPendingLogin(null);
+  };
+
+  const finishLogin = async (response: any) => {
+    // รอให้ login และ fetch profile เสร็จก่อน navigate
+    await login(response.token, response.token_type, response.refresh_token);
+    toast.success(`ยินดีต้อนรับ!`);
+    onOpenChange(false);
+    reset();
+    resetMfa();
+
+    // ใช้ role จาก response ที่ได้จาก backend
+    const userRole = response.role?.toLowerCase();
+    if (userRole === "admin") {
+      navigate("/admin/events");
+    } else if (userRole === "student" || userRole === "organizer") {
+      navigate("/student/events");
+    } else {
+      navigate("/");
+    }
+  };
+
+  const startChallenge = async (token: string, setupRequired: boolean) => {
+    setChallenge({ token, setupRequired });
+    if (setupRequired) {
+      const res = await setup2faChallenge(token);
+      if (res?.data?.error) {
+        toast.error(res.data.error);
+        resetMfa();
+        return;
+      }
+      setSetupInfo(res.data);
+    }
+  };
+
+  const onSubmitMfa = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (!challenge) return;
+    setLoading(true);
+    try {
+      const response = challenge.setupRequired
+        ? await enable2faChallenge(challenge.token, mfaCode)
+        : await verify2fa(challenge.token, mfaCode);
+      if (response?.data?.error) {
+        toast.error(response.data.error);
+        if (response.status === 401) resetMfa();
+        return;
+      }
+      if (response.recovery_codes) {
+        // แสดงรหัสสำรองก่อน แล้วค่อยเข้าสู่ระบบเมื่อผู้ใช้กดดำเนินการต่อ
+        setRecoveryCodes(response.recovery_codes);
+        setPendingLogin(response);
+        return;
+      }
+      await finishLogin(response);
+    } finally {
+      setLoading(false);
+    }
+  };
+
   const onSubmit = async (data: LoginFormValues) => {
     setLoading(true);
     try {
@@ -76,21 +191,12 @@ export function LoginModal({ open, onOpenChange }: LoginModalProps) {
         return;
       }
 
-      // รอให้ login และ fetch profile เสร็จก่อน navigate
-      await login(response.token, response.token_type);
-      toast.success(`ยินดีต้อนรับ!`);
-      onOpenChange(false);
-      reset();
-
-      // ใช้ role จาก response ที่ได้จาก backend
-      const userRole = response.role?.toLowerCase();
-      if (userRole === "admin") {
-        navigate("/admin/events");
-      } else if (userRole === "student") {
-        navigate("/student/events");
-      } else {
-        navigate("/");
+      if (response.challenge_token) {
+        await startChallenge(response.challenge_token, response.mfa_setup_required);
+        return;
       }
+
+      await finishLogin(response);
     } catch (error: any) {
       console.error("Login error:", error);
       toast.error("เข้าสู่ระบบไม่สำเร็จ กรุณาตรวจสอบข้อมูลอีกครั้ง");
@@ -119,6 +225,74 @@ export function LoginModal({ open, onOpenChange }: LoginModalProps) {
           </p>
         </div>
 
+        {recoveryCodes ? (
+          <div className="p-8 pt-2 space-y-5">
+            <p className="text-sm text-slate-600">
+              เก็บรหัสสำรองเหล่านี้ไว้ในที่ปลอดภัย ใช้แทนรหัสจากแอปได้รหัสละหนึ่งครั้ง
+              และจะไม่แสดงอีก
+            </p>
+            <div className="grid grid-cols-2 gap-2 font-mono text-sm bg-slate-50 rounded-xl p-4">
+              {recoveryCodes.map((code) => (
+                <span key={code}>{code}</span>
+              ))}
+            </div>
+            <Button
+              type="button"
+              onClick={() => finishLogin(pendingLogin)}
+              className="bg-primary rounded-full w-full heading-font"
+            >
+              บันทึกแล้ว ดำเนินการต่อ
+            </Button>
+          </div>
+        ) : challenge ? (
+          <form onSubmit={onSubmitMfa} className="p-8 pt-2 space-y-5">
+            {challenge.setupRequired && setupInfo && (
+              <div className="space-y-2 text-center">
+                <p className="text-sm text-slate-600">
+                  บัญชีนี้ต้องใช้การยืนยันตัวตนสองขั้นตอน สแกน QR code
+                  ด้วยแอป Authenticator
+                </p>
+                <img
+                  src={setupInfo.qr_code}
+                  alt="QR code"
+                  className="mx-auto size-48"
+                />
+                <p className="text-xs text-slate-400 font-mono break-all">
+                  {setupInfo.secret}
+                </p>
+              </div>
+            )}
+            <div className="space-y-2">
+              <Label>
+                {challenge.setupRequired
+                  ? "รหัส 6 หลักจากแอป"
+                  : "รหัส 6 หลักจากแอป หรือรหัสสำรอง"}
+              </Label>
+              <Input
+                type="text"
+                value={mfaCode}
+                onChange={(e) => setMfaCode(e.target.value)}
+                autoComplete="one-time-code"
+                autoFocus
+              />
+            </div>
+            <Button
+              type="submit"
+              disabled={loading || !mfaCode}
+              className="bg-primary rounded-full w-full heading-font"
+            >
+              {loading ? "กำลังตรวจสอบ..." : "ยืนยัน"}
+            </Button>
+            <Button
+              type="button"
+              variant="ghost"
+              onClick={resetMfa}
+              className="rounded-full w-full"
+            >
+              ยกเลิก
+            </Button>
+          </form>
+        ) : (
         <form onSubmit={handleSubmit(onSubmit)} className="p-8 pt-2 space-y-5">
           <div className="space-y-2">
             <Label className={errors.sut_id ? "text-red-500" : ""}>
@@ -158,6 +332,15 @@ export function LoginModal({ open, onOpenChange }: LoginModalProps) {
             {errors.password && (
               <p className="text-red-500 text-sm">{errors.password.message}</p>
             )}
+            <div className="text-right">
+              <Link
+                to="/forgot-password"
+                onClick={() => onOpenChange(false)}
+                className="text-sm text-slate-500 hover:text-slate-900 hover:underline"
+              >
+                ลืมรหัสผ่าน?
+              </Link>
+            </div>
           </div>
           <Button
             type="submit"
@@ -166,7 +349,18 @@ export function LoginModal({ open, onOpenChange }: LoginModalProps) {
           >
             {loading ? "กำลังเข้าสู่ระบบ..." : "เข้าสู่ระบบ"}
           </Button>
+          {ssoEnabled && (
+            <Button
+              type="button"
+              variant="outline"
+              onClick={() => (window.location.href = getOidcLoginUrl())}
+              className="rounded-full w-full heading-font"
+            >
+              เข้าสู่ระบบด้วยบัญชีมหาวิทยาลัย (SSO)
+            </Button>
+          )}
         </form>
+        )}
 
         {/* Footer */}
         <div className="p-6 pt-0 bg-slate-50/50 border-t border-slate-100 text-center">
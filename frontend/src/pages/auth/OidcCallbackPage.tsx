@@ -0,0 +1,45 @@
+import { useEffect, useRef } from "react";
+import { useNavigate } from "react-router-dom";
+import { toast } from "react-toastify";
+import { useAuth } from "@/context/AuthContext";
+
+// backend ส่ง token กลับมาใน URL fragment (#token=...) หลังเข้าสู่ระบบด้วยบัญชีมหาวิทยาลัย
+export default function OidcCallbackPage() {
+  const { login } = useAuth();
+  const navigate = useNavigate();
+  const handled = useRef(false);
+
+  useEffect(() => {
+    if (handled.current) return;
+    handled.current = true;
+
+    const params = new URLSearchParams(window.location.hash.slice(1));
+    const token = params.get("token");
+    // ลบ token ออกจาก address bar / history
+    window.history.replaceState(null, "", window.location.pathname);
+
+    if (!token) {
+      toast.error("เข้าสู่ระบบไม่สำเร็จ กรุณาลองใหม่อีกครั้ง");
+      navigate("/login");
+      return;
+    }
+
+    const finish = async () => {
+      await login(
+        token,
+        params.get("token_type") || "Bearer",
+        params.get("refresh_token") || undefined
+      );
+      toast.success("ยินดีต้อนรับ!");
+      const role = params.get("role")?.toLowerCase();
+      navigate(role === "admin" ? "/admin/events" : "/student/events");
+    };
+    finish();
+  }, [login, navigate]);
+
+  return (
+    <div className="flex justify-center items-center h-screen text-slate-500">
+      กำลังเข้าสู่ระบบ...
+    </div>
+  );
+}
@@ -0,0 +1,136 @@
+import { useState } from "react";
+import { Link, useNavigate, useSearchParams } from "react-router-dom";
+import { toast } from "react-toastify";
+import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
+import { Label } from "@/components/ui/label";
+import { BrandLogo } from "@/components/ui/brand-logo";
+import { forgotPassword, resetPassword } from "@/services/authService";
+
+// หน้าเดียวใช้ทั้งขอลิงก์รีเซ็ต (/forgot-password) และตั้งรหัสใหม่ (/reset-password?token=...)
+export default function ResetPasswordPage() {
+  const [searchParams] = useSearchParams();
+  const token = searchParams.get("token");
+  const navigate = useNavigate();
+  const [email, setEmail] = useState("");
+  const [password, setPassword] = useState("");
+  const [confirmPassword, setConfirmPassword] = useState("");
+  const [loading, setLoading] = useState(false);
+
+  const handleForgot = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setLoading(true);
+    try {
+      const res = await forgotPassword(email);
+      if (res?.data?.error) {
+        toast.error(res.data.error);
+        return;
+      }
+      toast.success(res.message);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleReset = async (e: React.FormEvent) => {
+    e.preventDefault();
+    if (password.length < 8) {
+      toast.error("รหัสผ่านต้องมีอย่างน้อย 8 ตัวอักษร");
+      return;
+    }
+    if (password !== confirmPassword) {
+      toast.error("รหัสผ่านไม่ตรงกัน");
+      return;
+    }
+    setLoading(true);
+    try {
+      const res = await resetPassword(token as string, password);
+      if (res?.data?.error) {
+        toast.error(res.data.error);
+        return;
+      }
+      navigate("/login", { state: { message: res.message } });
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen flex items-center justify-center bg-slate-50 px-4">
+      <div className="w-full max-w-md bg-white rounded-4xl border border-slate-200/60 p-8 space-y-6">
+        <div className="text-center">
+          <div className="flex justify-center mb-4">
+            <BrandLogo size="md" variant="dark" showText={false} linkTo="/" />
+          </div>
+          <h3 className="text-2xl font-bold text-slate-900 heading-font">
+            {token ? "ตั้งรหัสผ่านใหม่" : "ลืมรหัสผ่าน"}
+          </h3>
+          <p className="text-slate-500 mt-2 font-light">
+            {token
+              ? "กรอกรหัสผ่านใหม่ที่ต้องการใช้งาน"
+              : "กรอกอีเมลที่ใช้สมัคร ระบบจะส่งลิงก์สำหรับตั้งรหัสผ่านใหม่ให้"}
+          </p>
+        </div>
+
+        {token ? (
+          <form onSubmit={handleReset} className="space-y-5">
+            <div className="space-y-2">
+              <Label>รหัสผ่านใหม่</Label>
+              <Input
+                type="password"
+                value={password}
+                onChange={(e) => setPassword(e.target.value)}
+                autoComplete="new-password"
+              />
+            </div>
+            <div className="space-y-2">
+              <Label>ยืนยันรหัสผ่านใหม่</Label>
+              <Input
+                type="password"
+                value={confirmPassword}
+                onChange={(e) => setConfirmPassword(e.target.value)}
+                autoComplete="new-password"
+              />
+            </div>
+            <Button
+              type="submit"
+              disabled={loading}
+              className="bg-primary rounded-full w-full heading-font"
+            >
+              {loading ? "กำลังบันทึก..." : "บันทึกรหัสผ่านใหม่"}
+            </Button>
+          </form>
+        ) : (
+          <form onSubmit={handleForgot} className="space-y-5">
+            <div className="space-y-2">
+              <Label>อีเมล</Label>
+              <Input
+                type="email"
+                placeholder="bxxxxxxx@g.sut.ac.th"
+                value={email}
+                onChange={(e) => setEmail(e.target.value)}
+                required
+              />
+            </div>
+            <Button
+              type="submit"
+              disabled={loading}
+              className="bg-primary rounded-full w-full heading-font"
+            >
+              {loading ? "กำลังส่ง..." : "ส่งลิงก์รีเซ็ตรหัสผ่าน"}
+            </Button>
+          </form>
+        )}
+
+        <p className="text-sm text-slate-500 text-center">
+          <Link
+            to="/login"
+            className="text-slate-900 font-bold hover:underline decoration-2 underline-offset-4"
+          >
+            กลับไปหน้าเข้าสู่ระบบ
+          </Link>
+        </p>
+      </div>
+    </div>
+  );
+}
@@ -29,6 +29,8 @@ import {
   updateMyProfile,
   uploadAvatar,
   getMyProfile,
+  exportMyData,
+  deleteMyAccount,
 } from "@/services/profileService";
 import { getImageUrl } from "@/utils/imageUtils";
 import TagInputBox from "@/pages/auth/register-components/TagInputBox";
@@ -38,7 +40,7 @@ import type { Major } from "@/interfaces/major";
 import type { User, SocialMedia } from "@/interfaces/user";
 
 export default function EditProfilePage() {
-  const { user, refreshProfile } = useAuth();
+  const { user, refreshProfile, logout } = useAuth();
   const navigate = useNavigate();
   const fileInputRef = useRef<HTMLInputElement>(null);
 
@@ -47,6 +49,10 @@ export default function EditProfilePage() {
   const [saving, setSaving] = useState(false);
   const [error, setError] = useState("");
   const [saveConfirm, setSaveConfirm] = useState(false);
+  const [exporting, setExporting] = useState(false);
+  const [deleteConfirm, setDeleteConfirm] = useState(false);
+  const [deletePassword, setDeletePassword] = useState("");
+  const [deleting, setDeleting] = useState(false);
 
   const [formData, setFormData] = useState({
     first_name: "",
@@ -196,6 +202,36 @@ export default function EditProfilePage() {
     }
   };
 
+  const handleExport = async () => {
+    setExporting(true);
+    const blob = await exportMyData();
+    setExporting(false);
+    if (!blob) {
+      toast.error("ไม่สามารถดาวน์โหลดข้อมูลส่วนบุคคลได้");
+      return;
+    }
+    const url = URL.createObjectURL(blob);
+    const link = document.createElement("a");
+    link.href = url;
+    link.download = `engiconnect-data-${user?.sut_id ?? "me"}.zip`;
+    link.click();
+    URL.revokeObjectURL(url);
+  };
+
+  const handleDeleteAccount = async () => {
+    setDeleting(true);
+    const res = await deleteMyAccount(deletePassword);
+    setDeleting(false);
+    if (!res || res.error) {
+      toast.error(res?.error || "ไม่สามารถลบบัญชีได้");
+      return;
+    }
+    setDeleteConfirm(false);
+    toast.success(res.message);
+    logout();
+    navigate("/login");
+  };
+
   if (loading) {
     return (
       <div className="flex items-center justify-center py-20">
@@ -493,6 +529,23 @@ export default function EditProfilePage() {
             </Button>
           </div>
         </form>
+
+        <div className="mt-10 p-6 border border-slate-200 rounded-lg space-y-3">
+          <h2 className="text-lg font-semibold">ข้อมูลส่วนบุคคล (PDPA)</h2>
+          <p className="text-sm text-slate-600">
+            ดาวน์โหลดสำเนาข้อมูลทั้งหมดที่ระบบเก็บเกี่ยวกับคุณ หรือขอลบบัญชี
+            เมื่อลบบัญชีแล้วข้อมูลส่วนตัวจะถูกลบถาวร
+            ส่วนข้อความและผลประเมินเดิมจะแสดงเป็นผู้ใช้ที่ลบบัญชีแทน
+          </p>
+          <div className="flex gap-4">
+            <Button type="button" variant="outline" onClick={handleExport} disabled={exporting}>
+              {exporting ? "กำลังเตรียมไฟล์..." : "ดาวน์โหลดข้อมูลของฉัน"}
+            </Button>
+            <Button type="button" variant="destructive" onClick={() => setDeleteConfirm(true)}>
+              ลบบัญชี
+            </Button>
+          </div>
+        </div>
       </div>
 
       {/* Save Confirmation Dialog */}
@@ -518,6 +571,34 @@ export default function EditProfilePage() {
           </AlertDialogFooter>
         </AlertDialogContent>
       </AlertDialog>
+
+      {/* Delete Account Dialog */}
+      <AlertDialog open={deleteConfirm} onOpenChange={setDeleteConfirm}>
+        <AlertDialogContent>
+          <AlertDialogHeader>
+            <AlertDialogTitle>ยืนยันการลบบัญชี</AlertDialogTitle>
+            <AlertDialogDescription>
+              การลบบัญชีย้อนกลับไม่ได้ กรุณากรอกรหัสผ่านเพื่อยืนยัน
+            </AlertDialogDescription>
+          </AlertDialogHeader>
+          <Input
+            type="password"
+            value={deletePassword}
+            onChange={(e) => setDeletePassword(e.target.value)}
+            placeholder="รหัสผ่าน"
+          />
+          <AlertDialogFooter>
+            <AlertDialogCancel>ยกเลิก</AlertDialogCancel>
+            <Button
+              variant="destructive"
+              onClick={handleDeleteAccount}
+              disabled={deleting || !deletePassword}
+            >
+              {deleting ? "กำลังลบ..." : "ลบบัญชีถาวร"}
+            </Button>
+          </AlertDialogFooter>
+        </AlertDialogContent>
+      </AlertDialog>
     </div>
   );
 }
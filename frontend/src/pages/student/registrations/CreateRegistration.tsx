@@ -19,8 +19,15 @@ import {
   UpdateRegistration, 
   UpdateRegistrationStatus,
   getUserByStudentId, 
-  GetRegistrationsByPostId 
+  GetRegistrationsByPostId,
+  GetRegistrationForm,
+  UploadRegistrationFile,
 } from "@/services/registrationService";
+import type {
+  RegistrationAnswer,
+  RegistrationAnswerInput,
+  RegistrationFormField,
+} from "@/interfaces/registration";
 import { GetPostById } from "@/services/postServices";
 import { toast } from 'react-toastify';
 import { useAuth } from '@/context/AuthContext';
@@ -76,6 +83,39 @@ export default function PostRegistration() {
 
   const [errors, setErrors] = useState<{ [key: string]: string }>({});
 
+  // แบบฟอร์มสมัครเพิ่มเติมที่ผู้จัดกิจกรรมกำหนด
+  const [formFields, setFormFields] = useState<RegistrationFormField[]>([]);
+  const [answers, setAnswers] = useState<Record<number, RegistrationAnswerInput["value"]>>({});
+  const [uploadingField, setUploadingField] = useState<number | null>(null);
+
+  const fieldId = (field: RegistrationFormField) => Number(field.ID ?? field.id);
+
+  const updateAnswer = (id: number, value: RegistrationAnswerInput["value"]) => {
+    setAnswers((prev) => ({ ...prev, [id]: value }));
+  };
+
+  const handleAnswerFile = async (id: number, file?: File) => {
+    if (!file) return;
+    setUploadingField(id);
+    const res = await UploadRegistrationFile(file);
+    setUploadingField(null);
+    if (res?.status === 200 && res.data?.url) {
+      updateAnswer(id, res.data.url);
+    } else {
+      toast.error(res?.data?.error || "อัปโหลดไฟล์ไม่สำเร็จ");
+    }
+  };
+
+  const missingRequiredAnswer = () =>
+    formFields.find((field) => {
+      if (!field.required) return false;
+      const value = answers[fieldId(field)];
+      return value === undefined || value === null || value === "" || (Array.isArray(value) && value.length === 0);
+    });
+
+  const answerPayload = (): RegistrationAnswerInput[] =>
+    formFields.map((field) => ({ field_id: fieldId(field), value: answers[fieldId(field)] ?? null }));
+
   const validateForm = () => {
     const newErrors: { [key: string]: string } = {};
   
@@ -243,6 +283,22 @@ export default function PostRegistration() {
               note: userRegistration.description ?? userRegistration.Description ?? '',
             });
 
+            const previousAnswers: Record<number, RegistrationAnswerInput["value"]> = {};
+            (userRegistration.answers ?? []).forEach((a: RegistrationAnswer) => {
+              if (a.field?.type === "multi_choice") {
+                try {
+                  previousAnswers[a.field_id] = JSON.parse(a.value);
+                } catch {
+                  previousAnswers[a.field_id] = [];
+                }
+              } else if (a.field?.type === "number") {
+                previousAnswers[a.field_id] = Number(a.value);
+              } else {
+                previousAnswers[a.field_id] = a.value;
+              }
+            });
+            setAnswers(previousAnswers);
+
             const existingUsers = userRegistration.users ?? userRegistration.Users ?? [];
   
             if (existingUsers.length > 0) {
@@ -342,6 +398,11 @@ export default function PostRegistration() {
           stopDate: data.stop_date,
         });
 
+        const formRes = await GetRegistrationForm(Number(id));
+        if (formRes?.status === 200) {
+          setFormFields(formRes.data?.data ?? []);
+        }
+
         await checkExistingRegistration();
 
         console.log("Post set successfully");
@@ -418,6 +479,12 @@ export default function PostRegistration() {
       return;
     }
   
+    const missing = missingRequiredAnswer();
+    if (missing) {
+      toast.error(`กรุณาตอบ ${missing.label}`);
+      return;
+    }
+
     const teamName = formData.teamName.trim();
     const postIdNum = Number(id);
   
@@ -477,6 +544,7 @@ export default function PostRegistration() {
         const updatePayload = {
           team_name: teamName,
           description: formData.note?.trim() || "-",
+          answers: answerPayload(),
         };
   
         console.log("Updating registration:", registrationId, updatePayload);
@@ -515,6 +583,7 @@ export default function PostRegistration() {
         description: formData.note?.trim() || "-",
         post_id: postIdNum,
         user_ids: userIds,
+        answers: answerPayload(),
         status: "pending",
         registration_date: new Date().toISOString(),
       };
@@ -526,7 +595,9 @@ export default function PostRegistration() {
   
       if (res?.status === 200 || res?.status === 201) {
         setShowSuccessDialog(true);
-        toast.success("ลงทะเบียนสำเร็จ");
+        // สมาชิกคนอื่นได้รับคำเชิญ และเข้าทีมเมื่อกดตอบรับ
+        toast.success(userIds.length > 1 ? "ลงทะเบียนสำเร็จ ส่งคำเชิญให้สมาชิกในทีมแล้ว" : "ลงทะเบียนสำเร็จ");
+        (res.data?.data?.warnings ?? []).forEach((w: string) => toast.warning(w));
         setTimeout(() => navigate(`/student/registrations/MyRegistrationsPage`), 1500);
         return;
       }
@@ -805,6 +876,95 @@ export default function PostRegistration() {
                   </div>
                 </div>
 
+                {/* Registration Form Fields */}
+                {formFields.length > 0 && (
+                  <div className="border-t pt-6">
+                    <h3 className="text-lg font-semibold text-slate-900 mb-4 flex items-center gap-2">
+                      <Info className="size-5 text-indigo-600" />
+                      ข้อมูลเพิ่มเติมที่ผู้จัดกิจกรรมต้องการ
+                    </h3>
+                    <div className="space-y-4">
+                      {formFields.map((field) => {
+                        const fid = fieldId(field);
+                        const value = answers[fid];
+                        return (
+                          <div key={fid} className="space-y-2">
+                            <Label htmlFor={`field-${fid}`}>
+                              {field.label}
+                              {field.required && " *"}
+                            </Label>
+                            {field.type === "text" && (
+                              <Input
+                                id={`field-${fid}`}
+                                value={(value as string) ?? ""}
+                                onChange={(e) => updateAnswer(fid, e.target.value)}
+                              />
+                            )}
+                            {field.type === "number" && (
+                              <Input
+                                id={`field-${fid}`}
+                                type="number"
+                                value={value === null || value === undefined ? "" : String(value)}
+                                onChange={(e) => updateAnswer(fid, e.target.value === "" ? null : Number(e.target.value))}
+                              />
+                            )}
+                            {field.type === "choice" && (
+                              <select
+                                id={`field-${fid}`}
+                                className="w-full h-9 rounded-md border border-input bg-transparent px-3 text-sm"
+                                value={(value as string) ?? ""}
+                                onChange={(e) => updateAnswer(fid, e.target.value)}
+                              >
+                                <option value="">เลือก</option>
+                                {(field.options ?? []).map((option) => (
+                                  <option key={option} value={option}>{option}</option>
+                                ))}
+                              </select>
+                            )}
+                            {field.type === "multi_choice" && (
+                              <div className="flex flex-wrap gap-4">
+                                {(field.options ?? []).map((option) => {
+                                  const selected = Array.isArray(value) ? value : [];
+                                  return (
+                                    <label key={option} className="flex items-center gap-2 text-sm">
+                                      <input
+                                        type="checkbox"
+                                        checked={selected.includes(option)}
+                                        onChange={(e) =>
+                                          updateAnswer(
+                                            fid,
+                                            e.target.checked
+                                              ? [...selected, option]
+                                              : selected.filter((s) => s !== option)
+                                          )
+                                        }
+                                      />
+                                      {option}
+                                    </label>
+                                  );
+                                })}
+                              </div>
+                            )}
+                            {field.type === "file" && (
+                              <div className="flex items-center gap-3">
+                                <Input
+                                  id={`field-${fid}`}
+                                  type="file"
+                                  onChange={(e) => handleAnswerFile(fid, e.target.files?.[0])}
+                                />
+                                {uploadingField === fid && <Loader2 className="size-4 animate-spin" />}
+                                {typeof value === "string" && value && uploadingField !== fid && (
+                                  <CheckCircle2 className="size-4 text-green-600" />
+                                )}
+                              </div>
+                            )}
+                          </div>
+                        );
+                      })}
+                    </div>
+                  </div>
+                )}
+
                 {/* Team Members Section */}
                 <div className="border-t pt-6">
                   <div className="flex items-center justify-between mb-4">
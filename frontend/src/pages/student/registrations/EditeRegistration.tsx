@@ -24,13 +24,15 @@ import {
   Building,
   GraduationCap,
   AlertTriangle,
+  Crown,
 } from "lucide-react";
 import {
   GetRegistrationById,
-  DeleteRegistration,
+  WithdrawRegistration,
   AddUserToRegistration,
   RemoveUserFromRegistration,
   UpdateRegistration,
+  TransferLeadership,
 } from "@/services/registrationService";
 import { GetPostById } from "@/services/postServices";
 import { cn } from "@/lib/utils";
@@ -68,8 +70,17 @@ const statusConfig: Record<
     color: "text-red-700",
     bgColor: "bg-red-50",
   },
+  withdrawn: {
+    label: "ถอนการสมัครแล้ว",
+    icon: XCircle,
+    color: "text-gray-700",
+    bgColor: "bg-gray-50",
+  },
 };
 
+// สถานะที่ยังถอนการสมัครได้
+const withdrawableStatuses = ["pending", "approved", "waitlisted", "offered"];
+
 export interface AddUserPayload {
   user_id?: number;
   sut_id?: string;
@@ -83,6 +94,7 @@ export default function RegistrationDetail() {
   const [savingTeam, setSavingTeam] = React.useState(false);
   const [savingMember, setSavingMember] = React.useState(false);
   const [showCancelDialog, setShowCancelDialog] = React.useState(false);
+  const [withdrawReason, setWithdrawReason] = React.useState("");
   const [memberToDelete, setMemberToDelete] = React.useState<number | null>(null);
 
   const [reg, setReg] = React.useState<any>(null);
@@ -219,6 +231,8 @@ export default function RegistrationDetail() {
   const teamName = toText(reg.team_name ?? reg.TeamName, "-");
   const desc = toText(reg.description ?? reg.Description, "");
   const users: any[] = Array.isArray(reg.users ?? reg.Users) ? reg.users ?? reg.Users : [];
+  // ทีมเก่าที่ยังไม่มี leader_id ถือว่าคนแรกเป็นหัวหน้าทีม
+  const leaderId = reg.leader_id ?? users[0]?.ID ?? users[0]?.id;
 
   const rawStatus = toText(reg?.status ?? reg?.Status, "pending");
   const status = rawStatus.trim().toLowerCase();
@@ -247,11 +261,15 @@ export default function RegistrationDetail() {
     : null;
 
   const onCancelRegistration = async () => {
+    if (!withdrawReason.trim()) {
+      toast.error("กรุณาระบุเหตุผลการถอนการสมัคร");
+      return;
+    }
     try {
-      const res = await DeleteRegistration(regId);
+      const res = await WithdrawRegistration(regId, withdrawReason.trim());
   
       if (res?.status === 200) {
-        toast.success("ยกเลิกการลงทะเบียนเรียบร้อย");
+        toast.success("ถอนการสมัครเรียบร้อย");
         nav("/student/registrations/MyRegistrationsPage");
       } else {
         toast.error(res?.data?.error || "ยกเลิกไม่สำเร็จ");
@@ -310,8 +328,14 @@ export default function RegistrationDetail() {
         sut_id: memberSutId.trim(),
       });
 
-      if (res?.status === 200) {
+      // 201 = ส่งคำเชิญแล้ว (สมาชิกเข้าทีมเมื่อตอบรับ) 200 = admin เพิ่มเข้าทีมทันที
+      if (res?.status === 201) {
+        toast.success("ส่งคำเชิญแล้ว รอสมาชิกตอบรับ");
+        (res.data?.data?.warnings ?? []).forEach((w: string) => toast.warning(w));
+        setMemberSutId("");
+      } else if (res?.status === 200) {
         toast.success("เพิ่มสมาชิกเรียบร้อย");
+        (res.data?.warnings ?? []).forEach((w: string) => toast.warning(w));
         setMemberSutId("");
         await reload();
       } else {
@@ -324,6 +348,16 @@ export default function RegistrationDetail() {
     }
   };
 
+  const onTransferLeader = async (uid: number) => {
+    const res = await TransferLeadership(regId, uid);
+    if (res?.status === 200) {
+      toast.success("โอนตำแหน่งหัวหน้าทีมเรียบร้อย");
+      await reload();
+    } else {
+      toast.error(res?.data?.error || "โอนตำแหน่งหัวหน้าทีมไม่สำเร็จ");
+    }
+  };
+
   const onRemoveMember = async (uid: number) => {
     try {
       const res = await RemoveUserFromRegistration(regId, { user_id: uid });
@@ -589,7 +623,7 @@ export default function RegistrationDetail() {
               </div>
             ) : (
               <div className="grid grid-cols-1 md:grid-cols-2 gap-4">
-                {users.map((u: any, index: number) => {
+                {users.map((u: any) => {
                   const userId = u.ID ?? u.id;
                   const firstName = toText(u.FirstName ?? u.first_name ?? u.firstName, "");
                   const lastName = toText(u.LastName ?? u.last_name ?? u.lastName, "");
@@ -601,8 +635,8 @@ export default function RegistrationDetail() {
                   return (
                     <Card key={userId} className="bg-slate-50 relative">
                       <CardContent className="p-4">
-                        {/* ✅ ปุ่มลบมุมบนขวา: เฉพาะ Pending + ไม่ใช่ผู้ลงทะเบียน (index > 0) */}
-                        {status === "pending" && index > 0 && (
+                        {/* ✅ ปุ่มลบมุมบนขวา: เฉพาะ Pending + ไม่ใช่หัวหน้าทีม */}
+                        {status === "pending" && userId !== leaderId && (
                           <Button
                             variant="ghost"
                             size="icon"
@@ -621,10 +655,22 @@ export default function RegistrationDetail() {
                             <div className="font-semibold">
                               {firstName} {lastName}
                             </div>
-                            {index === 0 && (
+                            {userId === leaderId ? (
                               <Badge variant="secondary" className="text-xs mt-1">
-                                ผู้ลงทะเบียน
+                                <Crown className="size-3 mr-1" />
+                                หัวหน้าทีม
                               </Badge>
+                            ) : (
+                              status === "pending" && (
+                                <Button
+                                  variant="link"
+                                  size="sm"
+                                  className="h-auto p-0 text-xs"
+                                  onClick={() => onTransferLeader(userId)}
+                                >
+                                  ตั้งเป็นหัวหน้าทีม
+                                </Button>
+                              )
                             )}
                           </div>
                         </div>
@@ -667,17 +713,19 @@ export default function RegistrationDetail() {
           </CardContent>
         </Card>
 
-        {/* Cancel Registration - เฉพาะ Pending */}
-        {status === "pending" && (
+        {/* Withdraw Registration - ทีมที่ยังไม่ถูกปฏิเสธ/หมดเวลา */}
+        {withdrawableStatuses.includes(status) && (
           <Card className="border-red-200">
             <CardContent className="p-6">
               <div className="flex items-center justify-between">
                 <div>
                   <h3 className="font-semibold text-slate-900 mb-1">
-                    ยกเลิกการลงทะเบียน
+                    ถอนการสมัคร
                   </h3>
                   <p className="text-sm text-slate-600">
-                    หากต้องการยกเลิก คุณจะไม่สามารถกู้คืนข้อมูลได้
+                    ทีมจะถูกเก็บไว้ในสถานะถอนการสมัคร และไม่สามารถกลับมาสมัครด้วยทีมนี้ได้
+                    {post?.withdrawal_cutoff_hours > 0 && post?.late_withdrawal_penalty > 0 &&
+                      ` ถอนภายใน ${post.withdrawal_cutoff_hours} ชั่วโมงก่อนปิดรับสมัครหรือหลังปิดรับสมัคร สมาชิกทุกคนจะถูกหัก ${post.late_withdrawal_penalty} คะแนน`}
                   </p>
                 </div>
                 <Button variant="destructive" onClick={() => setShowCancelDialog(true)}>
@@ -694,11 +742,18 @@ export default function RegistrationDetail() {
       <AlertDialog open={showCancelDialog} onOpenChange={setShowCancelDialog}>
         <AlertDialogContent>
           <AlertDialogHeader>
-            <AlertDialogTitle>ยืนยันการยกเลิก?</AlertDialogTitle>
+            <AlertDialogTitle>ยืนยันการถอนการสมัคร?</AlertDialogTitle>
             <AlertDialogDescription>
-              คุณแน่ใจหรือไม่ว่าต้องการยกเลิกการลงทะเบียนนี้? การกระทำนี้ไม่สามารถย้อนกลับได้
+              คุณแน่ใจหรือไม่ว่าต้องการถอนการสมัครของทีมนี้? การกระทำนี้ไม่สามารถย้อนกลับได้
             </AlertDialogDescription>
           </AlertDialogHeader>
+          <textarea
+            value={withdrawReason}
+            onChange={(e) => setWithdrawReason(e.target.value)}
+            placeholder="เหตุผลการถอนการสมัคร"
+            rows={3}
+            className="w-full rounded-lg border border-slate-200 px-3 py-2 text-sm"
+          />
           <AlertDialogFooter>
             <AlertDialogCancel>ยกเลิก</AlertDialogCancel>
             <AlertDialogAction
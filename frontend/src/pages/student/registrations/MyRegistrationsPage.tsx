@@ -24,10 +24,11 @@ import {
   DialogHeader,
   DialogTitle,
 } from "@/components/ui/dialog";
-import { GetMyRegistrations, DeleteRegistration } from "@/services/registrationService";
+import { GetMyRegistrations, WithdrawRegistration } from "@/services/registrationService";
 import { GetPostById } from "@/services/postServices";
 import { cn } from "@/lib/utils";
 import { toast } from "react-toastify";
+import PendingInvitations from "./PendingInvitations";
 const statusConfig: Record<
   string,
   { label: string; icon: any; color: string }
@@ -35,7 +36,12 @@ const statusConfig: Record<
   pending: { label: "รออนุมัติ", icon: Clock3, color: "bg-yellow-500" },
   approved: { label: "อนุมัติแล้ว", icon: CheckCircle2, color: "bg-green-500" },
   rejected: { label: "ไม่ผ่านการอนุมัติ", icon: XCircle, color: "bg-red-500" },
+  withdrawn: { label: "ถอนการสมัครแล้ว", icon: XCircle, color: "bg-gray-500" },
 };
+
+// สถานะที่ยังถอนการสมัครได้
+const withdrawableStatuses = ["pending", "approved", "waitlisted", "offered"];
+
 interface RegistrationWithPost {
   registration: any;
   post: any | null;
@@ -46,9 +52,10 @@ export default function MyRegistrationsPage() {
   const [loading, setLoading] = React.useState(true);
   const [items, setItems] = React.useState<RegistrationWithPost[]>([]);
   const [error, setError] = React.useState<string | null>(null);
-  const [deleteDialogOpen, setDeleteDialogOpen] = React.useState(false);
-  const [deletingItem, setDeletingItem] = React.useState<RegistrationWithPost | null>(null);
-  const [isDeleting, setIsDeleting] = React.useState(false);
+  const [withdrawDialogOpen, setWithdrawDialogOpen] = React.useState(false);
+  const [withdrawingItem, setWithdrawingItem] = React.useState<RegistrationWithPost | null>(null);
+  const [withdrawReason, setWithdrawReason] = React.useState("");
+  const [isWithdrawing, setIsWithdrawing] = React.useState(false);
   const formatDate = (dateStr?: string) => {
     if (!dateStr) return "";
     const d = new Date(dateStr);
@@ -140,37 +147,42 @@ export default function MyRegistrationsPage() {
     load();
   }, []);
 
-  const handleDeleteClick = (item: RegistrationWithPost) => {
-    setDeletingItem(item);
-    setDeleteDialogOpen(true);
+  const handleWithdrawClick = (item: RegistrationWithPost) => {
+    setWithdrawingItem(item);
+    setWithdrawReason("");
+    setWithdrawDialogOpen(true);
   };
 
-  const handleDeleteConfirm = async () => {
-    if (!deletingItem) return;
+  const handleWithdrawConfirm = async () => {
+    if (!withdrawingItem) return;
 
-    const regId = deletingItem.registration?.ID ?? deletingItem.registration?.id;
+    const regId = withdrawingItem.registration?.ID ?? withdrawingItem.registration?.id;
     if (!regId) {
       toast.error("ไม่พบ ID การลงทะเบียน");
       return;
     }
+    if (!withdrawReason.trim()) {
+      toast.error("กรุณาระบุเหตุผลการถอนการสมัคร");
+      return;
+    }
 
-    setIsDeleting(true);
+    setIsWithdrawing(true);
     try {
-      const res = await DeleteRegistration(Number(regId));
+      const res = await WithdrawRegistration(Number(regId), withdrawReason.trim());
 
       if (res?.status === 200) {
-        toast.success("ลบการลงทะเบียนสำเร็จ");
-        setDeleteDialogOpen(false);
-        setDeletingItem(null);
+        toast.success("ถอนการสมัครเรียบร้อย");
+        setWithdrawDialogOpen(false);
+        setWithdrawingItem(null);
         await load();
       } else {
-        toast.error(res?.data?.error || "ลบการลงทะเบียนไม่สำเร็จ");
+        toast.error(res?.data?.error || "ถอนการสมัครไม่สำเร็จ");
       }
     } catch (error) {
-      console.error("Delete error:", error);
-      toast.error("เกิดข้อผิดพลาดในการลบข้อมูล");
+      console.error("Withdraw error:", error);
+      toast.error("เกิดข้อผิดพลาดในการถอนการสมัคร");
     } finally {
-      setIsDeleting(false);
+      setIsWithdrawing(false);
     }
   };
 
@@ -205,6 +217,9 @@ export default function MyRegistrationsPage() {
   if (items.length === 0) {
     return (
       <div className="min-h-screen bg-slate-50 p-8">
+        <div className="max-w-xl mx-auto">
+          <PendingInvitations onAccepted={load} />
+        </div>
         <Card className="max-w-xl mx-auto">
           <CardContent className="py-12 text-center">
             <div className="text-6xl mb-4">📋</div>
@@ -247,6 +262,7 @@ export default function MyRegistrationsPage() {
       </header>
 
       <main className="w-full px-6 sm:px-8 lg:px-12 py-8">
+        <PendingInvitations onAccepted={load} />
         <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-6">
           {items.map((item, idx) => {
             const { registration, post } = item;
@@ -348,15 +364,15 @@ export default function MyRegistrationsPage() {
                       ดูรายละเอียด
                     </Button>
 
-                    {/* กิจกรรมหมดเวลา → แสดงเฉพาะปุ่มลบ */}
-                    {expired && (
+                    {/* ทีมที่ยังอยู่ในการพิจารณาและกิจกรรมยังไม่จบ → ถอนการสมัคร (ทีมยังถูกเก็บไว้เป็นประวัติ) */}
+                    {!expired && withdrawableStatuses.includes(status.toLowerCase()) && (
                       <Button
                         variant="destructive"
                         className="w-full"
-                        onClick={() => handleDeleteClick(item)}
+                        onClick={() => handleWithdrawClick(item)}
                       >
                         <Trash2 className="size-4 mr-2" />
-                        ลบการลงทะเบียน
+                        ถอนการสมัคร
                       </Button>
                     )}
 
@@ -379,27 +395,27 @@ export default function MyRegistrationsPage() {
         </div>
       </main>
 
-      {/* Delete Confirmation Dialog */}
-      <Dialog open={deleteDialogOpen} onOpenChange={setDeleteDialogOpen}>
+      {/* Withdraw Confirmation Dialog */}
+      <Dialog open={withdrawDialogOpen} onOpenChange={setWithdrawDialogOpen}>
         <DialogContent>
           <DialogHeader>
             <DialogTitle className="flex items-center gap-2 text-red-600">
               <Trash2 className="size-5" />
-              ยืนยันการลบ
+              ยืนยันการถอนการสมัคร
             </DialogTitle>
             <DialogDescription>
-              คุณแน่ใจหรือไม่ว่าต้องการลบการลงทะเบียนนี้?
+              ทีมจะถูกเก็บไว้ในสถานะถอนการสมัคร ถอนหลังกำหนดของกิจกรรมสมาชิกจะถูกหักคะแนน
             </DialogDescription>
           </DialogHeader>
 
-          {deletingItem?.post && (
-            <div className="py-4">
+          {withdrawingItem?.post && (
+            <div className="pt-4">
               <div className="p-4 bg-slate-50 rounded-lg border border-slate-200">
                 <p className="font-semibold text-slate-900 mb-1">
-                  {deletingItem.post.title || "กิจกรรม"}
+                  {withdrawingItem.post.title || "กิจกรรม"}
                 </p>
                 <p className="text-sm text-slate-600">
-                  ทีม: {deletingItem.registration.team_name || deletingItem.registration.TeamName || "-"}
+                  ทีม: {withdrawingItem.registration.team_name || withdrawingItem.registration.TeamName || "-"}
                 </p>
                 <p className="text-xs text-slate-500 mt-2">
                   การดำเนินการนี้ไม่สามารถย้อนกลับได้
@@ -408,23 +424,31 @@ export default function MyRegistrationsPage() {
             </div>
           )}
 
+          <textarea
+            value={withdrawReason}
+            onChange={(e) => setWithdrawReason(e.target.value)}
+            placeholder="เหตุผลการถอนการสมัคร"
+            rows={3}
+            className="w-full rounded-lg border border-slate-200 px-3 py-2 text-sm"
+          />
+
           <DialogFooter>
             <Button
               variant="outline"
               onClick={() => {
-                setDeleteDialogOpen(false);
-                setDeletingItem(null);
+                setWithdrawDialogOpen(false);
+                setWithdrawingItem(null);
               }}
-              disabled={isDeleting}
+              disabled={isWithdrawing}
             >
               ยกเลิก
             </Button>
             <Button
               variant="destructive"
-              onClick={handleDeleteConfirm}
-              disabled={isDeleting}
+              onClick={handleWithdrawConfirm}
+              disabled={isWithdrawing}
             >
-              {isDeleting ? "กำลังลบ..." : "ยืนยันลบ"}
+              {isWithdrawing ? "กำลังถอน..." : "ยืนยันถอนการสมัคร"}
             </Button>
           </DialogFooter>
         </DialogContent>
@@ -0,0 +1,84 @@
+import * as React from "react";
+import { Card, CardContent } from "@/components/ui/card";
+import { Button } from "@/components/ui/button";
+import { Mail } from "lucide-react";
+import { toast } from "react-toastify";
+import type { TeamInvitation } from "@/interfaces/registration";
+import { GetMyInvitations, RespondInvitation } from "@/services/registrationService";
+
+interface PendingInvitationsProps {
+  onAccepted?: () => void;
+}
+
+// PendingInvitations คำเชิญเข้าทีมที่รอผู้ใช้ตอบ ตอบรับแล้วจึงเป็นสมาชิกของทีม
+export default function PendingInvitations({ onAccepted }: PendingInvitationsProps) {
+  const [invitations, setInvitations] = React.useState<TeamInvitation[]>([]);
+  const [respondingId, setRespondingId] = React.useState<number | null>(null);
+
+  const load = async () => {
+    const res = await GetMyInvitations();
+    if (res?.status === 200) setInvitations(res.data?.data ?? []);
+  };
+
+  React.useEffect(() => {
+    load();
+  }, []);
+
+  const respond = async (invitation: TeamInvitation, accept: boolean) => {
+    setRespondingId(invitation.ID);
+    const res = await RespondInvitation(invitation.ID, accept);
+    setRespondingId(null);
+    if (res?.status === 200) {
+      toast.success(accept ? "เข้าร่วมทีมเรียบร้อย" : "ปฏิเสธคำเชิญแล้ว");
+      (res.data?.data?.warnings ?? []).forEach((w: string) => toast.warning(w));
+      if (accept) onAccepted?.();
+    } else {
+      toast.error(res?.data?.error || "ตอบคำเชิญไม่สำเร็จ");
+    }
+    await load();
+  };
+
+  if (invitations.length === 0) return null;
+
+  return (
+    <Card className="mb-6 border-indigo-200">
+      <CardContent className="p-5">
+        <h2 className="font-bold text-lg mb-4 flex items-center gap-2">
+          <Mail className="size-5" />
+          คำเชิญเข้าทีม ({invitations.length})
+        </h2>
+        <div className="space-y-3">
+          {invitations.map((inv) => (
+            <div
+              key={inv.ID}
+              className="flex flex-col sm:flex-row sm:items-center justify-between gap-3 rounded-lg border p-3"
+            >
+              <div>
+                <p className="font-medium">
+                  ทีม {inv.registration?.team_name ?? "-"} · {inv.registration?.post?.title ?? "กิจกรรม"}
+                </p>
+                <p className="text-sm text-slate-500">
+                  เชิญโดย {inv.inviter ? `${inv.inviter.first_name} ${inv.inviter.last_name}` : "-"} · หมดอายุ{" "}
+                  {new Date(inv.expires_at).toLocaleString("th-TH")}
+                </p>
+              </div>
+              <div className="flex gap-2">
+                <Button size="sm" disabled={respondingId === inv.ID} onClick={() => respond(inv, true)}>
+                  ตอบรับ
+                </Button>
+                <Button
+                  size="sm"
+                  variant="outline"
+                  disabled={respondingId === inv.ID}
+                  onClick={() => respond(inv, false)}
+                >
+                  ปฏิเสธ
+                </Button>
+              </div>
+            </div>
+          ))}
+        </div>
+      </CardContent>
+    </Card>
+  );
+}
@@ -1,6 +1,6 @@
 import { useState, useEffect } from 'react';
 import { Search, Calendar, Users, Trophy, AlertTriangle } from 'lucide-react';
-import { GetAllPosts } from '@/services/postServices';
+import { GetDetailedPosts } from '@/services/postServices';
 import { useAuth } from '@/context/AuthContext';
 import { checkPointsDistributed } from '@/services/pointsService';
 import { getImageUrl } from '@/utils/imageUtils';
@@ -62,7 +62,7 @@ export default function StudentResultsPage() {
       setError(null);
       try {
         console.log('Current user:', user);
-        const res = await GetAllPosts();
+        const res = await GetDetailedPosts();
         if (res?.status === 200 && res.data?.data) {
           const posts = res.data.data;
           console.log('All posts:', posts);
@@ -6,6 +6,8 @@ import { lazy } from "react";
 
 const LandingPage = Loadable(lazy(() => import('@/layout/landing/LandingPage')));
 const Register = Loadable(lazy(() => import('@/pages/auth/RegisterPage')));
+const ResetPassword = Loadable(lazy(() => import('@/pages/auth/ResetPasswordPage')));
+const OidcCallback = Loadable(lazy(() => import('@/pages/auth/OidcCallbackPage')));
 
 
 const mainRoutes = createBrowserRouter([
@@ -23,6 +25,18 @@ const mainRoutes = createBrowserRouter([
     path: '/register',
     element: <Register />,
   },
+  {
+    path: '/forgot-password',
+    element: <ResetPassword />,
+  },
+  {
+    path: '/reset-password',
+    element: <ResetPassword />,
+  },
+  {
+    path: '/oidc/callback',
+    element: <OidcCallback />,
+  },
   
   ...studentRoutes,
 
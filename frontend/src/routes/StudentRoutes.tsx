@@ -71,7 +71,7 @@ export const studentRoutes: RouteObject[] = [
   {
     path: "/student",
     element: (
-      <ProtectedRoute allowedRoles={["student"]}>
+      <ProtectedRoute allowedRoles={["student", "organizer"]}>
         <StudentLayout />
       </ProtectedRoute>
     ),
@@ -1,4 +1,5 @@
 import axios from "axios";
+import { getRefreshToken, logout, setAuthToken } from "@/utils/auth";
 const baseURL = import.meta.env.VITE_API_URL;
 export const WS_URL = import.meta.env.VITE_WS_URL
 const apiClient = axios.create({
@@ -18,7 +19,41 @@ apiClient.interceptors.request.use((config) => {
   return config;  
 });
 
+// access token อายุสั้น เมื่อได้ 401 ให้ขอ token ใหม่ด้วย refresh token แล้วลองส่ง request เดิมอีกครั้ง
+let refreshing: Promise<string | null> | null = null;
 
-export default apiClient;
+const refreshAccessToken = async (): Promise<string | null> => {
+  const refreshToken = getRefreshToken();
+  if (!refreshToken) return null;
+  try {
+    const res = await axios.post(`${baseURL}/auth/refresh`, { refresh_token: refreshToken });
+    setAuthToken(res.data.token, res.data.token_type, res.data.refresh_token);
+    return res.data.token;
+  } catch {
+    logout();
+    return null;
+  }
+};
+
+apiClient.interceptors.response.use(
+  (res) => res,
+  async (error) => {
+    const original = error.config;
+    const isAuthCall = original?.url?.startsWith("/auth/");
+    if (error.response?.status !== 401 || !original || original._retry || isAuthCall) {
+      return Promise.reject(error);
+    }
+
+    original._retry = true;
+    refreshing = refreshing ?? refreshAccessToken().finally(() => { refreshing = null; });
+    const token = await refreshing;
+    if (!token) {
+      return Promise.reject(error);
+    }
+    original.headers.Authorization = `Bearer ${token}`;
+    return apiClient(original);
+  }
+);
 
 
+export default apiClient;
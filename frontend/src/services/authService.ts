@@ -16,6 +16,78 @@ export async function register(data: Register) {
     .then((res) => res.data)
     .catch((e) => e.response);
 }
+// ===== Logout Function =====
+export async function logout(refreshToken: string) {
+  return await apiClient
+    .post("/auth/logout", { refresh_token: refreshToken })
+    .then((res) => res.data)
+    .catch((e) => e.response);
+}
+
+// ===== Logout All Devices Function =====
+export async function logoutAll() {
+  return await apiClient
+    .post("/auth/logout-all")
+    .then((res) => res.data)
+    .catch((e) => e.response);
+}
+
+// ===== Email Verification / Password Reset =====
+export async function resendVerification(email: string) {
+  return await apiClient
+    .post("/auth/resend-verification", { email })
+    .then((res) => res.data)
+    .catch((e) => e.response);
+}
+
+export async function forgotPassword(email: string) {
+  return await apiClient
+    .post("/auth/forgot-password", { email })
+    .then((res) => res.data)
+    .catch((e) => e.response);
+}
+
+export async function resetPassword(token: string, password: string) {
+  return await apiClient
+    .post("/auth/reset-password", { token, password })
+    .then((res) => res.data)
+    .catch((e) => e.response);
+}
+
+// ===== Two-Factor Authentication (TOTP) =====
+export async function verify2fa(challengeToken: string, code: string) {
+  return await apiClient
+    .post("/auth/2fa/verify", { challenge_token: challengeToken, code })
+    .then((res) => res.data)
+    .catch((e) => e.response);
+}
+
+export async function setup2faChallenge(challengeToken: string) {
+  return await apiClient
+    .post("/auth/2fa/challenge/setup", { challenge_token: challengeToken })
+    .then((res) => res.data)
+    .catch((e) => e.response);
+}
+
+export async function enable2faChallenge(challengeToken: string, code: string) {
+  return await apiClient
+    .post("/auth/2fa/challenge/enable", { challenge_token: challengeToken, code })
+    .then((res) => res.data)
+    .catch((e) => e.response);
+}
+
+// ===== Single Sign-On (OIDC) =====
+export async function getOidcConfig() {
+  return await apiClient
+    .get("/auth/oidc/config")
+    .then((res) => res.data)
+    .catch((e) => e.response);
+}
+
+// เปิดจากเบราว์เซอร์โดยตรง (redirect ไปหน้า login ของมหาวิทยาลัย)
+export function getOidcLoginUrl() {
+  return `${import.meta.env.VITE_API_URL}/auth/oidc/login`;
+}
 // ------------------------------------
 // export async function functionName(params) 
 // { return await apiClient 
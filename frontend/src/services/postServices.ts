@@ -1,4 +1,8 @@
-import type { CreatePostRequest, UpdatePostRequest } from "../interfaces/post";
+import type {
+  CreatePostRequest,
+  PostListQuery,
+  UpdatePostRequest,
+} from "../interfaces/post";
 import apiClient from "./apiClient";
 
 export async function CreatePost(data: CreatePostRequest) {
@@ -33,9 +37,35 @@ export async function DeletePost(id: number) {
     .catch((e) => e.response);
 }
 
-export async function GetAllPosts() {
+export async function GetAllPosts(params: PostListQuery = { limit: 200 }) {
   return await apiClient
-    .get(`/post`)
+    .get(`/post`, { params })
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+// ขนาดหน้าสูงสุดที่ GET /post ยอมให้ (MaxPostPageSize ของ backend)
+const MAX_POST_PAGE_SIZE = 200;
+
+// ดึงโพสต์ทุกหน้าตามเงื่อนไขค้นหา รวมเป็น response เดียว (ใช้ในหน้าผู้ดูแลที่ต้องเห็นกิจกรรมทั้งหมด)
+export async function GetAllPostPages(params: Omit<PostListQuery, "page" | "limit"> = {}) {
+  const items: any[] = [];
+  for (let page = 1; ; page++) {
+    const res = await GetAllPosts({ ...params, page, limit: MAX_POST_PAGE_SIZE });
+    if (res?.status !== 200) return res;
+
+    const data = res.data?.data ?? [];
+    items.push(...data);
+    if (data.length < MAX_POST_PAGE_SIZE || items.length >= (res.data?.total ?? 0)) {
+      return { ...res, data: { ...res.data, data: items, page: 1, limit: items.length } };
+    }
+  }
+}
+
+// โพสต์พร้อมทีมที่สมัครและผลการแข่งขัน (ใช้ในหน้าสรุปผล)
+export async function GetDetailedPosts() {
+  return await apiClient
+    .get(`/post/detailed`)
     .then((res) => res)
     .catch((e) => e.response);
 }
@@ -57,6 +87,41 @@ export async function GetPostById(id: number | string) {
     });
 }
 
+export async function SubmitPost(id: number) {
+  return await apiClient
+    .post(`/post/${id}/submit`)
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+export async function ApprovePost(id: number, comment?: string) {
+  return await apiClient
+    .post(`/post/${id}/approve`, { comment: comment || "" })
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+export async function RejectPost(id: number, comment: string) {
+  return await apiClient
+    .post(`/post/${id}/reject`, { comment })
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+export async function GetReviewQueue() {
+  return await apiClient
+    .get(`/post/review-queue`)
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+export async function GetPostReviews(id: number) {
+  return await apiClient
+    .get(`/post/${id}/reviews`)
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
 export function convertFileToBase64(file: File): Promise<string> {
   return new Promise((resolve, reject) => {
     const reader = new FileReader();
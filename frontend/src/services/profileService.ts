@@ -44,3 +44,18 @@ export async function uploadAvatar(
     .then((res) => res.data)
     .catch((e) => e.response);
 }
+
+// ดาวน์โหลดสำเนาข้อมูลส่วนบุคคลทั้งหมด (ZIP)
+export async function exportMyData(): Promise<Blob | null> {
+  return await apiClient
+    .get("/profiles/me/export", { responseType: "blob" })
+    .then((res) => res.data as Blob)
+    .catch(() => null);
+}
+
+export async function deleteMyAccount(password: string) {
+  return await apiClient
+    .delete("/profiles/me", { data: { password } })
+    .then((res) => res.data)
+    .catch((e) => e.response?.data);
+}
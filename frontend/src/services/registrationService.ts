@@ -1,5 +1,9 @@
 import apiClient from "./apiClient";
-import type { AddUserPayload } from "@/interfaces/registration";
+import type {
+  AddUserPayload,
+  RegistrationAnswerInput,
+  RegistrationFormField,
+} from "@/interfaces/registration";
 export async function GetRegistrations() {
   return await apiClient
     .get(`/registration`)
@@ -37,11 +41,12 @@ export async function CreateRegistration(data: any) {
 
 export async function UpdateRegistration(
   id: number,
-  data: { team_name: string; description?: string }
+  data: { team_name: string; description?: string; answers?: RegistrationAnswerInput[] }
 ) {
   return apiClient.patch(`/registration/${id}`, {
     team_name: data.team_name,
     description: data.description ?? "",
+    answers: data.answers,
   });
 }
 
@@ -52,6 +57,30 @@ export async function UpdateRegistrationStatus(id: number, data: { status: strin
     .catch((e) => e.response);
 }
 
+// ยืนยันที่นั่งที่ได้จากคิวสำรอง
+export async function ConfirmRegistration(id: number) {
+  return await apiClient
+    .post(`/registration/${id}/confirm`)
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+// ถอนการสมัคร ทีมถูกเก็บไว้ในสถานะ withdrawn พร้อมเหตุผล
+export async function WithdrawRegistration(id: number, reason: string) {
+  return await apiClient
+    .post(`/registration/${id}/withdraw`, { reason })
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+// รายงานทีมที่ถอนการสมัครของกิจกรรม (ผู้จัดกิจกรรม)
+export async function GetWithdrawalReport(postId: number) {
+  return await apiClient
+    .get(`/registration/post/${postId}/withdrawals`)
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
 export async function DeleteRegistration(id: number) {
   return await apiClient
     .delete(`/registration/${id}`)
@@ -76,9 +105,98 @@ export async function RemoveUserFromRegistration(registrationId: number, data: {
     .catch((e) => e.response);
 }
 
+// เชิญผู้ใช้เข้าทีมด้วย sut_id ผู้ได้รับเชิญต้องตอบรับก่อนจึงเป็นสมาชิก
+export async function InviteToRegistration(registrationId: number, data: AddUserPayload) {
+  return await apiClient
+    .post(`/registration/${registrationId}/invitations`, data)
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+export async function GetTeamInvitations(registrationId: number) {
+  return await apiClient
+    .get(`/registration/${registrationId}/invitations`)
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+export async function CancelInvitation(registrationId: number, invitationId: number) {
+  return await apiClient
+    .delete(`/registration/${registrationId}/invitations/${invitationId}`)
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+// คำเชิญเข้าทีมที่รอผู้ใช้ตอบ
+export async function GetMyInvitations() {
+  return await apiClient
+    .get(`/registration/invitations`)
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+export async function RespondInvitation(invitationId: number, accept: boolean) {
+  return await apiClient
+    .post(`/registration/invitations/${invitationId}/${accept ? "accept" : "decline"}`)
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+// โอนตำแหน่งหัวหน้าทีมให้สมาชิกคนอื่น
+export async function TransferLeadership(registrationId: number, userId: number) {
+  return await apiClient
+    .post(`/registration/${registrationId}/leader`, { user_id: userId })
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
 export async function GetMyRegistrations() {
   return await apiClient
     .get("/registration/my")
     .then((res) => res)
     .catch((e) => e.response);
-}
\ No newline at end of file
+}
+
+// แบบฟอร์มสมัครของกิจกรรม
+export async function GetRegistrationForm(postId: number) {
+  return await apiClient
+    .get(`/post/${postId}/form`)
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+// แทนที่แบบฟอร์มทั้งชุด (คำถามที่มี id เดิมถูกแก้ไข ที่ไม่ส่งมาถูกลบ)
+export async function SaveRegistrationForm(postId: number, fields: RegistrationFormField[]) {
+  return await apiClient
+    .put(`/post/${postId}/form`, {
+      fields: fields.map((f) => ({
+        id: f.ID ?? f.id ?? 0,
+        label: f.label,
+        type: f.type,
+        required: f.required,
+        options: f.options ?? [],
+      })),
+    })
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+// อัปโหลดไฟล์ของคำถามชนิด file แล้วใช้ url ที่ได้เป็นคำตอบ
+export async function UploadRegistrationFile(file: File) {
+  const formData = new FormData();
+  formData.append("file", file);
+  return await apiClient
+    .post(`/registration/upload`, formData, {
+      headers: { "Content-Type": "multipart/form-data" },
+    })
+    .then((res) => res)
+    .catch((e) => e.response);
+}
+
+// ดาวน์โหลดรายชื่อทีมพร้อมคำตอบแบบฟอร์มเป็น CSV
+export async function ExportRegistrations(postId: number): Promise<Blob | null> {
+  return await apiClient
+    .get(`/registration/post/${postId}/export`, { responseType: "blob" })
+    .then((res) => res.data as Blob)
+    .catch(() => null);
+}
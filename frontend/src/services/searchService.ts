@@ -0,0 +1,11 @@
+import apiClient from "./apiClient";
+import type { SearchResponse, SearchType } from "@/interfaces/search";
+
+export async function search(q: string, types?: SearchType[], limit?: number) {
+  return await apiClient
+    .get<SearchResponse>("/search", {
+      params: { q, type: types?.join(","), limit },
+    })
+    .then((res) => res)
+    .catch((e) => e.response);
+}
@@ -2,6 +2,7 @@ import { jwtDecode } from 'jwt-decode';
 
 const TOKEN_KEY = 'token';
 const TOKEN_TYPE_KEY = 'token_type';
+const REFRESH_TOKEN_KEY = 'refresh_token';
 
 interface JWTPayload {
   user_id: number;
@@ -11,9 +12,15 @@ interface JWTPayload {
   iat: number;
 }
 
-export const setAuthToken = (token: string, tokenType: string = "Bearer") => {
+export const setAuthToken = (token: string, tokenType: string = "Bearer", refreshToken?: string) => {
   localStorage.setItem(TOKEN_KEY, token);
   localStorage.setItem(TOKEN_TYPE_KEY, tokenType);
+  if (refreshToken) {
+    localStorage.setItem(REFRESH_TOKEN_KEY, refreshToken);
+  }
+};
+export const getRefreshToken = (): string | null => {
+  return localStorage.getItem(REFRESH_TOKEN_KEY);
 };
 export const getAuthToken = (): string | null => {
   return localStorage.getItem(TOKEN_KEY);
@@ -43,4 +50,5 @@ export const isAuthenticated = (): boolean => {
 export const logout = () => {
   localStorage.removeItem(TOKEN_KEY);
   localStorage.removeItem(TOKEN_TYPE_KEY);
+  localStorage.removeItem(REFRESH_TOKEN_KEY);
 };